@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"top1000/internal/config"
+	"top1000/internal/reparse"
+	"top1000/internal/storage"
+)
+
+const (
+	// migrateTimeout 迁移命令的整体超时时间
+	migrateTimeout = time.Minute
+	// reparseTimeout 重新解析命令的整体超时时间
+	reparseTimeout = 5 * time.Minute
+)
+
+// usage 命令行用法
+const usage = `用法:
+  top1000                          启动服务
+  top1000 migrate-keys [-dry-run]  将旧版本固定 key（top1000:*）迁移到 REDIS_KEY_PREFIX 命名空间
+  top1000 reparse [-dry-run]       用当前解析器重新解析保存的上游原始内容，改写对应的历史快照`
+
+// runCommand 执行子命令，返回进程退出码
+func runCommand(name string, args []string) int {
+	switch name {
+	case "migrate-keys":
+		return migrateKeys(args)
+	case "reparse":
+		return reparseRaw(args)
+	case "-h", "--help", "help":
+		fmt.Println(usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n%s\n", name, usage)
+		return 2
+	}
+}
+
+// migrateKeys 一次性迁移 Redis key 到当前命名空间
+func migrateKeys(args []string) int {
+	flags := flag.NewFlagSet("migrate-keys", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "只检查需要迁移的 key，不做修改")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := config.Get()
+	if cfg.StorageBackend != config.StorageBackendRedis {
+		log.Printf("[migrate-keys] 仅支持 Redis 存储后端，当前为: %s", cfg.StorageBackend)
+		return 1
+	}
+	if err := config.Validate(); err != nil {
+		log.Printf("[migrate-keys] %v", err)
+		return 1
+	}
+	if err := storage.InitRedis(); err != nil {
+		log.Printf("[migrate-keys] %v", err)
+		return 1
+	}
+	defer storage.CloseRedis()
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	results, err := storage.MigrateLegacyKeys(ctx, *dryRun)
+	if results == nil && err == nil {
+		log.Printf("[migrate-keys] REDIS_KEY_PREFIX 与旧版本相同（%s），无需迁移", config.DefaultRedisKeyPrefix)
+		return 0
+	}
+
+	conflicts := 0
+	for _, result := range results {
+		log.Printf("[migrate-keys] %-8s %s -> %s", result.Status, result.From, result.To)
+		if result.Status == storage.MigrationConflict {
+			conflicts++
+		}
+	}
+	if err != nil {
+		log.Printf("[migrate-keys] 迁移中断: %v", err)
+		return 1
+	}
+	if conflicts > 0 {
+		log.Printf("[migrate-keys] %d 个 key 在新命名空间已存在，未覆盖（确认后可手动删除旧 key）", conflicts)
+	}
+	return 0
+}
+
+// reparseRaw 重新解析保存的原始内容并改写历史快照
+// 文件后端的存储文件由服务进程独占写入，服务运行时应改用 POST /api/admin/reparse
+func reparseRaw(args []string) int {
+	flags := flag.NewFlagSet("reparse", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "只检查需要改写的快照，不做修改")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg := config.Get()
+	if cfg.StorageBackend == config.StorageBackendMemory {
+		log.Printf("[reparse] 内存存储没有可重新解析的数据，请在服务运行时调用 POST /api/admin/reparse")
+		return 1
+	}
+	if err := config.Validate(); err != nil {
+		log.Printf("[reparse] %v", err)
+		return 1
+	}
+	if err := storage.Init(); err != nil {
+		log.Printf("[reparse] %v", err)
+		return 1
+	}
+	defer storage.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), reparseTimeout)
+	defer cancel()
+
+	// 与数据刷新共用租约（Redis 后端为集群级），避免改写快照时被新数据覆盖
+	lease, err := storage.GetDefaultLock().AcquireLease(ctx, storage.LeaseTop1000)
+	if err != nil {
+		log.Printf("[reparse] 获取刷新租约失败（可能正在更新数据，请稍后重试）: %v", err)
+		return 1
+	}
+	defer lease.Release(context.Background())
+
+	// 租约丢失后停止改写快照
+	ctx, cancelLease := storage.WithLease(ctx, lease)
+	defer cancelLease()
+
+	results, err := reparse.Run(ctx, storage.GetDefaultRawStore(), storage.GetDefaultHistoryStore(), *dryRun)
+	counts := make(map[string]int)
+	for _, result := range results {
+		line := fmt.Sprintf("[reparse] %-9s %s %s（%d 条）", result.Status, result.ID, result.Time, result.Items)
+		if result.Error != "" {
+			line += ": " + result.Error
+		}
+		log.Print(line)
+		counts[result.Status]++
+	}
+	if err != nil {
+		log.Printf("[reparse] 重新解析中断: %v", err)
+		return 1
+	}
+
+	log.Printf("[reparse] 共 %d 份原始内容：改写 %d，待改写 %d，未变化 %d，无对应快照 %d，失败 %d",
+		len(results), counts[reparse.StatusRewritten], counts[reparse.StatusPending],
+		counts[reparse.StatusUnchanged], counts[reparse.StatusMissing], counts[reparse.StatusFailed])
+	return 0
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"log"
+	"os"
+	_ "time/tzdata" // 内嵌时区数据（scratch 镜像没有 /usr/share/zoneinfo）
 
 	"github.com/joho/godotenv"
 	"top1000/internal/server"
@@ -23,12 +25,27 @@ import (
 // @BasePath /
 // @schemes http https
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description 管理接口令牌，格式为 "Bearer <ADMIN_TOKEN>"
+
+// @securityDefinitions.apikey APITokenAuth
+// @in header
+// @name Authorization
+// @description 用户API令牌（API_TOKENS 中的令牌），格式为 "Bearer <令牌>"
+
 func main() {
 	// 加载 .env 文件（非必需，失败时使用系统环境变量）
 	_ = godotenv.Load()
 
 	log.Println("[main] 环境变量已加载")
 
+	// 子命令（如 migrate-keys）执行完即退出
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
 	// 使用兼容性启动函数（自动处理 context 和优雅关闭）
 	server.StartCompat()
 }
@@ -24,26 +24,30 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/sites.json": {
+        "/api/admin/raw": {
             "get": {
-                "description": "获取IYUU站点列表数据（需要配置IYUU_SIGN环境变量）",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "列出保存的上游原始内容（最新在前，不含内容），需要 ADMIN_TOKEN",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Sites"
+                    "Admin"
                 ],
-                "summary": "获取IYUU站点列表",
+                "summary": "获取上游原始内容列表",
                 "responses": {
                     "200": {
-                        "description": "站点列表数据",
-                        "schema": {}
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.RawListResponse"
+                        }
                     },
-                    "500": {
-                        "description": "error\": \"无法加载站点数据",
+                    "401": {
+                        "description": "error\": \"管理令牌无效",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -51,8 +55,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "502": {
-                        "description": "error\": \"未配置IYUU_SIGN环境变量",
+                    "404": {
+                        "description": "error\": \"管理接口未启用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法加载原始内容",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -63,28 +76,173 @@ const docTemplate = `{
                 }
             }
         },
-        "/top1000.json": {
+        "/api/admin/raw/{id}": {
             "get": {
-                "description": "获取Top1000站点列表数据，数据会自动更新（24小时过期）",
-                "consumes": [
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "按原样返回一次爬取的上游响应体，需要 ADMIN_TOKEN",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "获取上游原始内容",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "上游原始内容",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "error\": \"管理令牌无效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\": \"原始内容不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法加载原始内容",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/reparse": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "用当前解析器重新解析所有保存的上游原始内容，改写同一数据时间的历史快照（当前数据为同一时间时一并改写），用于解析器修复后修复历史数据。只改写已有快照，需要 ADMIN_TOKEN",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "重新解析原始内容",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "只检查需要改写的快照，不做修改",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ReparseResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "error\": \"管理令牌无效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\": \"管理接口未启用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "error\": \"正在更新数据，请稍后重试",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"重新解析失败",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "error\": \"服务正在关闭",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/crawl/last": {
+            "get": {
+                "description": "返回最近一次爬取（包括被异常检测拒绝或解析失败的数据）的解析报告：头部、行数、被跳过的数据组（行号和原因）和末尾剩余行，用于发现上游格式变化。上游返回 304（未变化）时没有重新解析，不更新该报告",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Top1000"
                 ],
-                "summary": "获取Top1000站点数据",
+                "summary": "获取最近一次爬取的解析报告",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.ProcessedData"
+                            "$ref": "#/definitions/model.ParseReport"
+                        }
+                    },
+                    "404": {
+                        "description": "error\": \"还没有解析报告",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "error\": \"无法加载数据",
+                        "description": "error\": \"无法加载解析报告",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -94,42 +252,1087 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.ProcessedData": {
-            "type": "object",
-            "properties": {
-                "items": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.SiteItem"
+        },
+        "/api/credentials": {
+            "get": {
+                "security": [
+                    {
+                        "APITokenAuth": []
+                    }
+                ],
+                "description": "列出当前用户保存了凭据的站点和凭据名称（不返回凭据值），需要用户API令牌",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Credentials"
+                ],
+                "summary": "获取已保存的站点凭据",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CredentialsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "error\": \"API令牌无效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\": \"用户凭据未启用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法加载用户凭据",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
-                },
-                "time": {
-                    "type": "string"
                 }
             }
         },
-        "model.SiteItem": {
-            "type": "object",
-            "properties": {
-                "duplication": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "siteName": {
-                    "type": "string"
-                },
-                "siteid": {
-                    "type": "string"
-                },
-                "size": {
-                    "type": "string"
+        "/api/credentials/{site}": {
+            "put": {
+                "security": [
+                    {
+                        "APITokenAuth": []
+                    }
+                ],
+                "description": "保存当前用户在指定站点的下载凭据（替换该站点已有的凭据），加密后保存。名称为下载链接模板中的占位符：passkey、downHash、uid、hash、authkey、torrent_pass。需要用户API令牌",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Credentials"
+                ],
+                "summary": "保存站点凭据",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "站点标识（与站点目录的 site 一致）",
+                        "name": "site",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "凭据名称到值的映射",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SiteCredentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CredentialsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error\": \"凭据无效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error\": \"API令牌无效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\": \"用户凭据未启用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法保存用户凭据",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
-            }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "APITokenAuth": []
+                    }
+                ],
+                "description": "删除当前用户在指定站点保存的凭据，需要用户API令牌",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Credentials"
+                ],
+                "summary": "删除站点凭据",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "站点标识",
+                        "name": "site",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.CredentialsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "error\": \"API令牌无效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\": \"用户凭据未启用",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法保存用户凭据",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/diff": {
+            "get": {
+                "description": "返回新进入榜单、掉出榜单以及重复度/大小/排名变化的条目（按站点名+站点ID识别），默认比较上一次与最新一次快照",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Top1000"
+                ],
+                "summary": "比较两次Top1000快照",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "旧快照时间（默认为 to 的上一个快照）",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "新快照时间（默认为最新快照）",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SnapshotDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "error\": \"快照时间格式错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\": \"历史快照不足",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法加载历史快照",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/history": {
+            "get": {
+                "description": "列出已归档的历史快照（按数据时间倒序），可通过 /top1000.json?at=\u003ctime\u003e 获取快照内容",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Top1000"
+                ],
+                "summary": "获取Top1000历史快照列表",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.HistoryResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法加载历史快照",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/quarantine": {
+            "get": {
+                "description": "列出最近被异常检测拒绝的数据（最新在前，不含条目内容），可通过 /api/quarantine/{id} 获取完整数据",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Top1000"
+                ],
+                "summary": "获取隔离区列表",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuarantineResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法加载隔离区",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/quarantine/{id}": {
+            "get": {
+                "description": "返回被拒绝的完整数据和拒绝原因",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Top1000"
+                ],
+                "summary": "获取隔离区记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.QuarantineEntry"
+                        }
+                    },
+                    "404": {
+                        "description": "error\": \"隔离记录不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法加载隔离区",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/status": {
+            "get": {
+                "description": "返回各上游（IYUU 接口和镜像）的熔断器状态，以及当前是否有刷新正在进行",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Status"
+                ],
+                "summary": "获取服务状态",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StatusResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sites.json": {
+            "get": {
+                "description": "获取IYUU站点列表数据（需要配置IYUU_SIGN环境变量）。站点数据由后台调度器提前刷新，尚无数据时等待正在进行的刷新，没有刷新或刷新失败时返回 503（带 Retry-After）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "获取IYUU站点列表",
+                "responses": {
+                    "200": {
+                        "description": "站点列表数据",
+                        "schema": {
+                            "$ref": "#/definitions/model.SitesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法加载站点数据",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "error\": \"未配置IYUU_SIGN环境变量",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "error\": \"站点数据尚未加载，请稍后重试",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "string",
+                                "description": "建议重试间隔（秒）"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/top1000.json": {
+            "get": {
+                "description": "获取Top1000站点列表数据，数据由后台调度器定时更新（24小时过期）。数据过期时立即返回旧数据并设置 X-Data-Stale 响应头，同时在后台刷新。尚无数据时等待正在进行的刷新，没有刷新或刷新失败时返回 503（带 Retry-After）。time 为上游原始时间文本，createdAt（RFC 3339）和 createdAtUnix（Unix 秒）为按 UPSTREAM_TIMEZONE 解析后的时间，lastCheckedAt 为最近一次确认上游未变化的时间。enrich=1 时按缓存的站点目录为每条数据补充 nickname、detailsUrl 和 downloadUrl（站点目录不可用时不补充，X-Sites-Enriched 为 false）；同时带有用户API令牌（Authorization: Bearer \u003c令牌\u003e）时，downloadUrl 按该用户保存的站点凭据填充 passkey 等参数，响应不允许缓存",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Top1000"
+                ],
+                "summary": "获取Top1000站点数据",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "历史快照时间（2006-01-02 15:04:05 或 2006-01-02），为空时返回最新数据",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "补充站点名称和种子链接",
+                        "name": "enrich",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ProcessedData"
+                        },
+                        "headers": {
+                            "X-Data-Stale": {
+                                "type": "string",
+                                "description": "数据已过期时为 true"
+                            },
+                            "X-Sites-Enriched": {
+                                "type": "string",
+                                "description": "请求 enrich 时，是否已补充站点链接"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error\": \"快照时间格式错误",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error\": \"API令牌无效",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\": \"历史快照不存在",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\": \"无法加载数据",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "error\": \"数据尚未加载，请稍后重试",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.CredentialsResponse": {
+            "type": "object",
+            "properties": {
+                "sites": {
+                    "description": "站点标识 -\u003e 已保存的凭据名称",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "user": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "snapshots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotMeta"
+                    }
+                }
+            }
+        },
+        "api.QuarantineResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.QuarantineEntry"
+                    }
+                }
+            }
+        },
+        "api.RawListResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RawMeta"
+                    }
+                }
+            }
+        },
+        "api.ReparseResponse": {
+            "type": "object",
+            "properties": {
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reparse.Result"
+                    }
+                }
+            }
+        },
+        "api.StatusResponse": {
+            "type": "object",
+            "properties": {
+                "refreshing": {
+                    "description": "各类数据是否正在刷新",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "upstreams": {
+                    "description": "已请求过的上游（按名称排序）",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/upstream.BreakerStatus"
+                    }
+                }
+            }
+        },
+        "model.ItemChange": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "变化的字段（duplication/size/rank）",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "description": "旧快照中的条目",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SiteItem"
+                        }
+                    ]
+                },
+                "rankDiff": {
+                    "description": "排名变化（正数表示上升）",
+                    "type": "integer"
+                },
+                "siteName": {
+                    "type": "string"
+                },
+                "siteid": {
+                    "type": "string"
+                },
+                "to": {
+                    "description": "新快照中的条目",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SiteItem"
+                        }
+                    ]
+                }
+            }
+        },
+        "model.ParseReport": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "description": "数据格式（text/json）",
+                    "type": "string"
+                },
+                "groups": {
+                    "description": "数据组数（以\"站名\"行开始）",
+                    "type": "integer"
+                },
+                "header": {
+                    "description": "头部原文（数据行之前的行）",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "irregularLines": {
+                    "description": "格式不规范但已容忍的行数（半角冒号、多余空白、空行）",
+                    "type": "integer"
+                },
+                "items": {
+                    "description": "成功解析的条目数",
+                    "type": "integer"
+                },
+                "leftover": {
+                    "description": "无法识别的行（最多 MaxReportDetails 行）",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ReportLine"
+                    }
+                },
+                "leftoverLines": {
+                    "description": "无法识别的行数（不属于任何数据组或组内多余的行）",
+                    "type": "integer"
+                },
+                "linesConsumed": {
+                    "description": "解析为数据的行数（头部和成功解析的数据组）",
+                    "type": "integer"
+                },
+                "parsedAt": {
+                    "description": "解析时间",
+                    "type": "string"
+                },
+                "skipped": {
+                    "description": "被跳过的组（最多 MaxReportDetails 个）",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SkippedGroup"
+                    }
+                },
+                "skippedGroups": {
+                    "description": "格式错误被跳过的组数",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "数据源",
+                    "type": "string"
+                },
+                "time": {
+                    "description": "从头部提取的数据时间",
+                    "type": "string"
+                },
+                "totalLines": {
+                    "description": "总行数",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ProcessedData": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "数据生成时间（RFC 3339，带时区）",
+                    "type": "string"
+                },
+                "createdAtUnix": {
+                    "description": "数据生成时间（Unix 秒）",
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SiteItem"
+                    }
+                },
+                "lastCheckedAt": {
+                    "description": "最近一次确认上游数据未变化的时间（由存储层维护）",
+                    "type": "string"
+                },
+                "time": {
+                    "description": "上游原始时间文本（兼容旧客户端）",
+                    "type": "string"
+                }
+            }
+        },
+        "model.QuarantineEntry": {
+            "type": "object",
+            "properties": {
+                "contentHash": {
+                    "description": "被拒绝数据的内容哈希（用于去重）",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "被拒绝的完整数据（列表接口不返回）",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ProcessedData"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "记录标识（拒绝时间的 Unix 毫秒）",
+                    "type": "string"
+                },
+                "itemCount": {
+                    "description": "被拒绝数据的条目数",
+                    "type": "integer"
+                },
+                "reasons": {
+                    "description": "拒绝原因",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rejectedAt": {
+                    "description": "拒绝时间",
+                    "type": "string"
+                },
+                "report": {
+                    "description": "被拒绝数据的解析报告（列表接口不返回）",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ParseReport"
+                        }
+                    ]
+                },
+                "time": {
+                    "description": "被拒绝数据的时间",
+                    "type": "string"
+                }
+            }
+        },
+        "model.RawMeta": {
+            "type": "object",
+            "properties": {
+                "fetchedAt": {
+                    "description": "获取时间",
+                    "type": "string"
+                },
+                "hash": {
+                    "description": "原始内容的 SHA-256（用于去重）",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "记录标识（获取时间的 Unix 毫秒）",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "原始大小（字节）",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "数据源",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ReportLine": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "description": "行号（从 1 开始）",
+                    "type": "integer"
+                },
+                "text": {
+                    "description": "原文",
+                    "type": "string"
+                }
+            }
+        },
+        "model.Site": {
+            "type": "object",
+            "properties": {
+                "base_url": {
+                    "description": "站点域名（不含协议）",
+                    "type": "string"
+                },
+                "cookie_required": {
+                    "description": "下载是否需要 cookie",
+                    "type": "integer"
+                },
+                "details_page": {
+                    "description": "详情链接模板（{} 为种子ID）",
+                    "type": "string"
+                },
+                "download_page": {
+                    "description": "下载链接模板（{} 为种子ID）",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_https": {
+                    "description": "大于等于1时使用 https",
+                    "type": "integer"
+                },
+                "nickname": {
+                    "description": "站点显示名称",
+                    "type": "string"
+                },
+                "site": {
+                    "description": "站点标识（与 Top1000 的 siteName 对应）",
+                    "type": "string"
+                }
+            }
+        },
+        "model.SiteCatalog": {
+            "type": "object",
+            "properties": {
+                "sites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Site"
+                    }
+                }
+            }
+        },
+        "model.SiteCredentials": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "model.SiteItem": {
+            "type": "object",
+            "properties": {
+                "detailsUrl": {
+                    "description": "种子详情链接",
+                    "type": "string"
+                },
+                "downloadUrl": {
+                    "description": "种子下载链接（不含 passkey，需登录后下载）",
+                    "type": "string"
+                },
+                "duplication": {
+                    "type": "string"
+                },
+                "duplicationValue": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "nickname": {
+                    "description": "以下字段只在请求 ?enrich=1 时按站点目录补充，不保存",
+                    "type": "string"
+                },
+                "siteName": {
+                    "type": "string"
+                },
+                "siteid": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "string"
+                },
+                "sizeBytes": {
+                    "description": "字节数（KB/MB/GB/TB 按 1000 进位，KiB/MiB 等按 1024 进位）",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.SitesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.SiteCatalog"
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "ret": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.SkippedGroup": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "description": "起始行号（从 1 开始）",
+                    "type": "integer"
+                },
+                "lines": {
+                    "description": "原文",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "description": "跳过原因",
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotDiff": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "description": "新进入榜单的条目",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SiteItem"
+                    }
+                },
+                "changed": {
+                    "description": "重复度、大小或排名发生变化的条目",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ItemChange"
+                    }
+                },
+                "from": {
+                    "description": "旧快照时间",
+                    "type": "string"
+                },
+                "removed": {
+                    "description": "掉出榜单的条目",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SiteItem"
+                    }
+                },
+                "to": {
+                    "description": "新快照时间",
+                    "type": "string"
+                }
+            }
+        },
+        "model.SnapshotMeta": {
+            "type": "object",
+            "properties": {
+                "itemCount": {
+                    "description": "条目数量",
+                    "type": "integer"
+                },
+                "savedAt": {
+                    "description": "归档时间",
+                    "type": "string"
+                },
+                "time": {
+                    "description": "上游数据时间（快照标识）",
+                    "type": "string"
+                }
+            }
+        },
+        "reparse.Result": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "fetchedAt": {
+                    "description": "获取时间",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "原始内容记录 ID",
+                    "type": "string"
+                },
+                "items": {
+                    "description": "解析出的条目数",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "数据源",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "处理结果",
+                    "type": "string"
+                },
+                "time": {
+                    "description": "解析出的数据时间",
+                    "type": "string"
+                }
+            }
+        },
+        "upstream.BreakerStatus": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "description": "连续失败次数",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "openedAt": {
+                    "description": "最近一次熔断时间",
+                    "type": "string"
+                },
+                "retryAt": {
+                    "description": "允许探测的时间（熔断中）",
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "APITokenAuth": {
+            "description": "用户API令牌（API_TOKENS 中的令牌），格式为 \"Bearer \u003c令牌\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "管理接口令牌，格式为 \"Bearer \u003cADMIN_TOKEN\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -2,58 +2,90 @@ package api
 
 import (
 	"context"
-	"crypto/tls"
-	"encoding/json"
+	"crypto/subtle"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"net/url"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"top1000/internal/config"
 	"top1000/internal/crawler"
+	"top1000/internal/credentials"
+	"top1000/internal/guard"
 	"top1000/internal/model"
+	"top1000/internal/reparse"
+	"top1000/internal/siterules"
 	"top1000/internal/storage"
+	"top1000/internal/upstream"
 )
 
 const (
 	dataUpdateLogPrefix  = "Top1000"
 	sitesUpdateLogPrefix = "Sites"
 	defaultAPITimeout    = 15 * time.Second
+	// retryAfterSeconds 数据尚未加载时建议客户端重试的间隔
+	retryAfterSeconds = "30"
+	// sitesRefreshBefore 站点数据剩余有效期低于该值时提前刷新，避免过期后出现空窗
+	sitesRefreshBefore = config.DefaultSitesExpire / 2
+	// refreshTimeout 单次刷新的最长时间（独立于触发刷新的请求）
+	refreshTimeout = 2 * time.Minute
+	// staleRefreshCooldown 请求触发后台刷新的最小间隔，避免上游故障时反复爬取
+	staleRefreshCooldown = time.Minute
+	// headerDataStale 返回已过期数据时设置的响应头
+	headerDataStale = "X-Data-Stale"
+	// headerSitesEnriched 请求 ?enrich=1 时表示是否已按站点目录补充链接
+	headerSitesEnriched = "X-Sites-Enriched"
+	// cachePrivate 包含用户凭据的响应（只能由令牌持有者的浏览器使用，不能被共享缓存）
+	cachePrivate = "private, no-store"
+	// localsUser 通过用户令牌认证的用户名（c.Locals）
+	localsUser = "user"
 )
 
-// Go 1.26: 创建HTTP客户端的辅助函数，避免重复代码
-// 这个SB函数根据context和配置创建合适的HTTP客户端
-func createHTTPClient(ctx context.Context, timeout time.Duration) *http.Client {
-	cfg := config.Get()
-
-	// 根据context的deadline调整超时
-	actualTimeout := timeout
-	if deadline, ok := ctx.Deadline(); ok {
-		if remaining := time.Until(deadline); remaining > 0 && remaining < timeout {
-			actualTimeout = remaining
-		}
-	}
+// leaseWaitInterval 其他实例持有租约时检查数据是否已保存的间隔（测试中缩短）
+var leaseWaitInterval = time.Second
 
-	client := &http.Client{Timeout: actualTimeout}
-
-	if cfg.InsecureSkipVerify {
-		client.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
-
-	return client
-}
+// errClosed Handler 已关闭，不再执行刷新
+var errClosed = errors.New("服务正在关闭，不再刷新数据")
 
 // Handler API 处理器（依赖注入模式）
 type Handler struct {
 	store      storage.DataStore
 	sitesStore storage.SitesStore
+	history    storage.HistoryStore
+	quarantine storage.QuarantineStore
+	raw        storage.RawStore
 	lock       storage.UpdateLock
 	crawler    Crawler
+
+	// rules 保存前的异常检测规则
+	rules guard.Rules
+
+	// siteRules 补充站点链接时使用的规则
+	siteRules *siterules.RuleSet
+
+	// adminToken 管理接口令牌（为空时不开放 /api/admin/*）
+	adminToken string
+
+	// users 用户API令牌（令牌 -> 用户名）
+	users map[string]string
+
+	// vault 用户站点凭据（未配置 API_TOKENS 时为 nil，不开放 /api/credentials）
+	vault *credentials.Vault
+
+	// lastStaleRefresh 上次由请求触发后台刷新的时间（UnixNano）
+	lastStaleRefresh atomic.Int64
+
+	// lifetime 刷新使用的 context，Close 时取消；refreshes 跟踪正在执行的刷新
+	lifetime     context.Context
+	stop         context.CancelFunc
+	refreshes    sync.WaitGroup
+	refreshMutex sync.Mutex
 }
 
 // Crawler 爬虫接口（小而专注）
@@ -61,62 +93,392 @@ type Handler struct {
 type Crawler interface {
 	// FetchTop1000WithContext 带 context 的数据爬取
 	FetchTop1000WithContext(ctx context.Context) (*model.ProcessedData, error)
+
+	// FetchSitesWithContext 获取IYUU站点目录
+	FetchSitesWithContext(ctx context.Context, sign string) (*model.SiteCatalog, error)
 }
 
 // NewHandler 创建 Handler 实例（依赖注入）
-func NewHandler(store storage.DataStore, sitesStore storage.SitesStore, lock storage.UpdateLock) *Handler {
-	return &Handler{
+func NewHandler(store storage.DataStore, sitesStore storage.SitesStore, history storage.HistoryStore, quarantine storage.QuarantineStore, raw storage.RawStore, credentialStore storage.CredentialStore, lock storage.UpdateLock) *Handler {
+	cfg := config.Get()
+	h := &Handler{
 		store:      store,
 		sitesStore: sitesStore,
+		history:    history,
+		quarantine: quarantine,
+		raw:        raw,
 		lock:       lock,
-		crawler:    &defaultCrawler{},
+		crawler:    &defaultCrawler{source: crawler.DefaultSource()},
+		rules:      guard.DefaultRules(),
+		siteRules:  siterules.Default(),
+		adminToken: cfg.AdminToken,
 	}
+	h.lifetime, h.stop = context.WithCancel(context.Background())
+
+	// 密钥已由 config.Validate 检查，这里失败时只关闭用户凭据功能
+	if users := cfg.APITokenUsers(); len(users) > 0 {
+		vault, err := credentials.NewVault(credentialStore, cfg.SecretKeyBytes())
+		if err != nil {
+			log.Printf("用户凭据未启用: %v", err)
+		} else {
+			h.users, h.vault = users, vault
+		}
+	}
+	return h
 }
 
 // defaultCrawler 默认爬虫实现（实现 Crawler 接口）
-type defaultCrawler struct{}
+// Top1000 数据从 SOURCE_URLS 配置的数据源获取
+type defaultCrawler struct {
+	source crawler.Source
+}
 
 // FetchTop1000WithContext 调用底层爬虫
 func (d *defaultCrawler) FetchTop1000WithContext(ctx context.Context) (*model.ProcessedData, error) {
-	return crawler.FetchTop1000WithContext(ctx)
+	return crawler.FetchFrom(ctx, d.source)
+}
+
+// FetchSitesWithContext 调用底层站点接口
+func (d *defaultCrawler) FetchSitesWithContext(ctx context.Context, sign string) (*model.SiteCatalog, error) {
+	return crawler.FetchSitesWithContext(ctx, sign)
 }
 
 // RegisterRoutes 注册路由
 func (h *Handler) RegisterRoutes(app *fiber.App) {
 	app.Get("/top1000.json", h.GetTop1000Data)
 	app.Get("/sites.json", h.GetSitesData)
+	app.Get("/api/history", h.GetHistory)
+	app.Get("/api/diff", h.GetDiff)
+	app.Get("/api/status", h.GetStatus)
+	app.Get("/api/quarantine", h.GetQuarantine)
+	app.Get("/api/quarantine/:id", h.GetQuarantineEntry)
+	app.Get("/api/crawl/last", h.GetLastCrawl)
+
+	admin := app.Group("/api/admin", h.requireAdmin)
+	admin.Get("/raw", h.GetRawList)
+	admin.Get("/raw/:id", h.GetRaw)
+	admin.Post("/reparse", h.PostReparse)
+
+	user := app.Group("/api/credentials", h.requireUser)
+	user.Get("", h.GetCredentials)
+	user.Put("/:site", h.PutCredentials)
+	user.Delete("/:site", h.DeleteCredentials)
 }
 
 // ===== 以下改为 Handler 的方法 =====
 
 // GetTop1000Data 提供Top1000数据的API接口
 // @Summary 获取Top1000站点数据
-// @Description 获取Top1000站点列表数据，数据会自动更新（24小时过期）
+// @Description 获取Top1000站点列表数据，数据由后台调度器定时更新（24小时过期）。数据过期时立即返回旧数据并设置 X-Data-Stale 响应头，同时在后台刷新。尚无数据时等待正在进行的刷新，没有刷新或刷新失败时返回 503（带 Retry-After）。time 为上游原始时间文本，createdAt（RFC 3339）和 createdAtUnix（Unix 秒）为按 UPSTREAM_TIMEZONE 解析后的时间，lastCheckedAt 为最近一次确认上游未变化的时间。enrich=1 时按缓存的站点目录为每条数据补充 nickname、detailsUrl 和 downloadUrl（站点目录不可用时不补充，X-Sites-Enriched 为 false）；同时带有用户API令牌（Authorization: Bearer <令牌>）时，downloadUrl 按该用户保存的站点凭据填充 passkey 等参数，响应不允许缓存
 // @Tags Top1000
 // @Accept json
 // @Produce json
+// @Param at query string false "历史快照时间（2006-01-02 15:04:05 或 2006-01-02），为空时返回最新数据"
+// @Param enrich query bool false "补充站点名称和种子链接"
 // @Success 200 {object} model.ProcessedData
+// @Header 200 {string} X-Data-Stale "数据已过期时为 true"
+// @Header 200 {string} X-Sites-Enriched "请求 enrich 时，是否已补充站点链接"
+// @Header 503 {string} Retry-After "建议重试间隔（秒）"
+// @Failure 400 {object} map[string]string "error": "快照时间格式错误"
+// @Failure 401 {object} map[string]string "error": "API令牌无效"
+// @Failure 404 {object} map[string]string "error": "历史快照不存在"
 // @Failure 500 {object} map[string]string "error": "无法加载数据"
+// @Failure 503 {object} map[string]string "error": "数据尚未加载，请稍后重试"
 // @Router /top1000.json [get]
 func (h *Handler) GetTop1000Data(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
 	defer cancel()
 
-	if h.shouldUpdateData(ctx) {
-		if err := h.refreshData(ctx); err != nil {
-			log.Printf("[%s] 刷新数据失败: %v", dataUpdateLogPrefix, err)
-		}
+	if at := c.Query("at"); at != "" {
+		return h.getSnapshot(ctx, c, at)
 	}
 
+	// 数据由后台调度器刷新（见 RefreshData），请求路径不直接爬取
 	data, err := h.store.LoadData(ctx)
 	if err != nil {
-		log.Printf("[%s] 加载数据失败: %v", dataUpdateLogPrefix, err)
+		if exists, existsErr := h.store.DataExists(ctx); existsErr != nil || exists {
+			log.Printf("[%s] 加载数据失败: %v", dataUpdateLogPrefix, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "无法加载数据",
+			})
+		}
+
+		// 尚无任何数据：等待调度器正在进行的刷新，与其他请求共享结果
+		if data, err = h.waitForData(ctx); err != nil {
+			return notLoaded(c, "数据尚未加载，请稍后重试")
+		}
+	}
+
+	// 过期时先返回旧数据，再在后台合并刷新
+	if storage.IsExpired(data) {
+		c.Set(headerDataStale, "true")
+		h.refreshInBackground()
+	}
+
+	return h.sendData(ctx, c, data)
+}
+
+// sendData 返回 Top1000 数据（?enrich=1 时补充站点名称和链接，带用户令牌时按用户凭据填充下载链接）
+func (h *Handler) sendData(ctx context.Context, c *fiber.Ctx, data *model.ProcessedData) error {
+	data = normalized(data)
+	if !c.QueryBool("enrich") {
+		return c.JSON(data)
+	}
+
+	user, present := h.tokenUser(c)
+	if present && user == "" {
+		return invalidToken(c)
+	}
+	var creds model.UserCredentials
+	if user != "" {
+		// 个性化的链接只能返回给令牌持有者
+		c.Set(fiber.HeaderCacheControl, cachePrivate)
+		c.Vary(fiber.HeaderAuthorization)
+
+		var err error
+		if creds, err = h.vault.Load(ctx, user); err != nil {
+			return credentialsLoadFailed(c, user, err)
+		}
+	}
+
+	// 只使用缓存的站点目录，不在请求路径上请求 IYUU
+	catalog, err := h.sitesStore.LoadSitesData(ctx)
+	if err != nil {
+		c.Set(headerSitesEnriched, "false")
+		return c.JSON(data)
+	}
+	c.Set(headerSitesEnriched, "true")
+	return c.JSON(h.siteRules.Enrich(data, catalog, creds))
+}
+
+// normalized 补齐旧版本保存的数据
+// createdAt/createdAtUnix 按上游时区解析；sizeBytes/duplicationValue 按原始文本重新计算，
+// 与当前的单位约定（见 model.ParseSize）保持一致
+func normalized(data *model.ProcessedData) *model.ProcessedData {
+	if data.CreatedAt.IsZero() {
+		// 解析失败时保持为空，只返回原始 time 字段
+		_ = data.ParseTime(config.Get().UpstreamLocation())
+	}
+	for i := range data.Items {
+		data.Items[i].ComputeValues()
+	}
+	return data
+}
+
+// waitForData 等待正在进行的刷新完成后重新加载数据
+// 不发起爬取：没有正在进行的刷新时直接重新读取（可能刚刷新完成），仍然没有数据时由调用者返回 503
+func (h *Handler) waitForData(ctx context.Context) (*model.ProcessedData, error) {
+	if _, err := h.lock.Wait(ctx, storage.LeaseTop1000); err != nil {
+		return nil, err
+	}
+	return h.store.LoadData(ctx)
+}
+
+// refreshInBackground 在后台发起一次刷新（已有刷新进行中或刚触发过时跳过）
+func (h *Handler) refreshInBackground() {
+	if h.lock.InFlight(storage.LeaseTop1000) {
+		return
+	}
+
+	now := time.Now().UnixNano()
+	last := h.lastStaleRefresh.Load()
+	if now-last < int64(staleRefreshCooldown) || !h.lastStaleRefresh.CompareAndSwap(last, now) {
+		return
+	}
+
+	go func() {
+		// 失败原因已在 fetchData 中记录；关闭时不再等待
+		_ = h.refreshData(h.lifetime)
+	}()
+}
+
+// runRefresh 在 Handler 的生命周期内执行一次刷新或重新解析（独立于触发的请求，最长 refreshTimeout）
+// Close 之后不再执行，Close 会取消并等待正在执行的刷新，避免关闭存储后仍有写入
+func (h *Handler) runRefresh(fn func(ctx context.Context) error) error {
+	h.refreshMutex.Lock()
+	if h.lifetime.Err() != nil {
+		h.refreshMutex.Unlock()
+		return errClosed
+	}
+	h.refreshes.Add(1)
+	h.refreshMutex.Unlock()
+	defer h.refreshes.Done()
+
+	ctx, cancel := context.WithTimeout(h.lifetime, refreshTimeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+// Close 取消正在执行的刷新并等待其结束（ctx 超时后不再等待）
+// 服务关闭时在关闭存储之前调用，之后的刷新直接返回错误
+func (h *Handler) Close(ctx context.Context) error {
+	h.refreshMutex.Lock()
+	h.stop()
+	h.refreshMutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.refreshes.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待刷新任务结束超时: %w", ctx.Err())
+	}
+}
+
+// notLoaded 数据尚未由后台调度器加载（启动后首次刷新完成前）
+func notLoaded(c *fiber.Ctx, message string) error {
+	c.Set(fiber.HeaderRetryAfter, retryAfterSeconds)
+	return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+		"error": message,
+	})
+}
+
+// getSnapshot 返回指定时间的历史快照
+func (h *Handler) getSnapshot(ctx context.Context, c *fiber.Ctx, at string) error {
+	data, err := h.history.LoadSnapshot(ctx, at)
+	if err != nil {
+		return h.snapshotError(c, err)
+	}
+
+	return h.sendData(ctx, c, data)
+}
+
+// GetHistory 列出所有历史快照
+// @Summary 获取Top1000历史快照列表
+// @Description 列出已归档的历史快照（按数据时间倒序），可通过 /top1000.json?at=<time> 获取快照内容
+// @Tags Top1000
+// @Produce json
+// @Success 200 {object} HistoryResponse
+// @Failure 500 {object} map[string]string "error": "无法加载历史快照"
+// @Router /api/history [get]
+func (h *Handler) GetHistory(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
+	defer cancel()
+
+	snapshots, err := h.history.ListSnapshots(ctx)
+	if err != nil {
+		log.Printf("[%s] 列出历史快照失败: %v", dataUpdateLogPrefix, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "无法加载历史快照",
+		})
+	}
+
+	return c.JSON(HistoryResponse{Snapshots: snapshots})
+}
+
+// HistoryResponse 历史快照列表响应
+type HistoryResponse struct {
+	Snapshots []model.SnapshotMeta `json:"snapshots"`
+}
+
+// GetDiff 比较两次历史快照
+// @Summary 比较两次Top1000快照
+// @Description 返回新进入榜单、掉出榜单以及重复度/大小/排名变化的条目（按站点名+站点ID识别），默认比较上一次与最新一次快照
+// @Tags Top1000
+// @Produce json
+// @Param from query string false "旧快照时间（默认为 to 的上一个快照）"
+// @Param to query string false "新快照时间（默认为最新快照）"
+// @Success 200 {object} model.SnapshotDiff
+// @Failure 400 {object} map[string]string "error": "快照时间格式错误"
+// @Failure 404 {object} map[string]string "error": "历史快照不足"
+// @Failure 500 {object} map[string]string "error": "无法加载历史快照"
+// @Router /api/diff [get]
+func (h *Handler) GetDiff(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
+	defer cancel()
+
+	snapshots, err := h.history.ListSnapshots(ctx)
+	if err != nil {
+		log.Printf("[%s] 列出历史快照失败: %v", dataUpdateLogPrefix, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "无法加载数据",
+			"error": "无法加载历史快照",
+		})
+	}
+
+	if len(snapshots) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "历史快照不足",
+		})
+	}
+
+	// to 默认为最新快照
+	toAt := c.Query("to", snapshots[0].Time)
+	to, err := h.history.LoadSnapshot(ctx, toAt)
+	if err != nil {
+		return h.snapshotError(c, err)
+	}
+
+	// from 默认为 to 之前的一个快照
+	fromAt := c.Query("from")
+	if fromAt == "" {
+		var ok bool
+		if fromAt, ok = previousSnapshot(snapshots, to.Time); !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "历史快照不足",
+			})
+		}
+	}
+	from, err := h.history.LoadSnapshot(ctx, fromAt)
+	if err != nil {
+		return h.snapshotError(c, err)
+	}
+
+	return c.JSON(model.DiffSnapshots(from, to))
+}
+
+// previousSnapshot 返回 at 之前的一个快照时间（snapshots 按时间倒序）
+func previousSnapshot(snapshots []model.SnapshotMeta, at string) (string, bool) {
+	i := slices.IndexFunc(snapshots, func(m model.SnapshotMeta) bool { return m.Time == at })
+	if i < 0 || i+1 >= len(snapshots) {
+		return "", false
+	}
+	return snapshots[i+1].Time, true
+}
+
+// snapshotError 统一处理快照加载错误
+func (h *Handler) snapshotError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, storage.ErrSnapshotTimeInvalid) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "快照时间格式错误，应为 2006-01-02 15:04:05 或 2006-01-02",
 		})
 	}
+	if errors.Is(err, storage.ErrSnapshotNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "历史快照不存在",
+		})
+	}
+	log.Printf("[%s] 加载历史快照失败: %v", dataUpdateLogPrefix, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "无法加载历史快照",
+	})
+}
 
-	return c.JSON(data)
+// GetStatus 返回上游熔断器和刷新任务状态
+// @Summary 获取服务状态
+// @Description 返回各上游（IYUU 接口和镜像）的熔断器状态，以及当前是否有刷新正在进行
+// @Tags Status
+// @Produce json
+// @Success 200 {object} StatusResponse
+// @Router /api/status [get]
+func (h *Handler) GetStatus(c *fiber.Ctx) error {
+	return c.JSON(StatusResponse{
+		Upstreams: upstream.Statuses(),
+		Refreshing: map[string]bool{
+			storage.LeaseTop1000: h.lock.InFlight(storage.LeaseTop1000),
+			storage.LeaseSites:   h.lock.InFlight(storage.LeaseSites),
+		},
+	})
+}
+
+// StatusResponse 服务状态响应
+type StatusResponse struct {
+	Upstreams  []upstream.BreakerStatus `json:"upstreams"`  // 已请求过的上游（按名称排序）
+	Refreshing map[string]bool          `json:"refreshing"` // 各类数据是否正在刷新
 }
 
 // shouldUpdateData 检查数据是否需要更新
@@ -130,17 +492,42 @@ func (h *Handler) shouldUpdateData(ctx context.Context) bool {
 	return err != nil || isExpired
 }
 
-// refreshData 刷新数据（带容错机制）
-// 返回 error 让调用者知道刷新是否成功
-func (h *Handler) refreshData(ctx context.Context) error {
-	// 防止并发更新
-	if h.lock.IsUpdating() {
-		log.Printf("[%s] 正在更新中，跳过", dataUpdateLogPrefix)
+// RefreshData 按需刷新 Top1000 数据（数据不存在或已过期时才爬取）
+// 由后台调度器调用，HTTP 请求路径只读取数据
+func (h *Handler) RefreshData(ctx context.Context) error {
+	if !h.shouldUpdateData(ctx) {
 		return nil
 	}
+	return h.refreshData(ctx)
+}
 
-	h.lock.SetUpdating(true)
-	defer h.lock.SetUpdating(false)
+// refreshData 刷新数据（合并并发刷新）
+// 同一时间只有一次爬取，调度器和请求共享它的结果；ctx 只控制等待时间
+func (h *Handler) refreshData(ctx context.Context) error {
+	return h.lock.Do(ctx, storage.LeaseTop1000, func() error {
+		return h.runRefresh(h.fetchData)
+	})
+}
+
+// fetchData 爬取并保存数据（带容错机制）
+// 返回 error 让调用者知道刷新是否成功
+func (h *Handler) fetchData(ctx context.Context) error {
+	// 集群级租约：多实例部署时只有一个实例去爬取
+	lease, err := h.acquireLease(ctx, storage.LeaseTop1000, dataUpdateLogPrefix, h.store.DataExists)
+	if err != nil || lease == nil {
+		return err
+	}
+	defer releaseLease(lease, dataUpdateLogPrefix)
+
+	// 租约丢失后其他实例可能已开始刷新，爬取和保存随之中断
+	ctx, cancel := storage.WithLease(ctx, lease)
+	defer cancel()
+
+	// 获取租约期间其他实例可能刚完成刷新
+	if !h.shouldUpdateData(ctx) {
+		log.Printf("[%s] 数据已被其他实例更新，跳过", dataUpdateLogPrefix)
+		return nil
+	}
 
 	// 保存旧数据用于容错（传递context）
 	oldData, err := h.store.LoadData(ctx)
@@ -151,6 +538,12 @@ func (h *Handler) refreshData(ctx context.Context) error {
 
 	log.Printf("[%s] 开始爬取新数据...", dataUpdateLogPrefix)
 	newData, err := h.crawler.FetchTop1000WithContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		// 附上中断原因（租约丢失、超时或服务关闭）
+		err = fmt.Errorf("%w: %w", err, context.Cause(ctx))
+	}
+	h.saveCrawlReport(ctx, newData, err)
+	h.saveRaw(ctx, newData, err)
 	if err != nil {
 		// 爬取失败，如果有旧数据则使用旧数据（容错）
 		if oldData != nil {
@@ -161,6 +554,27 @@ func (h *Handler) refreshData(ctx context.Context) error {
 		return err
 	}
 
+	// 上游没有生成新数据：只记录检查时间，不重写数据和历史快照
+	if oldData != nil && oldData.ContentHash() == newData.ContentHash() {
+		if err := h.store.TouchData(ctx, time.Now()); err != nil {
+			log.Printf("[%s] 记录检查时间失败: %v", dataUpdateLogPrefix, err)
+			return err
+		}
+		log.Printf("[%s] 数据未变化（数据时间: %s），跳过保存", dataUpdateLogPrefix, newData.Time)
+		return nil
+	}
+
+	// 可疑数据（如被截断的响应）不覆盖当前数据，放入隔离区供检查
+	if err := h.rules.Check(oldData, newData); err != nil {
+		log.Printf("[%s] 拒绝保存: %v", dataUpdateLogPrefix, err)
+		h.quarantineData(ctx, newData, err)
+		return err
+	}
+
+	if err := leaseLost(ctx); err != nil {
+		log.Printf("[%s] 放弃保存: %v", dataUpdateLogPrefix, err)
+		return err
+	}
 	if err := h.store.SaveData(ctx, *newData); err != nil {
 		log.Printf("[%s] 保存数据失败: %v", dataUpdateLogPrefix, err)
 		return err
@@ -170,15 +584,469 @@ func (h *Handler) refreshData(ctx context.Context) error {
 	return nil
 }
 
+// saveCrawlReport 保存本次爬取的解析报告（解析失败时从错误中取出）
+func (h *Handler) saveCrawlReport(ctx context.Context, data *model.ProcessedData, fetchErr error) {
+	var report *model.ParseReport
+	var parseErr *crawler.ParseError
+	switch {
+	case data != nil:
+		report = data.Report
+	case errors.As(fetchErr, &parseErr):
+		report = parseErr.Report
+	}
+	if report == nil {
+		return
+	}
+
+	// 报告只用于排查，保存失败不影响本次刷新
+	if err := h.history.SaveCrawlReport(ctx, *report); err != nil {
+		log.Printf("[%s] 保存解析报告失败: %v", dataUpdateLogPrefix, err)
+	}
+}
+
+// saveRaw 保存本次爬取的上游原始内容（解析失败时从错误中取出，与最近一份相同时不重复保存）
+func (h *Handler) saveRaw(ctx context.Context, data *model.ProcessedData, fetchErr error) {
+	var raw *model.RawPayload
+	var parseErr *crawler.ParseError
+	switch {
+	case data != nil:
+		raw = data.Raw
+	case errors.As(fetchErr, &parseErr):
+		raw = parseErr.Raw
+	}
+	if raw == nil {
+		return
+	}
+
+	if metas, err := h.raw.ListRaw(ctx); err == nil && len(metas) > 0 && metas[0].Hash == raw.Hash {
+		return
+	}
+
+	// 原始内容只用于重新解析，保存失败不影响本次刷新
+	if err := h.raw.SaveRaw(ctx, *raw); err != nil {
+		log.Printf("[%s] 保存原始内容失败: %v", dataUpdateLogPrefix, err)
+	}
+}
+
+// GetLastCrawl 返回最近一次爬取的解析报告
+// @Summary 获取最近一次爬取的解析报告
+// @Description 返回最近一次爬取（包括被异常检测拒绝或解析失败的数据）的解析报告：头部、行数、被跳过的数据组（行号和原因）和末尾剩余行，用于发现上游格式变化。上游返回 304（未变化）时没有重新解析，不更新该报告
+// @Tags Top1000
+// @Produce json
+// @Success 200 {object} model.ParseReport
+// @Failure 404 {object} map[string]string "error": "还没有解析报告"
+// @Failure 500 {object} map[string]string "error": "无法加载解析报告"
+// @Router /api/crawl/last [get]
+func (h *Handler) GetLastCrawl(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
+	defer cancel()
+
+	report, err := h.history.LoadCrawlReport(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrReportNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "还没有解析报告",
+			})
+		}
+		log.Printf("[%s] 加载解析报告失败: %v", dataUpdateLogPrefix, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "无法加载解析报告",
+		})
+	}
+	return c.JSON(report)
+}
+
+// quarantineData 把被拒绝的数据放入隔离区（与最近一条相同时不重复保存）
+func (h *Handler) quarantineData(ctx context.Context, data *model.ProcessedData, reason error) {
+	hash := data.ContentHash()
+	if entries, err := h.quarantine.ListQuarantine(ctx); err == nil && len(entries) > 0 && entries[0].ContentHash == hash {
+		return
+	}
+
+	reasons := []string{reason.Error()}
+	var violations guard.Violations
+	if errors.As(reason, &violations) {
+		reasons = violations
+	}
+
+	entry := guard.NewQuarantineEntry(data, reasons, time.Now())
+	if err := h.quarantine.Quarantine(ctx, entry); err != nil {
+		log.Printf("[%s] 保存到隔离区失败: %v", dataUpdateLogPrefix, err)
+		return
+	}
+	log.Printf("[%s] 已放入隔离区: %s（%d 条，数据时间 %s）", dataUpdateLogPrefix, entry.ID, entry.ItemCount, entry.Time)
+}
+
+// GetQuarantine 列出被异常检测拒绝的数据
+// @Summary 获取隔离区列表
+// @Description 列出最近被异常检测拒绝的数据（最新在前，不含条目内容），可通过 /api/quarantine/{id} 获取完整数据
+// @Tags Top1000
+// @Produce json
+// @Success 200 {object} QuarantineResponse
+// @Failure 500 {object} map[string]string "error": "无法加载隔离区"
+// @Router /api/quarantine [get]
+func (h *Handler) GetQuarantine(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
+	defer cancel()
+
+	entries, err := h.quarantine.ListQuarantine(ctx)
+	if err != nil {
+		log.Printf("[%s] 加载隔离区失败: %v", dataUpdateLogPrefix, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "无法加载隔离区",
+		})
+	}
+
+	for i := range entries {
+		entries[i].Data = nil
+		entries[i].Report = nil
+	}
+	return c.JSON(QuarantineResponse{Entries: entries})
+}
+
+// GetQuarantineEntry 返回一条隔离记录的完整数据
+// @Summary 获取隔离区记录
+// @Description 返回被拒绝的完整数据和拒绝原因
+// @Tags Top1000
+// @Produce json
+// @Param id path string true "记录ID"
+// @Success 200 {object} model.QuarantineEntry
+// @Failure 404 {object} map[string]string "error": "隔离记录不存在"
+// @Failure 500 {object} map[string]string "error": "无法加载隔离区"
+// @Router /api/quarantine/{id} [get]
+func (h *Handler) GetQuarantineEntry(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
+	defer cancel()
+
+	entries, err := h.quarantine.ListQuarantine(ctx)
+	if err != nil {
+		log.Printf("[%s] 加载隔离区失败: %v", dataUpdateLogPrefix, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "无法加载隔离区",
+		})
+	}
+
+	i := slices.IndexFunc(entries, func(e model.QuarantineEntry) bool { return e.ID == c.Params("id") })
+	if i < 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "隔离记录不存在",
+		})
+	}
+	return c.JSON(entries[i])
+}
+
+// QuarantineResponse 隔离区列表响应
+type QuarantineResponse struct {
+	Entries []model.QuarantineEntry `json:"entries"`
+}
+
+// ===== 管理接口 =====
+
+// requireAdmin 校验管理接口令牌（Authorization: Bearer <ADMIN_TOKEN>）
+// 未配置 ADMIN_TOKEN 时管理接口不存在
+func (h *Handler) requireAdmin(c *fiber.Ctx) error {
+	if h.adminToken == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "管理接口未启用",
+		})
+	}
+
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "管理令牌无效",
+		})
+	}
+	return c.Next()
+}
+
+// tokenUser 按 Authorization: Bearer <令牌> 识别用户（逐个比较全部令牌，耗时与匹配位置无关）
+// 未启用用户令牌或请求没有 Bearer 令牌时 present 为 false；令牌无效时 user 为空
+func (h *Handler) tokenUser(c *fiber.Ctx) (user string, present bool) {
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !ok || h.vault == nil {
+		return "", false
+	}
+
+	for candidate, name := range h.users {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+			user = name
+		}
+	}
+	return user, true
+}
+
+// invalidToken 返回令牌无效
+func invalidToken(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"error": "API令牌无效",
+	})
+}
+
+// requireUser 校验用户API令牌（Authorization: Bearer <令牌>），用户名保存在 c.Locals(localsUser)
+// 未配置 API_TOKENS 时用户凭据接口不存在
+func (h *Handler) requireUser(c *fiber.Ctx) error {
+	if h.vault == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "用户凭据未启用",
+		})
+	}
+
+	user, _ := h.tokenUser(c)
+	if user == "" {
+		return invalidToken(c)
+	}
+	c.Locals(localsUser, user)
+	c.Set(fiber.HeaderCacheControl, cachePrivate)
+	return c.Next()
+}
+
+// GetCredentials 列出当前用户保存了凭据的站点
+// @Summary 获取已保存的站点凭据
+// @Description 列出当前用户保存了凭据的站点和凭据名称（不返回凭据值），需要用户API令牌
+// @Tags Credentials
+// @Produce json
+// @Security APITokenAuth
+// @Success 200 {object} CredentialsResponse
+// @Failure 401 {object} map[string]string "error": "API令牌无效"
+// @Failure 404 {object} map[string]string "error": "用户凭据未启用"
+// @Failure 500 {object} map[string]string "error": "无法加载用户凭据"
+// @Router /api/credentials [get]
+func (h *Handler) GetCredentials(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
+	defer cancel()
+
+	user := c.Locals(localsUser).(string)
+	creds, err := h.vault.Load(ctx, user)
+	if err != nil {
+		return credentialsLoadFailed(c, user, err)
+	}
+	return c.JSON(newCredentialsResponse(user, creds))
+}
+
+// PutCredentials 保存当前用户在一个站点的凭据
+// @Summary 保存站点凭据
+// @Description 保存当前用户在指定站点的下载凭据（替换该站点已有的凭据），加密后保存。名称为下载链接模板中的占位符：passkey、downHash、uid、hash、authkey、torrent_pass。需要用户API令牌
+// @Tags Credentials
+// @Accept json
+// @Produce json
+// @Security APITokenAuth
+// @Param site path string true "站点标识（与站点目录的 site 一致）"
+// @Param credentials body model.SiteCredentials true "凭据名称到值的映射"
+// @Success 200 {object} CredentialsResponse
+// @Failure 400 {object} map[string]string "error": "凭据无效"
+// @Failure 401 {object} map[string]string "error": "API令牌无效"
+// @Failure 404 {object} map[string]string "error": "用户凭据未启用"
+// @Failure 500 {object} map[string]string "error": "无法保存用户凭据"
+// @Router /api/credentials/{site} [put]
+func (h *Handler) PutCredentials(c *fiber.Ctx) error {
+	var values model.SiteCredentials
+	if err := c.BodyParser(&values); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "请求体必须是凭据名称到值的 JSON 对象",
+		})
+	}
+	if err := credentials.Validate(values); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return h.updateCredentials(c, func(creds model.UserCredentials) {
+		creds[c.Params("site")] = values
+	})
+}
+
+// DeleteCredentials 删除当前用户在一个站点的凭据
+// @Summary 删除站点凭据
+// @Description 删除当前用户在指定站点保存的凭据，需要用户API令牌
+// @Tags Credentials
+// @Produce json
+// @Security APITokenAuth
+// @Param site path string true "站点标识"
+// @Success 200 {object} CredentialsResponse
+// @Failure 401 {object} map[string]string "error": "API令牌无效"
+// @Failure 404 {object} map[string]string "error": "用户凭据未启用"
+// @Failure 500 {object} map[string]string "error": "无法保存用户凭据"
+// @Router /api/credentials/{site} [delete]
+func (h *Handler) DeleteCredentials(c *fiber.Ctx) error {
+	return h.updateCredentials(c, func(creds model.UserCredentials) {
+		delete(creds, c.Params("site"))
+	})
+}
+
+// updateCredentials 原子地修改当前用户的凭据并重新加密保存（同一用户同时修改不同站点时互不覆盖）
+func (h *Handler) updateCredentials(c *fiber.Ctx, fn func(model.UserCredentials)) error {
+	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
+	defer cancel()
+
+	user := c.Locals(localsUser).(string)
+	creds, err := h.vault.Update(ctx, user, fn)
+	if err != nil {
+		log.Printf("[Credentials] 保存用户 %s 的凭据失败: %v", user, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "无法保存用户凭据",
+		})
+	}
+	return c.JSON(newCredentialsResponse(user, creds))
+}
+
+// credentialsLoadFailed 记录并返回加载用户凭据失败（错误中不包含凭据值）
+func credentialsLoadFailed(c *fiber.Ctx, user string, err error) error {
+	log.Printf("[Credentials] 加载用户 %s 的凭据失败: %v", user, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "无法加载用户凭据",
+	})
+}
+
+// GetRawList 列出保存的上游原始内容
+// @Summary 获取上游原始内容列表
+// @Description 列出保存的上游原始内容（最新在前，不含内容），需要 ADMIN_TOKEN
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} RawListResponse
+// @Failure 401 {object} map[string]string "error": "管理令牌无效"
+// @Failure 404 {object} map[string]string "error": "管理接口未启用"
+// @Failure 500 {object} map[string]string "error": "无法加载原始内容"
+// @Router /api/admin/raw [get]
+func (h *Handler) GetRawList(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
+	defer cancel()
+
+	metas, err := h.raw.ListRaw(ctx)
+	if err != nil {
+		log.Printf("[%s] 加载原始内容失败: %v", dataUpdateLogPrefix, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "无法加载原始内容",
+		})
+	}
+	return c.JSON(RawListResponse{Entries: metas})
+}
+
+// GetRaw 返回一份上游原始内容
+// @Summary 获取上游原始内容
+// @Description 按原样返回一次爬取的上游响应体，需要 ADMIN_TOKEN
+// @Tags Admin
+// @Produce plain
+// @Security BearerAuth
+// @Param id path string true "记录ID"
+// @Success 200 {string} string "上游原始内容"
+// @Failure 401 {object} map[string]string "error": "管理令牌无效"
+// @Failure 404 {object} map[string]string "error": "原始内容不存在"
+// @Failure 500 {object} map[string]string "error": "无法加载原始内容"
+// @Router /api/admin/raw/{id} [get]
+func (h *Handler) GetRaw(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
+	defer cancel()
+
+	raw, err := h.raw.LoadRaw(ctx, c.Params("id"))
+	if err != nil {
+		if errors.Is(err, storage.ErrRawNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "原始内容不存在",
+			})
+		}
+		log.Printf("[%s] 加载原始内容失败: %v", dataUpdateLogPrefix, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "无法加载原始内容",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.Send(raw.Body)
+}
+
+// PostReparse 用当前解析器重新解析保存的原始内容并改写历史快照
+// @Summary 重新解析原始内容
+// @Description 用当前解析器重新解析所有保存的上游原始内容，改写同一数据时间的历史快照（当前数据为同一时间时一并改写），用于解析器修复后修复历史数据。只改写已有快照，需要 ADMIN_TOKEN
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param dryRun query bool false "只检查需要改写的快照，不做修改"
+// @Success 200 {object} ReparseResponse
+// @Failure 401 {object} map[string]string "error": "管理令牌无效"
+// @Failure 404 {object} map[string]string "error": "管理接口未启用"
+// @Failure 409 {object} map[string]string "error": "正在更新数据，请稍后重试"
+// @Failure 500 {object} map[string]string "error": "重新解析失败"
+// @Failure 503 {object} map[string]string "error": "服务正在关闭"
+// @Router /api/admin/reparse [post]
+func (h *Handler) PostReparse(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dryRun")
+
+	// 与后台刷新一样在 Handler 的生命周期内执行，关闭时取消并等待，避免存储关闭后仍在改写快照
+	var results []reparse.Result
+	err := h.runRefresh(func(ctx context.Context) error {
+		// 与数据刷新共用租约，避免改写快照时被新数据覆盖
+		lease, err := h.lock.AcquireLease(ctx, storage.LeaseTop1000)
+		if err != nil {
+			return fmt.Errorf("获取刷新租约失败: %w", err)
+		}
+		defer releaseLease(lease, dataUpdateLogPrefix)
+
+		ctx, cancelLease := storage.WithLease(ctx, lease)
+		defer cancelLease()
+
+		results, err = reparse.Run(ctx, h.raw, h.history, dryRun)
+		return err
+	})
+
+	switch {
+	case err == nil:
+		return c.JSON(ReparseResponse{DryRun: dryRun, Results: results})
+	case errors.Is(err, storage.ErrLeaseHeld):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "正在更新数据，请稍后重试",
+		})
+	case errors.Is(err, errClosed):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "服务正在关闭",
+		})
+	default:
+		log.Printf("[%s] 重新解析失败: %v", dataUpdateLogPrefix, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "重新解析失败",
+		})
+	}
+}
+
+// CredentialsResponse 用户凭据响应（只包含站点和凭据名称，不包含凭据值）
+type CredentialsResponse struct {
+	User  string              `json:"user"`
+	Sites map[string][]string `json:"sites"` // 站点标识 -> 已保存的凭据名称
+}
+
+// newCredentialsResponse 列出已保存的凭据名称
+func newCredentialsResponse(user string, creds model.UserCredentials) CredentialsResponse {
+	sites := make(map[string][]string, len(creds))
+	for site, values := range creds {
+		sites[site] = slices.Sorted(maps.Keys(values))
+	}
+	return CredentialsResponse{User: user, Sites: sites}
+}
+
+// RawListResponse 原始内容列表响应
+type RawListResponse struct {
+	Entries []model.RawMeta `json:"entries"`
+}
+
+// ReparseResponse 重新解析响应
+type ReparseResponse struct {
+	DryRun  bool             `json:"dryRun"`
+	Results []reparse.Result `json:"results"`
+}
+
 // GetSitesData 提供IYUU站点数据的API接口
 // @Summary 获取IYUU站点列表
-// @Description 获取IYUU站点列表数据（需要配置IYUU_SIGN环境变量）
+// @Description 获取IYUU站点列表数据（需要配置IYUU_SIGN环境变量）。站点数据由后台调度器提前刷新，尚无数据时等待正在进行的刷新，没有刷新或刷新失败时返回 503（带 Retry-After）
 // @Tags Sites
 // @Accept json
 // @Produce json
-// @Success 200 {object} interface{} "站点列表数据"
+// @Success 200 {object} model.SitesResponse "站点列表数据"
 // @Failure 502 {object} map[string]string "error": "未配置IYUU_SIGN环境变量"
 // @Failure 500 {object} map[string]string "error": "无法加载站点数据"
+// @Failure 503 {object} map[string]string "error": "站点数据尚未加载，请稍后重试"
+// @Header 503 {string} Retry-After "建议重试间隔（秒）"
 // @Router /sites.json [get]
 func (h *Handler) GetSitesData(c *fiber.Ctx) error {
 	cfg := config.Get()
@@ -192,89 +1060,143 @@ func (h *Handler) GetSitesData(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.Context(), defaultAPITimeout)
 	defer cancel()
 
-	if h.shouldUpdateSitesData(ctx) {
-		if err := h.refreshSitesData(ctx, cfg.IYYUSign); err != nil {
-			log.Printf("[%s] 刷新站点数据失败: %v", sitesUpdateLogPrefix, err)
-		}
-	}
-
+	// 站点数据由后台调度器提前刷新（见 RefreshSitesData），请求路径不直接请求IYUU
 	data, err := h.sitesStore.LoadSitesData(ctx)
 	if err != nil {
-		log.Printf("[%s] 加载站点数据失败: %v", sitesUpdateLogPrefix, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "无法加载站点数据",
-		})
+		if exists, existsErr := h.sitesStore.SitesDataExists(ctx); existsErr != nil || exists {
+			log.Printf("[%s] 加载站点数据失败: %v", sitesUpdateLogPrefix, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "无法加载站点数据",
+			})
+		}
+
+		// 尚无站点数据：等待调度器正在进行的刷新
+		if data, err = h.waitForSitesData(ctx); err != nil {
+			return notLoaded(c, "站点数据尚未加载，请稍后重试")
+		}
 	}
 
 	c.Set("Content-Type", "application/json; charset=utf-8")
 	c.Set("Cache-Control", "public, max-age=3600")
 
-	return c.JSON(data)
+	// 保持 IYUU 原始响应格式，前端按 data.sites 读取
+	return c.JSON(model.SitesResponse{Ret: model.SitesRetOK, Data: *data})
+}
+
+// waitForSitesData 等待正在进行的站点数据刷新完成后重新加载（不发起请求，见 waitForData）
+func (h *Handler) waitForSitesData(ctx context.Context) (*model.SiteCatalog, error) {
+	if _, err := h.lock.Wait(ctx, storage.LeaseSites); err != nil {
+		return nil, err
+	}
+	return h.sitesStore.LoadSitesData(ctx)
 }
 
-// shouldUpdateSitesData 检查站点数据是否需要更新
+// shouldUpdateSitesData 检查站点数据是否需要更新（不存在或即将过期）
 func (h *Handler) shouldUpdateSitesData(ctx context.Context) bool {
-	exists, err := h.sitesStore.SitesDataExists(ctx)
-	return err != nil || !exists
+	ttl, err := h.sitesStore.SitesDataTTL(ctx)
+	return err != nil || ttl < sitesRefreshBefore
 }
 
-// refreshSitesData 刷新站点数据（带容错机制）
-// 返回 error 让调用者知道刷新是否成功
-// Go 1.26: 使用 createHTTPClient 辅助函数，DRY原则落地
-func (h *Handler) refreshSitesData(ctx context.Context, sign string) error {
-	// 防止并发更新
-	if h.lock.IsSitesUpdating() {
-		log.Printf("[%s] 正在更新中，跳过", sitesUpdateLogPrefix)
+// RefreshSitesData 按需刷新站点数据（未配置 IYUU_SIGN 时跳过）
+// 由后台调度器调用，HTTP 请求路径只读取数据
+func (h *Handler) RefreshSitesData(ctx context.Context) error {
+	sign := config.Get().IYYUSign
+	if sign == "" || !h.shouldUpdateSitesData(ctx) {
 		return nil
 	}
+	return h.refreshSitesData(ctx, sign)
+}
 
-	h.lock.SetSitesUpdating(true)
-	defer h.lock.SetSitesUpdating(false)
-
-	log.Printf("[%s] 开始获取站点数据...", sitesUpdateLogPrefix)
+// refreshSitesData 刷新站点数据（合并并发刷新，ctx 只控制等待时间）
+func (h *Handler) refreshSitesData(ctx context.Context, sign string) error {
+	return h.lock.Do(ctx, storage.LeaseSites, func() error {
+		return h.runRefresh(func(ctx context.Context) error {
+			return h.fetchSitesData(ctx, sign)
+		})
+	})
+}
 
-	apiURL, err := url.Parse("https://api.iyuu.cn/index.php")
-	if err != nil {
-		log.Printf("[%s] 解析基础URL失败: %v", sitesUpdateLogPrefix, err)
-		return fmt.Errorf("解析基础URL失败: %w", err)
+// fetchSitesData 获取并保存站点数据（带容错机制）
+// 返回 error 让调用者知道刷新是否成功；上游返回错误时保留已有的站点数据
+func (h *Handler) fetchSitesData(ctx context.Context, sign string) error {
+	// 集群级租约：多实例部署时只有一个实例去请求IYUU
+	lease, err := h.acquireLease(ctx, storage.LeaseSites, sitesUpdateLogPrefix, h.sitesStore.SitesDataExists)
+	if err != nil || lease == nil {
+		return err
 	}
-	params := url.Values{}
-	params.Add("service", "App.Api.Sites")
-	params.Add("sign", sign)
-	params.Add("version", "2.0.0")
-	apiURL.RawQuery = params.Encode()
+	defer releaseLease(lease, sitesUpdateLogPrefix)
 
-	// Go 1.26: 使用辅助函数创建HTTP客户端
-	client := createHTTPClient(ctx, 5*time.Second)
+	ctx, cancel := storage.WithLease(ctx, lease)
+	defer cancel()
 
-	resp, err := client.Get(apiURL.String())
-	if err != nil {
-		log.Printf("[%s] 请求失败: %v", sitesUpdateLogPrefix, err)
-		return fmt.Errorf("请求失败: %w", err)
+	if !h.shouldUpdateSitesData(ctx) {
+		log.Printf("[%s] 站点数据已被其他实例更新，跳过", sitesUpdateLogPrefix)
+		return nil
 	}
-	defer resp.Body.Close()
 
-	// Go 1.26: io.ReadAll 性能已优化，分配更少内存
-	body, err := io.ReadAll(resp.Body)
+	catalog, err := h.crawler.FetchSitesWithContext(ctx, sign)
 	if err != nil {
-		log.Printf("[%s] 读取响应失败: %v", sitesUpdateLogPrefix, err)
-		return fmt.Errorf("读取响应失败: %w", err)
+		log.Printf("[%s] 获取站点数据失败: %v", sitesUpdateLogPrefix, err)
+		return fmt.Errorf("获取站点数据失败: %w", err)
 	}
 
-	// 解析JSON
-	var result any
-	if err := json.Unmarshal(body, &result); err != nil {
-		log.Printf("[%s] 解析JSON失败: %v", sitesUpdateLogPrefix, err)
-		return fmt.Errorf("解析JSON失败: %w", err)
+	if err := leaseLost(ctx); err != nil {
+		log.Printf("[%s] 放弃保存: %v", sitesUpdateLogPrefix, err)
+		return err
 	}
 
 	// 保存到存储（24小时TTL）
-	if err := h.sitesStore.SaveSitesData(ctx, result); err != nil {
+	if err := h.sitesStore.SaveSitesData(ctx, *catalog); err != nil {
 		log.Printf("[%s] 保存数据失败: %v", sitesUpdateLogPrefix, err)
 		return fmt.Errorf("保存数据失败: %w", err)
 	}
 
-	log.Printf("[%s] 站点数据更新成功", sitesUpdateLogPrefix)
+	log.Printf("[%s] 站点数据更新成功（共 %d 个站点）", sitesUpdateLogPrefix, len(catalog.Sites))
 	return nil
 }
 
+// acquireLease 获取刷新租约
+// 其他实例正在刷新时：本实例已有数据则跳过（返回 nil 租约）；还没有数据（冷启动）则等待其他实例保存，
+// 这样等待中的请求能拿到数据而不是 503；等待期间其他实例刷新失败或崩溃、租约被释放时由本实例接手
+func (h *Handler) acquireLease(ctx context.Context, name, logPrefix string, exists func(context.Context) (bool, error)) (storage.Lease, error) {
+	ticker := time.NewTicker(leaseWaitInterval)
+	defer ticker.Stop()
+
+	for waiting := false; ; waiting = true {
+		lease, err := h.lock.AcquireLease(ctx, name)
+		if !errors.Is(err, storage.ErrLeaseHeld) {
+			return lease, err
+		}
+		if ok, _ := exists(ctx); ok {
+			log.Printf("[%s] 其他实例正在更新，跳过", logPrefix)
+			return nil, nil
+		}
+		if !waiting {
+			log.Printf("[%s] 其他实例正在更新，等待其保存数据", logPrefix)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// leaseLost 保存前确认仍持有租约（租约丢失或刷新超时时返回原因）
+func leaseLost(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return nil
+}
+
+// releaseLease 释放刷新租约（使用独立 context，避免请求超时导致租约无法释放）
+func releaseLease(lease storage.Lease, logPrefix string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := lease.Release(ctx); err != nil {
+		log.Printf("[%s] %v", logPrefix, err)
+	}
+}
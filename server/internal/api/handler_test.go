@@ -0,0 +1,1073 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"top1000/internal/config"
+	"top1000/internal/crawler"
+	"top1000/internal/credentials"
+	"top1000/internal/guard"
+	"top1000/internal/model"
+	"top1000/internal/storage"
+)
+
+// fakeCrawler 测试用爬虫（不访问网络）
+type fakeCrawler struct {
+	data  *model.ProcessedData
+	err   error
+	delay time.Duration
+	calls atomic.Int32
+
+	sites      *model.SiteCatalog
+	sitesErr   error
+	sitesCalls atomic.Int32
+}
+
+func (f *fakeCrawler) FetchTop1000WithContext(ctx context.Context) (*model.ProcessedData, error) {
+	f.calls.Add(1)
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return f.data, f.err
+}
+
+func (f *fakeCrawler) FetchSitesWithContext(ctx context.Context, sign string) (*model.SiteCatalog, error) {
+	f.sitesCalls.Add(1)
+	return f.sites, f.sitesErr
+}
+
+// lostLeaseLock 获取的租约立即丢失（模拟续期失败期间被其他实例获取）
+type lostLeaseLock struct {
+	*storage.MemoryStore
+}
+
+func (l lostLeaseLock) AcquireLease(ctx context.Context, name string) (storage.Lease, error) {
+	lost := make(chan struct{})
+	close(lost)
+	return lostLease(lost), nil
+}
+
+type lostLease chan struct{}
+
+func (l lostLease) Done() <-chan struct{}             { return l }
+func (l lostLease) Release(ctx context.Context) error { return nil }
+
+// newTestHandler 使用内存存储创建 Handler
+func newTestHandler(t *testing.T, crawler Crawler) (*Handler, *storage.MemoryStore) {
+	t.Helper()
+
+	store := storage.NewMemoryStore()
+	handler := NewHandler(store, store, store, store, store, store, store)
+	handler.crawler = crawler
+	handler.rules.MinItems = 0 // 测试数据只有 1 条
+	return handler, store
+}
+
+// newTestApp 使用内存存储创建测试应用
+func newTestApp(t *testing.T, crawler Crawler) (*fiber.App, *storage.MemoryStore) {
+	t.Helper()
+
+	handler, store := newTestHandler(t, crawler)
+	app := fiber.New()
+	handler.RegisterRoutes(app)
+	return app, store
+}
+
+func freshData() *model.ProcessedData {
+	return &model.ProcessedData{
+		Time: time.Now().In(config.Get().UpstreamLocation()).Format(model.DataTimeLayout),
+		Items: []model.SiteItem{
+			{SiteName: "测试站点", SiteID: "123", Duplication: "85.5", Size: "1.2TB", ID: 1},
+		},
+	}
+}
+
+func TestMain(m *testing.M) {
+	leaseWaitInterval = 10 * time.Millisecond
+	os.Exit(m.Run())
+}
+
+func TestGetTop1000Data(t *testing.T) {
+	staleData := model.ProcessedData{
+		Time:  "2020-01-01 00:00:00",
+		Items: []model.SiteItem{{SiteName: "过期站点", SiteID: "1", ID: 1}},
+	}
+
+	get := func(t *testing.T, app *fiber.App) (*http.Response, model.ProcessedData) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", "/top1000.json", nil), -1)
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+		var body model.ProcessedData
+		if resp.StatusCode == fiber.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("解析响应失败: %v", err)
+			}
+		}
+		return resp, body
+	}
+
+	// startRefresh 模拟调度器发起的刷新（结束前无法读取到数据）
+	startRefresh := func(store *storage.MemoryStore, fn func() error) {
+		go store.Do(context.Background(), storage.LeaseTop1000, func() error {
+			time.Sleep(20 * time.Millisecond)
+			return fn()
+		})
+		for !store.InFlight(storage.LeaseTop1000) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	t.Run("无数据时等待刷新结果", func(t *testing.T) {
+		crawler := &fakeCrawler{data: freshData()}
+		app, store := newTestApp(t, crawler)
+		startRefresh(store, func() error { return store.SaveData(context.Background(), *freshData()) })
+
+		// 并发请求共享调度器正在进行的刷新，晚到的请求直接读取已保存的数据
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Go(func() {
+				resp, body := get(t, app)
+				if resp.StatusCode != fiber.StatusOK || len(body.Items) != 1 {
+					t.Errorf("状态码 %d，响应 %+v，期望返回刷新后的数据", resp.StatusCode, body)
+				}
+				if resp.Header.Get(headerDataStale) != "" {
+					t.Errorf("新数据不应设置 %s", headerDataStale)
+				}
+			})
+		}
+		wg.Wait()
+
+		if crawler.calls.Load() != 0 {
+			t.Errorf("请求路径不应爬取，实际爬取 %d 次", crawler.calls.Load())
+		}
+	})
+
+	t.Run("无数据且刷新失败", func(t *testing.T) {
+		app, store := newTestApp(t, &fakeCrawler{})
+		startRefresh(store, func() error { return errors.New("网络错误") })
+
+		resp, _ := get(t, app)
+		if resp.StatusCode != fiber.StatusServiceUnavailable {
+			t.Errorf("期望状态码 %d，得到 %d", fiber.StatusServiceUnavailable, resp.StatusCode)
+		}
+		if resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+			t.Error("缺少 Retry-After 响应头")
+		}
+	})
+
+	t.Run("无数据且没有正在进行的刷新", func(t *testing.T) {
+		crawler := &fakeCrawler{data: freshData()}
+		app, _ := newTestApp(t, crawler)
+
+		resp, _ := get(t, app)
+		if resp.StatusCode != fiber.StatusServiceUnavailable || resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+			t.Errorf("状态码 %d，Retry-After %q，期望 503", resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter))
+		}
+		if crawler.calls.Load() != 0 {
+			t.Errorf("请求路径不应爬取，实际爬取 %d 次", crawler.calls.Load())
+		}
+	})
+
+	t.Run("按当前单位约定重新计算数值字段", func(t *testing.T) {
+		app, store := newTestApp(t, &fakeCrawler{})
+		data := freshData()
+		data.Items[0].SizeBytes = 1_319_413_953_331 // 之前按 1024 进位保存
+		_ = store.SaveData(context.Background(), *data)
+
+		_, body := get(t, app)
+		if got := body.Items[0]; got.SizeBytes != 1_200_000_000_000 || got.DuplicationValue != 85.5 {
+			t.Errorf("SizeBytes = %d, DuplicationValue = %v，期望按十进制单位重新计算", got.SizeBytes, got.DuplicationValue)
+		}
+	})
+
+	t.Run("过期数据立即返回并在后台刷新", func(t *testing.T) {
+		crawler := &fakeCrawler{data: freshData(), delay: 20 * time.Millisecond}
+		app, store := newTestApp(t, crawler)
+		_ = store.SaveData(context.Background(), staleData)
+
+		for range 3 {
+			resp, body := get(t, app)
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("期望状态码 %d，得到 %d", fiber.StatusOK, resp.StatusCode)
+			}
+			if len(body.Items) != 1 || body.Items[0].SiteName != "过期站点" {
+				t.Errorf("期望立即返回旧数据，得到 %+v", body)
+			}
+			if resp.Header.Get(headerDataStale) != "true" {
+				t.Errorf("%s = %q, want true", headerDataStale, resp.Header.Get(headerDataStale))
+			}
+			// 旧数据没有 createdAt，按上游时区补齐（2020-01-01 00:00:00 +08:00）
+			if body.CreatedAtUnix != 1577808000 {
+				t.Errorf("CreatedAtUnix = %d, want %d", body.CreatedAtUnix, 1577808000)
+			}
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for store.InFlight(storage.LeaseTop1000) || crawler.calls.Load() == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("后台刷新未完成")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		if crawler.calls.Load() != 1 {
+			t.Errorf("多个过期请求应合并为 1 次刷新，实际爬取 %d 次", crawler.calls.Load())
+		}
+		resp, body := get(t, app)
+		if resp.Header.Get(headerDataStale) != "" || body.Items[0].SiteName != "测试站点" {
+			t.Errorf("刷新后应返回新数据，得到 %+v", body)
+		}
+	})
+}
+
+func TestHandlerClose(t *testing.T) {
+	ctx := context.Background()
+	crawler := &fakeCrawler{data: freshData(), delay: time.Minute}
+	handler, store := newTestHandler(t, crawler)
+	stale := model.ProcessedData{Time: "2020-01-01 00:00:00", Items: freshData().Items}
+	_ = store.SaveData(ctx, stale)
+
+	// 过期请求触发的后台刷新
+	handler.refreshInBackground()
+	for crawler.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := handler.Close(closeCtx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if store.InFlight(storage.LeaseTop1000) {
+		t.Error("Close() 返回后刷新仍在进行")
+	}
+	if data, _ := store.LoadData(ctx); data.Time != stale.Time {
+		t.Errorf("关闭时取消的刷新不应保存数据，当前数据时间 %s", data.Time)
+	}
+
+	if err := handler.RefreshData(ctx); !errors.Is(err, errClosed) {
+		t.Errorf("关闭后 RefreshData() error = %v, want errClosed", err)
+	}
+	if crawler.calls.Load() != 1 {
+		t.Errorf("关闭后不应再爬取，实际爬取 %d 次", crawler.calls.Load())
+	}
+}
+
+func TestGetTop1000DataEnrich(t *testing.T) {
+	ctx := context.Background()
+	app, store := newTestApp(t, &fakeCrawler{})
+	data := freshData()
+	_ = store.SaveData(ctx, model.ProcessedData{Time: "2020-01-01 00:00:00", Items: data.Items}) // 历史快照
+	_ = store.SaveData(ctx, *data)
+
+	get := func(t *testing.T, path string) (*http.Response, model.ProcessedData) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+		var body model.ProcessedData
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+		return resp, body
+	}
+
+	t.Run("站点目录不可用时不补充", func(t *testing.T) {
+		resp, body := get(t, "/top1000.json?enrich=1")
+		if resp.Header.Get(headerSitesEnriched) != "false" || body.Items[0].DetailsURL != "" {
+			t.Errorf("%s = %q，响应 %+v", headerSitesEnriched, resp.Header.Get(headerSitesEnriched), body.Items[0])
+		}
+	})
+
+	_ = store.SaveSitesData(ctx, model.SiteCatalog{Sites: []model.Site{
+		{Site: "测试站点", Nickname: "测试", BaseURL: "test.org", DetailsPage: "details.php?id={}", DownloadPage: "download.php?id={}&passkey={passkey}", IsHTTPS: 1},
+	}})
+
+	tests := []struct {
+		name        string
+		path        string
+		wantDetails string
+	}{
+		{name: "默认不补充", path: "/top1000.json"},
+		{name: "补充最新数据", path: "/top1000.json?enrich=1", wantDetails: "https://test.org/details.php?id=123"},
+		{name: "补充历史快照", path: "/top1000.json?at=2020-01-01&enrich=true", wantDetails: "https://test.org/details.php?id=123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body := get(t, tt.path)
+			if got := body.Items[0]; got.DetailsURL != tt.wantDetails {
+				t.Errorf("DetailsURL = %q, want %q（%+v）", got.DetailsURL, tt.wantDetails, got)
+			}
+			if tt.wantDetails != "" && (body.Items[0].Nickname != "测试" || body.Items[0].DownloadURL != "https://test.org/download.php?id=123") {
+				t.Errorf("Items[0] = %+v", body.Items[0])
+			}
+		})
+	}
+
+	// 补充的字段只在响应中，不写入存储
+	if stored, _ := store.LoadData(ctx); stored.Items[0].DetailsURL != "" {
+		t.Errorf("存储中的数据 = %+v", stored.Items[0])
+	}
+}
+
+func TestRefreshData(t *testing.T) {
+	ctx := context.Background()
+
+	newHandler := func(crawler Crawler) (*Handler, *storage.MemoryStore) {
+		store := storage.NewMemoryStore()
+		handler := NewHandler(store, store, store, store, store, store, store)
+		handler.crawler = crawler
+		handler.rules.MinItems = 0 // 测试数据只有 1 条
+		return handler, store
+	}
+
+	t.Run("无数据时爬取并保存", func(t *testing.T) {
+		handler, store := newHandler(&fakeCrawler{data: freshData()})
+
+		if err := handler.RefreshData(ctx); err != nil {
+			t.Fatalf("RefreshData() error = %v", err)
+		}
+		if exists, _ := store.DataExists(ctx); !exists {
+			t.Error("爬取结果未保存到存储")
+		}
+	})
+
+	t.Run("数据新鲜时不爬取", func(t *testing.T) {
+		crawler := &fakeCrawler{data: freshData()}
+		handler, store := newHandler(crawler)
+		_ = store.SaveData(ctx, *freshData())
+
+		if err := handler.RefreshData(ctx); err != nil {
+			t.Fatalf("RefreshData() error = %v", err)
+		}
+		if crawler.calls.Load() != 0 {
+			t.Errorf("数据新鲜时不应爬取，实际爬取 %d 次", crawler.calls.Load())
+		}
+	})
+
+	t.Run("其他实例持有租约时不爬取", func(t *testing.T) {
+		crawler := &fakeCrawler{data: freshData()}
+		handler, store := newHandler(crawler)
+		_ = store.SaveData(ctx, model.ProcessedData{Time: "2020-01-01 00:00:00", Items: freshData().Items})
+
+		lease, err := store.AcquireLease(ctx, storage.LeaseTop1000)
+		if err != nil {
+			t.Fatalf("AcquireLease() error = %v", err)
+		}
+		defer lease.Release(ctx)
+
+		if err := handler.RefreshData(ctx); err != nil {
+			t.Fatalf("RefreshData() error = %v", err)
+		}
+		if crawler.calls.Load() != 0 {
+			t.Errorf("租约被占用时不应爬取，实际爬取 %d 次", crawler.calls.Load())
+		}
+	})
+
+	t.Run("冷启动时等待其他实例保存", func(t *testing.T) {
+		crawler := &fakeCrawler{data: freshData()}
+		handler, store := newHandler(crawler)
+
+		lease, err := store.AcquireLease(ctx, storage.LeaseTop1000)
+		if err != nil {
+			t.Fatalf("AcquireLease() error = %v", err)
+		}
+		defer lease.Release(ctx)
+
+		done := make(chan error, 1)
+		go func() { done <- handler.RefreshData(ctx) }()
+		time.Sleep(3 * leaseWaitInterval)
+		_ = store.SaveData(ctx, *freshData())
+
+		if err := <-done; err != nil {
+			t.Fatalf("RefreshData() error = %v", err)
+		}
+		if crawler.calls.Load() != 0 {
+			t.Errorf("其他实例保存数据后不应爬取，实际爬取 %d 次", crawler.calls.Load())
+		}
+	})
+
+	t.Run("其他实例释放租约后接手", func(t *testing.T) {
+		crawler := &fakeCrawler{data: freshData()}
+		handler, store := newHandler(crawler)
+
+		lease, err := store.AcquireLease(ctx, storage.LeaseTop1000)
+		if err != nil {
+			t.Fatalf("AcquireLease() error = %v", err)
+		}
+
+		done := make(chan error, 1)
+		go func() { done <- handler.RefreshData(ctx) }()
+		time.Sleep(3 * leaseWaitInterval)
+		_ = lease.Release(ctx) // 其他实例刷新失败
+
+		if err := <-done; err != nil {
+			t.Fatalf("RefreshData() error = %v", err)
+		}
+		if crawler.calls.Load() != 1 {
+			t.Errorf("期望接手后爬取 1 次，实际爬取 %d 次", crawler.calls.Load())
+		}
+	})
+
+	t.Run("租约丢失时不保存", func(t *testing.T) {
+		crawler := &fakeCrawler{data: freshData(), delay: 20 * time.Millisecond}
+		handler, store := newHandler(crawler)
+		handler.lock = lostLeaseLock{store}
+
+		if err := handler.RefreshData(ctx); !errors.Is(err, storage.ErrLeaseLost) {
+			t.Fatalf("RefreshData() error = %v, want ErrLeaseLost", err)
+		}
+		if exists, _ := store.DataExists(ctx); exists {
+			t.Error("租约丢失后不应保存数据")
+		}
+	})
+
+	t.Run("上游未变化时只记录检查时间", func(t *testing.T) {
+		stale := model.ProcessedData{
+			Time:  "2020-01-01 00:00:00",
+			Items: []model.SiteItem{{SiteName: "过期站点", SiteID: "1", Duplication: "80", Size: "1TB", ID: 1}},
+		}
+		unchanged := stale
+		unchanged.Items = []model.SiteItem{stale.Items[0]}
+		unchanged.Items[0].ComputeValues() // 派生字段不同不算变化
+
+		crawler := &fakeCrawler{data: &unchanged}
+		handler, store := newHandler(crawler)
+		_ = store.SaveData(ctx, stale)
+
+		for range 2 {
+			if err := handler.RefreshData(ctx); err != nil {
+				t.Fatalf("RefreshData() error = %v", err)
+			}
+		}
+		// 第二次运行时刚确认过上游未变化，不再重复爬取
+		if crawler.calls.Load() != 1 {
+			t.Errorf("期望爬取 1 次，实际爬取 %d 次", crawler.calls.Load())
+		}
+
+		loaded, err := store.LoadData(ctx)
+		if err != nil || loaded.LastCheckedAt.IsZero() {
+			t.Errorf("LoadData() = %+v, %v，期望记录检查时间", loaded, err)
+		}
+		if loaded != nil && loaded.Items[0].SizeBytes != 0 {
+			t.Error("数据未变化时不应重新保存")
+		}
+		if storage.IsExpired(loaded) {
+			t.Error("刚确认过上游未变化，不应判定为过期")
+		}
+	})
+
+	t.Run("异常数据放入隔离区", func(t *testing.T) {
+		current := model.ProcessedData{Time: "2020-01-01 00:00:00"}
+		for i := range 4 {
+			current.Items = append(current.Items, model.SiteItem{SiteName: "站点", SiteID: "1", ID: i + 1})
+		}
+
+		// 只有 1 条的截断数据：条目数减少 75%
+		crawler := &fakeCrawler{data: freshData()}
+		handler, store := newHandler(crawler)
+		_ = store.SaveData(ctx, current)
+
+		for range 2 {
+			err := handler.RefreshData(ctx)
+			var violations guard.Violations
+			if !errors.As(err, &violations) {
+				t.Fatalf("RefreshData() error = %v, want guard.Violations", err)
+			}
+		}
+
+		loaded, err := store.LoadData(ctx)
+		if err != nil || len(loaded.Items) != 4 {
+			t.Errorf("LoadData() = %+v, %v，异常数据不应覆盖当前数据", loaded, err)
+		}
+		// 同一份数据只隔离一次
+		entries, err := store.ListQuarantine(ctx)
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("ListQuarantine() = %d 条, %v, want 1", len(entries), err)
+		}
+		if entries[0].ItemCount != 1 || len(entries[0].Reasons) != 1 || entries[0].Data == nil {
+			t.Errorf("隔离记录不符合预期: %+v", entries[0])
+		}
+	})
+
+	t.Run("保存原始内容", func(t *testing.T) {
+		raw := model.NewRawPayload("test", []byte("<html>维护中</html>"), time.Now())
+		handler, store := newHandler(&fakeCrawler{err: &crawler.ParseError{Err: errors.New("数据列表不能为空"), Raw: raw}})
+
+		// 解析失败的内容也保存（解析器修复后可以重新解析），相同内容只保存一次
+		for range 2 {
+			_ = handler.RefreshData(ctx)
+		}
+		metas, err := store.ListRaw(ctx)
+		if err != nil || len(metas) != 1 || metas[0].Hash != raw.Hash {
+			t.Errorf("ListRaw() = %+v, %v，期望 1 条", metas, err)
+		}
+	})
+
+	t.Run("爬取失败", func(t *testing.T) {
+		handler, store := newHandler(&fakeCrawler{err: errors.New("网络错误")})
+
+		if err := handler.RefreshData(ctx); err == nil {
+			t.Error("RefreshData() 期望返回错误")
+		}
+		if exists, _ := store.DataExists(ctx); exists {
+			t.Error("爬取失败时不应写入数据")
+		}
+	})
+}
+
+func TestGetSitesDataWithoutSign(t *testing.T) {
+	app, _ := newTestApp(t, &fakeCrawler{})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/sites.json", nil))
+	if err != nil {
+		t.Fatalf("Test() 失败: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusBadGateway {
+		t.Errorf("期望状态码 %d，得到 %d", fiber.StatusBadGateway, resp.StatusCode)
+	}
+}
+
+func TestRefreshSitesData(t *testing.T) {
+	ctx := context.Background()
+	sites := &model.SiteCatalog{Sites: []model.Site{{ID: 1, Site: "site1", BaseURL: "site1.example"}}}
+
+	newHandler := func(crawler Crawler) (*Handler, *storage.MemoryStore) {
+		store := storage.NewMemoryStore()
+		handler := NewHandler(store, store, store, store, store, store, store)
+		handler.crawler = crawler
+		return handler, store
+	}
+
+	t.Run("获取成功后保存", func(t *testing.T) {
+		handler, store := newHandler(&fakeCrawler{sites: sites})
+
+		if err := handler.refreshSitesData(ctx, "sign"); err != nil {
+			t.Fatalf("refreshSitesData() error = %v", err)
+		}
+		loaded, err := store.LoadSitesData(ctx)
+		if err != nil || len(loaded.Sites) != 1 {
+			t.Errorf("LoadSitesData() = %v, %v, want 1 site", loaded, err)
+		}
+	})
+
+	t.Run("上游返回错误时不缓存", func(t *testing.T) {
+		handler, store := newHandler(&fakeCrawler{sitesErr: errors.New("IYUU返回错误（ret=403）: sign错误")})
+
+		if err := handler.refreshSitesData(ctx, "sign"); err == nil {
+			t.Fatal("refreshSitesData() 期望返回错误")
+		}
+		if exists, _ := store.SitesDataExists(ctx); exists {
+			t.Error("错误响应不应写入存储")
+		}
+	})
+
+	t.Run("站点数据即将过期时才刷新", func(t *testing.T) {
+		handler, store := newHandler(&fakeCrawler{sites: sites})
+
+		if !handler.shouldUpdateSitesData(ctx) {
+			t.Error("无站点数据时应刷新")
+		}
+		_ = store.SaveSitesData(ctx, *sites)
+		if handler.shouldUpdateSitesData(ctx) {
+			t.Error("站点数据剩余有效期充足时不应刷新")
+		}
+	})
+}
+
+func TestGetHistory(t *testing.T) {
+	app, store := newTestApp(t, &fakeCrawler{})
+	ctx := context.Background()
+
+	older := model.ProcessedData{
+		Time:  "2026-01-18 07:50:56",
+		Items: []model.SiteItem{{SiteName: "旧站点", SiteID: "1", ID: 1}},
+	}
+	_ = store.SaveData(ctx, older)
+	_ = store.SaveData(ctx, *freshData())
+
+	t.Run("列出快照", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/history", nil))
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+
+		var body HistoryResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+		if len(body.Snapshots) != 2 || body.Snapshots[1].Time != older.Time {
+			t.Errorf("快照列表不符合预期: %+v", body.Snapshots)
+		}
+	})
+
+	t.Run("按时间获取快照", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/top1000.json?at=2026-01-18", nil))
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("期望状态码 %d，得到 %d", fiber.StatusOK, resp.StatusCode)
+		}
+
+		var body model.ProcessedData
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+		if body.Time != older.Time {
+			t.Errorf("Time = %v, want %v", body.Time, older.Time)
+		}
+	})
+
+	t.Run("快照不存在", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/top1000.json?at=2020-01-01", nil))
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Errorf("期望状态码 %d，得到 %d", fiber.StatusNotFound, resp.StatusCode)
+		}
+	})
+
+	t.Run("快照时间格式错误", func(t *testing.T) {
+		for _, at := range []string{"foo", "2026-01-19T10:00:00"} {
+			resp, err := app.Test(httptest.NewRequest("GET", "/top1000.json?at="+url.QueryEscape(at), nil))
+			if err != nil {
+				t.Fatalf("Test() 失败: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Errorf("at=%s 期望状态码 %d，得到 %d", at, fiber.StatusBadRequest, resp.StatusCode)
+			}
+		}
+	})
+}
+
+func TestGetDiff(t *testing.T) {
+	app, store := newTestApp(t, &fakeCrawler{})
+	ctx := context.Background()
+
+	get := func(t *testing.T, url string) (*model.SnapshotDiff, int) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", url, nil))
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			return nil, resp.StatusCode
+		}
+		var diff model.SnapshotDiff
+		if err := json.NewDecoder(resp.Body).Decode(&diff); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+		return &diff, resp.StatusCode
+	}
+
+	_ = store.SaveData(ctx, model.ProcessedData{
+		Time:  "2026-01-17 07:50:56",
+		Items: []model.SiteItem{{SiteName: "站点A", SiteID: "1", ID: 1}},
+	})
+
+	t.Run("快照不足", func(t *testing.T) {
+		if _, status := get(t, "/api/diff"); status != fiber.StatusNotFound {
+			t.Errorf("期望状态码 %d，得到 %d", fiber.StatusNotFound, status)
+		}
+	})
+
+	_ = store.SaveData(ctx, model.ProcessedData{
+		Time:  "2026-01-18 07:50:56",
+		Items: []model.SiteItem{{SiteName: "站点A", SiteID: "1", ID: 1}, {SiteName: "站点B", SiteID: "2", ID: 2}},
+	})
+	_ = store.SaveData(ctx, model.ProcessedData{
+		Time:  "2026-01-19 07:50:56",
+		Items: []model.SiteItem{{SiteName: "站点B", SiteID: "2", ID: 1}},
+	})
+
+	t.Run("默认比较上一次与最新快照", func(t *testing.T) {
+		diff, status := get(t, "/api/diff")
+		if status != fiber.StatusOK {
+			t.Fatalf("期望状态码 %d，得到 %d", fiber.StatusOK, status)
+		}
+		if diff.From != "2026-01-18 07:50:56" || diff.To != "2026-01-19 07:50:56" {
+			t.Errorf("From/To = %v/%v", diff.From, diff.To)
+		}
+		if len(diff.Removed) != 1 || len(diff.Changed) != 1 || len(diff.Added) != 0 {
+			t.Errorf("差异不符合预期: %+v", diff)
+		}
+	})
+
+	t.Run("指定范围", func(t *testing.T) {
+		diff, status := get(t, "/api/diff?from=2026-01-17&to=2026-01-18")
+		if status != fiber.StatusOK {
+			t.Fatalf("期望状态码 %d，得到 %d", fiber.StatusOK, status)
+		}
+		if len(diff.Added) != 1 || diff.Added[0].SiteName != "站点B" {
+			t.Errorf("Added = %+v, want [站点B]", diff.Added)
+		}
+	})
+
+	t.Run("只指定to时与其上一个快照比较", func(t *testing.T) {
+		diff, status := get(t, "/api/diff?to=2026-01-18")
+		if status != fiber.StatusOK {
+			t.Fatalf("期望状态码 %d，得到 %d", fiber.StatusOK, status)
+		}
+		if diff.From != "2026-01-17 07:50:56" {
+			t.Errorf("From = %v, want 2026-01-17 07:50:56", diff.From)
+		}
+	})
+}
+
+func TestGetQuarantine(t *testing.T) {
+	app, store := newTestApp(t, &fakeCrawler{})
+	_ = store.Quarantine(context.Background(), guard.NewQuarantineEntry(freshData(), []string{"条目数 1 少于 100"}, time.UnixMilli(1768780256000)))
+
+	t.Run("列表不含数据", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/quarantine", nil))
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+
+		var body QuarantineResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+		if len(body.Entries) != 1 || body.Entries[0].ID != "1768780256000" || body.Entries[0].Data != nil {
+			t.Errorf("隔离区列表不符合预期: %+v", body.Entries)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{name: "获取完整数据", id: "1768780256000", wantStatus: fiber.StatusOK},
+		{name: "记录不存在", id: "1", wantStatus: fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/quarantine/"+tt.id, nil))
+			if err != nil {
+				t.Fatalf("Test() 失败: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("期望状态码 %d，得到 %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantStatus != fiber.StatusOK {
+				return
+			}
+
+			var entry model.QuarantineEntry
+			if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+				t.Fatalf("解析响应失败: %v", err)
+			}
+			if entry.Data == nil || len(entry.Data.Items) != 1 {
+				t.Errorf("Data = %+v，期望包含完整数据", entry.Data)
+			}
+		})
+	}
+}
+
+func TestGetLastCrawl(t *testing.T) {
+	fake := &fakeCrawler{}
+	handler, _ := newTestHandler(t, fake)
+	app := fiber.New()
+	handler.RegisterRoutes(app)
+
+	get := func(t *testing.T) (int, model.ParseReport) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/crawl/last", nil))
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+		var report model.ParseReport
+		if resp.StatusCode == fiber.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+				t.Fatalf("解析响应失败: %v", err)
+			}
+		}
+		return resp.StatusCode, report
+	}
+
+	if status, _ := get(t); status != fiber.StatusNotFound {
+		t.Errorf("没有报告时期望状态码 %d，得到 %d", fiber.StatusNotFound, status)
+	}
+
+	// 上游格式变化导致解析失败时也能看到报告
+	fake.err = &crawler.ParseError{
+		Err:    errors.New("数据列表不能为空"),
+		Report: &model.ParseReport{Format: model.ReportFormatText, Groups: 1000, SkippedGroups: 1000},
+	}
+	if err := handler.RefreshData(context.Background()); err == nil {
+		t.Fatal("RefreshData() 期望返回解析错误")
+	}
+
+	status, report := get(t)
+	if status != fiber.StatusOK || report.SkippedGroups != 1000 {
+		t.Errorf("状态码 %d，报告 %+v，期望返回解析失败的报告", status, report)
+	}
+}
+
+func TestAdminAPI(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	handler := NewHandler(store, store, store, store, store, store, store)
+	handler.adminToken = "secret"
+	app := fiber.New()
+	handler.RegisterRoutes(app)
+
+	// 旧解析器漏掉了第二个站点
+	snapshot := model.ProcessedData{
+		Time:  "2026-01-19 07:50:56",
+		Items: []model.SiteItem{{SiteName: "站点A", SiteID: "1", Duplication: "1", Size: "1TB", ID: 1}},
+	}
+	_ = store.SaveData(ctx, snapshot)
+	body := "create time 2026-01-19 07:50:56 by xxx\n\n站名：站点A 【ID：1】\n重复度：1\n文件大小：1TB\n\n站名：站点B 【ID：2】\n重复度：2\n文件大小：2TB\n"
+	raw := model.NewRawPayload("test", []byte(body), time.Now())
+	_ = store.SaveRaw(ctx, *raw)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "缺少令牌", method: "GET", path: "/api/admin/raw", wantStatus: fiber.StatusUnauthorized},
+		{name: "令牌错误", method: "GET", path: "/api/admin/raw", token: "wrong", wantStatus: fiber.StatusUnauthorized},
+		{name: "列出原始内容", method: "GET", path: "/api/admin/raw", token: "secret", wantStatus: fiber.StatusOK, wantBody: raw.Hash},
+		{name: "获取原始内容", method: "GET", path: "/api/admin/raw/" + raw.ID, token: "secret", wantStatus: fiber.StatusOK, wantBody: body},
+		{name: "原始内容不存在", method: "GET", path: "/api/admin/raw/1", token: "secret", wantStatus: fiber.StatusNotFound},
+		{name: "重新解析（dry run）", method: "POST", path: "/api/admin/reparse?dryRun=true", token: "secret", wantStatus: fiber.StatusOK, wantBody: `"status":"pending"`},
+		{name: "重新解析", method: "POST", path: "/api/admin/reparse", token: "secret", wantStatus: fiber.StatusOK, wantBody: `"status":"rewritten"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("Test() 失败: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("期望状态码 %d，得到 %d", tt.wantStatus, resp.StatusCode)
+			}
+			got, _ := io.ReadAll(resp.Body)
+			if !strings.Contains(string(got), tt.wantBody) {
+				t.Errorf("响应 = %s，期望包含 %s", got, tt.wantBody)
+			}
+		})
+	}
+
+	if current, _ := store.LoadData(ctx); len(current.Items) != 2 {
+		t.Errorf("重新解析后当前数据 = %+v，期望 2 条", current)
+	}
+
+	t.Run("关闭后不再重新解析", func(t *testing.T) {
+		if err := handler.Close(ctx); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		req := httptest.NewRequest("POST", "/api/admin/reparse", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusServiceUnavailable {
+			t.Errorf("期望状态码 %d，得到 %d", fiber.StatusServiceUnavailable, resp.StatusCode)
+		}
+	})
+
+	t.Run("未配置令牌时不开放", func(t *testing.T) {
+		handler.adminToken = ""
+		req := httptest.NewRequest("GET", "/api/admin/raw", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Errorf("期望状态码 %d，得到 %d", fiber.StatusNotFound, resp.StatusCode)
+		}
+	})
+}
+
+func TestCredentialsAPI(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	handler := NewHandler(store, store, store, store, store, store, store)
+	app := fiber.New()
+	handler.RegisterRoutes(app)
+
+	_ = store.SaveData(ctx, *freshData())
+	_ = store.SaveSitesData(ctx, model.SiteCatalog{Sites: []model.Site{
+		{Site: "测试站点", BaseURL: "test.org", DetailsPage: "details.php?id={}", DownloadPage: "download.php?id={}&passkey={passkey}", IsHTTPS: 1},
+	}})
+
+	request := func(t *testing.T, method, path, token, body string) (*http.Response, string) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("Test() 失败: %v", err)
+		}
+		got, _ := io.ReadAll(resp.Body)
+		return resp, string(got)
+	}
+
+	t.Run("未配置用户令牌时不开放", func(t *testing.T) {
+		if resp, _ := request(t, "GET", "/api/credentials", "alice-token-0123456", ""); resp.StatusCode != fiber.StatusNotFound {
+			t.Errorf("期望状态码 %d，得到 %d", fiber.StatusNotFound, resp.StatusCode)
+		}
+		if resp, _ := request(t, "GET", "/top1000.json?enrich=1", "alice-token-0123456", ""); resp.StatusCode != fiber.StatusOK {
+			t.Errorf("未启用时应忽略令牌，得到状态码 %d", resp.StatusCode)
+		}
+	})
+
+	vault, err := credentials.NewVault(store, []byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("NewVault() error = %v", err)
+	}
+	handler.users = map[string]string{"alice-token-0123456": "alice", "bob-token-012345678": "bob"}
+	handler.vault = vault
+
+	var logs strings.Builder
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "缺少令牌", method: "GET", path: "/api/credentials", wantStatus: fiber.StatusUnauthorized},
+		{name: "令牌错误", method: "GET", path: "/api/credentials", token: "wrong", wantStatus: fiber.StatusUnauthorized},
+		{name: "请求体无效", method: "PUT", path: "/api/credentials/测试站点", token: "alice-token-0123456", body: `["alice-passkey"]`, wantStatus: fiber.StatusBadRequest},
+		{name: "不支持的凭据", method: "PUT", path: "/api/credentials/测试站点", token: "alice-token-0123456", body: `{"cookie":"alice-cookie"}`, wantStatus: fiber.StatusBadRequest},
+		{name: "保存凭据", method: "PUT", path: "/api/credentials/测试站点", token: "alice-token-0123456", body: `{"passkey":"alice-passkey"}`, wantStatus: fiber.StatusOK, wantBody: `"测试站点":["passkey"]`},
+		{name: "列出凭据", method: "GET", path: "/api/credentials", token: "alice-token-0123456", wantStatus: fiber.StatusOK, wantBody: `"user":"alice"`},
+		{name: "其他用户没有凭据", method: "GET", path: "/api/credentials", token: "bob-token-012345678", wantStatus: fiber.StatusOK, wantBody: `"sites":{}`},
+		{name: "个性化下载链接", method: "GET", path: "/top1000.json?enrich=1", token: "alice-token-0123456", wantStatus: fiber.StatusOK, wantBody: "download.php?id=123\\u0026passkey=alice-passkey"},
+		{name: "其他用户的下载链接", method: "GET", path: "/top1000.json?enrich=1", token: "bob-token-012345678", wantStatus: fiber.StatusOK, wantBody: `"downloadUrl":"https://test.org/download.php?id=123"`},
+		{name: "匿名下载链接", method: "GET", path: "/top1000.json?enrich=1", wantStatus: fiber.StatusOK, wantBody: `"downloadUrl":"https://test.org/download.php?id=123"`},
+		{name: "补充链接时令牌错误", method: "GET", path: "/top1000.json?enrich=1", token: "wrong", wantStatus: fiber.StatusUnauthorized},
+		{name: "删除凭据", method: "DELETE", path: "/api/credentials/测试站点", token: "alice-token-0123456", wantStatus: fiber.StatusOK, wantBody: `"sites":{}`},
+		{name: "删除后的下载链接", method: "GET", path: "/top1000.json?enrich=1", token: "alice-token-0123456", wantStatus: fiber.StatusOK, wantBody: `"downloadUrl":"https://test.org/download.php?id=123"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := request(t, tt.method, tt.path, tt.token, tt.body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("期望状态码 %d，得到 %d（%s）", tt.wantStatus, resp.StatusCode, body)
+			}
+			if !strings.Contains(body, tt.wantBody) {
+				t.Errorf("响应 = %s，期望包含 %s", body, tt.wantBody)
+			}
+			if strings.HasPrefix(tt.path, "/api/credentials") && strings.Contains(body, "alice-") {
+				t.Errorf("响应 = %s，不应包含凭据值", body)
+			}
+			if tt.token != "" && resp.StatusCode == fiber.StatusOK && resp.Header.Get("Cache-Control") != cachePrivate {
+				t.Errorf("Cache-Control = %q，带令牌的响应不能被共享缓存", resp.Header.Get("Cache-Control"))
+			}
+		})
+	}
+
+	if strings.Contains(logs.String(), "alice-passkey") {
+		t.Errorf("日志中包含凭据: %s", logs.String())
+	}
+	if sealed, _ := store.LoadCredentials(ctx, "alice"); len(sealed) != 0 {
+		t.Errorf("删除最后一个站点后仍有密文")
+	}
+
+	t.Run("同时保存多个站点", func(t *testing.T) {
+		sites := []string{"site1", "site2", "site3", "site4", "site5", "site6", "site7", "site8"}
+		var wg sync.WaitGroup
+		for _, site := range sites {
+			wg.Go(func() {
+				req := httptest.NewRequest("PUT", "/api/credentials/"+site, strings.NewReader(`{"passkey":"bob-passkey"}`))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer bob-token-012345678")
+				if resp, err := app.Test(req, -1); err != nil || resp.StatusCode != fiber.StatusOK {
+					t.Errorf("保存 %s 失败: %v", site, err)
+				}
+			})
+		}
+		wg.Wait()
+
+		creds, err := vault.Load(ctx, "bob")
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		for _, site := range sites {
+			if creds[site]["passkey"] != "bob-passkey" {
+				t.Errorf("并发保存后丢失了 %s 的凭据", site)
+			}
+		}
+	})
+}
+
+func TestGetStatus(t *testing.T) {
+	app, store := newTestApp(t, &fakeCrawler{})
+
+	release := make(chan struct{})
+	go store.Do(context.Background(), storage.LeaseSites, func() error {
+		<-release
+		return nil
+	})
+	defer close(release)
+	for !store.InFlight(storage.LeaseSites) {
+		time.Sleep(time.Millisecond)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/status", nil))
+	if err != nil {
+		t.Fatalf("Test() 失败: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("期望状态码 %d，得到 %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	var body StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if body.Upstreams == nil {
+		t.Error("upstreams 应为数组")
+	}
+	if !body.Refreshing[storage.LeaseSites] || body.Refreshing[storage.LeaseTop1000] {
+		t.Errorf("refreshing = %v, want sites 正在刷新", body.Refreshing)
+	}
+}
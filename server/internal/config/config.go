@@ -1,13 +1,19 @@
 package config
 
 import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/robfig/cron/v3"
 )
 
 // Go 1.26: 泛型环境变量解析器类型
@@ -30,23 +36,131 @@ func getEnvGeneric[T any](key string, defaultValue T, parser envParser[T]) T {
 
 // 默认值常量
 const (
-	DefaultPort         = "7066"
-	DefaultWebDistDir   = "./web-dist"
-	DefaultAPIURL       = "https://api.iyuu.cn/top1000.php"
-	DefaultDataExpire   = 24 * time.Hour // 数据过期检测阈值
-	DefaultRedisDB      = 0              // Redis数据库编号
-	DefaultRedisKey     = "top1000:data" // Redis key（Top1000数据）
-	DefaultSitesKey     = "top1000:sites" // Redis key（站点数据）
-	DefaultSitesExpire  = 24 * time.Hour // 站点数据过期时间
+	DefaultPort                 = "7066"
+	DefaultWebDistDir           = "./web-dist"
+	DefaultAPIURL               = "https://api.iyuu.cn/top1000.php" // IYUU Top1000接口（SOURCE_URLS 默认值）
+	DefaultUpstreamTimezone     = "Asia/Shanghai"                   // IYUU数据时间所在时区
+	DefaultUpstreamUserAgent    = "top1000/1.0"                     // 请求上游时的User-Agent
+	DefaultUpstreamMode         = UpstreamModeLive                  // 上游请求模式
+	DefaultFixturesDir          = "./fixtures"                      // 录制/回放上游响应的目录
+	DefaultSitesAPIURL          = "https://api.iyuu.cn/index.php"   // IYUU站点接口
+	DefaultDataExpire           = 24 * time.Hour                    // 数据过期检测阈值
+	DefaultRedisDB              = 0                                 // Redis数据库编号
+	DefaultRedisKeyPrefix       = "top1000"                         // Redis key命名空间（与旧版本的固定key一致）
+	DefaultSitesExpire          = 24 * time.Hour                    // 站点数据过期时间
+	DefaultStorageBackend       = StorageBackendRedis               // 默认存储后端
+	DefaultDataDir              = "./data"                          // 文件存储后端的数据目录
+	DefaultHistoryMaxCount      = 30                                // 最多保留的历史快照数量
+	DefaultHistoryMaxAge        = 0                                 // 历史快照最长保留时间（0表示不按时间清理）
+	DefaultRawMaxCount          = 30                                // 最多保留的上游原始内容数量
+	DefaultRawMaxAge            = 30 * 24 * time.Hour               // 上游原始内容最长保留时间
+	DefaultLockTTL              = 30 * time.Second                  // 刷新租约有效期（持有期间自动续期）
+	DefaultRedisPoolSize        = 3                                 // Redis连接池大小（每个节点）
+	DefaultRedisMinIdleConns    = 1                                 // Redis最少空闲连接数
+	DefaultRedisPoolTimeout     = 0                                 // 等待空闲连接的超时时间（0表示ReadTimeout+1s）
+	DefaultRedisConnMaxIdle     = 30 * time.Minute                  // 空闲连接最长保留时间
+	DefaultRedisCompression     = CompressionGzip                   // Redis数据压缩算法
+	DefaultRefreshInterval      = 15 * time.Minute                  // 后台刷新检查间隔（数据未过期时不会请求IYUU）
+	DefaultRefreshJitter        = 30 * time.Second                  // 每次刷新的最大随机延迟（错开多实例请求）
+	DefaultRetryAttempts        = 3                                 // 上游请求最多尝试次数（含首次）
+	DefaultRetryBaseDelay       = 1 * time.Second                   // 首次重试前的等待时间（之后指数增长）
+	DefaultRetryMaxDelay        = 30 * time.Second                  // 单次重试等待的上限
+	DefaultRetryJitter          = 0.2                               // 重试等待的随机浮动比例（0-1）
+	DefaultBreakerThreshold     = 5                                 // 连续失败多少次后熔断
+	DefaultBreakerCooldown      = 1 * time.Minute                   // 熔断后多久允许一次探测请求
+	DefaultGuardMinItems        = 100                               // 新数据至少包含的条目数
+	DefaultGuardMaxDropPercent  = 50.0                              // 条目数相对当前数据最多减少的百分比
+	DefaultGuardMaxSkippedRatio = 0.2                               // 解析时最多跳过的数据比例（0-1）
+	DefaultQuarantineMax        = 10                                // 隔离区最多保留的记录数
+	MinAPITokenLength           = 16                                // 用户API令牌的最短长度
+	SecretKeyLength             = 32                                // 站点凭据加密密钥长度（AES-256）
+)
+
+// Redis 数据压缩算法（读取时自动识别，可以随时切换）
+const (
+	CompressionNone = "none" // 不压缩（写入原始 JSON）
+	CompressionGzip = "gzip" // gzip（标准库）
+	CompressionZstd = "zstd" // zstd（压缩率与速度更好）
+)
+
+// Redis 部署模式（由配置推断，见 Config.RedisMode）
+const (
+	RedisModeStandalone = "standalone" // 单机（REDIS_ADDR 或 REDIS_URL）
+	RedisModeSentinel   = "sentinel"   // 哨兵（配置了 REDIS_SENTINEL_MASTER）
+	RedisModeCluster    = "cluster"    // 集群（配置了 REDIS_CLUSTER_ADDRS）
+)
+
+// 存储后端类型
+const (
+	StorageBackendRedis  = "redis"  // Redis 存储（生产环境）
+	StorageBackendMemory = "memory" // 内存存储（开发/CI，无需 Redis，重启丢失数据）
+	StorageBackendFile   = "file"   // 文件存储（小型部署，无需 Redis，重启保留数据）
+)
+
+// 上游请求模式
+const (
+	UpstreamModeLive   = "live"   // 直接请求上游（默认）
+	UpstreamModeRecord = "record" // 请求上游并把成功的响应录制到 UPSTREAM_FIXTURES_DIR
+	UpstreamModeReplay = "replay" // 只从 UPSTREAM_FIXTURES_DIR 回放，不访问网络
 )
 
 // Config 应用程序配置（只保留必须从环境变量读取的配置）
 type Config struct {
-	RedisAddr          string // Redis地址（必须配置）
-	RedisPassword      string // Redis密码（必须配置）
-	RedisDB            int    // Redis数据库编号（可选，默认0）
-	IYYUSign           string // IYUU签名（可选，用于调用站点API）
-	InsecureSkipVerify bool   // 跳过TLS证书验证（可选，仅用于证书过期等异常情况）
+	StorageBackend        string        // 存储后端（可选，redis/memory/file，默认redis）
+	DataDir               string        // 数据目录（可选，file后端使用，默认./data）
+	RedisAddr             string        // Redis地址（redis后端必须配置）
+	RedisPassword         string        // Redis密码（redis后端必须配置）
+	RedisDB               int           // Redis数据库编号（可选，默认0）
+	RedisKeyPrefix        string        // Redis key命名空间（可选，默认top1000）
+	RedisURL              string        // Redis连接URL（可选，redis:// 或 rediss://，优先于 REDIS_ADDR/REDIS_PASSWORD/REDIS_DB）
+	RedisUsername         string        // Redis ACL用户名（可选）
+	RedisSentinelMaster   string        // 哨兵模式的主节点名称（可选）
+	RedisSentinelAddrs    []string      // 哨兵节点地址列表（哨兵模式必须配置）
+	RedisSentinelPassword string        // 哨兵节点密码（可选）
+	RedisClusterAddrs     []string      // 集群种子节点地址列表（可选，配置后启用集群模式）
+	RedisTLS              bool          // 启用TLS连接（可选，rediss:// 自动启用）
+	RedisTLSCAFile        string        // 自定义CA证书文件（可选）
+	RedisTLSCertFile      string        // 客户端证书文件（可选，需与 REDIS_TLS_KEY_FILE 同时配置）
+	RedisTLSKeyFile       string        // 客户端私钥文件（可选）
+	RedisPoolSize         int           // 连接池大小（可选，默认3）
+	RedisMinIdleConns     int           // 最少空闲连接数（可选，默认1）
+	RedisPoolTimeout      time.Duration // 等待空闲连接的超时时间（可选）
+	RedisConnMaxIdleTime  time.Duration // 空闲连接最长保留时间（可选，默认30m）
+	RedisCompression      string        // 数据压缩算法（可选，none/gzip/zstd，默认gzip）
+	HistoryMaxCount       int           // 最多保留的历史快照数量（可选，默认30，0表示不限制）
+	HistoryMaxAge         time.Duration // 历史快照最长保留时间（可选，如720h，默认不限制）
+	RawMaxCount           int           // 最多保留的上游原始内容数量（可选，默认30，0表示不限制）
+	RawMaxAge             time.Duration // 上游原始内容最长保留时间（可选，默认720h，0表示不限制）
+	AdminToken            string        // 管理接口令牌（可选，为空时不开放 /api/admin/*）
+	SiteRulesFile         string        // 站点链接规则文件（可选，JSON，按站点覆盖内置规则）
+	APITokens             []string      // 用户API令牌（可选，格式 用户名:令牌，用于保存站点凭据和个性化下载链接）
+	SecretKey             string        // 站点凭据加密密钥（设置 API_TOKENS 时必需，base64 编码的32字节）
+	LockTTL               time.Duration // 刷新租约有效期（可选，默认30s）
+	RefreshInterval       time.Duration // 后台刷新间隔（可选，默认15m）
+	RefreshCron           string        // 后台刷新cron表达式（可选，优先于 REFRESH_INTERVAL）
+	RefreshJitter         time.Duration // 每次刷新的最大随机延迟（可选，默认30s，0表示不抖动）
+	SourceURLs            []string      // Top1000数据源（可选，按顺序故障转移，默认为IYUU官方接口）
+	UpstreamTimezone      string        // 上游数据时间所在时区（可选，默认Asia/Shanghai）
+	ParserStrict          bool          // 严格解析：文本中有任何异常行时拒绝整份数据（可选，默认false）
+	UpstreamProxy         string        // 出站代理（可选，http/https/socks5/socks5h，默认读取HTTP_PROXY等环境变量）
+	UpstreamCAFile        string        // 出站请求额外信任的CA证书文件（可选，追加到系统证书）
+	UpstreamUserAgent     string        // 出站请求的User-Agent（可选，默认top1000/1.0）
+	UpstreamMode          string        // 上游请求模式（可选，live/record/replay，默认live）
+	FixturesDir           string        // 录制/回放上游响应的目录（可选，默认./fixtures）
+	RetryAttempts         int           // 上游请求最多尝试次数（可选，默认3）
+	RetryBaseDelay        time.Duration // 首次重试等待时间（可选，默认1s，指数增长）
+	RetryMaxDelay         time.Duration // 单次重试等待上限（可选，默认30s）
+	RetryJitter           float64       // 重试等待随机浮动比例（可选，默认0.2）
+	BreakerThreshold      int           // 熔断阈值：连续失败次数（可选，默认5，0表示不熔断）
+	BreakerCooldown       time.Duration // 熔断冷却时间（可选，默认1m）
+	GuardMinItems         int           // 异常检测：最少条目数（可选，默认100，0表示不检查）
+	GuardMaxDropPercent   float64       // 异常检测：条目数最多减少的百分比（可选，默认50，0表示不检查）
+	GuardMaxSkippedRatio  float64       // 异常检测：最多跳过的数据比例（可选，默认0.2，0表示不检查）
+	GuardRejectTimeRewind bool          // 异常检测：拒绝时间早于当前数据的数据（可选，默认true）
+	IYYUSign              string        // IYUU签名（可选，用于调用站点API）
+	InsecureSkipVerify    bool          // 跳过TLS证书验证（可选，仅用于证书过期等异常情况）
+
+	upstreamLocation *time.Location // UpstreamTimezone 解析结果（Load 时缓存）
 }
 
 var (
@@ -59,19 +173,125 @@ var (
 func Load() *Config {
 	initOnce.Do(func() {
 		cfg := &Config{
-			RedisAddr:          getEnv("REDIS_ADDR", ""),
-			RedisPassword:      getEnv("REDIS_PASSWORD", ""),
+			StorageBackend: strings.ToLower(getEnv("STORAGE_BACKEND", DefaultStorageBackend)),
+			DataDir:        getEnv("DATA_DIR", DefaultDataDir),
+			RedisAddr:      getEnv("REDIS_ADDR", ""),
+			RedisPassword:  getEnv("REDIS_PASSWORD", ""),
 			// Go 1.26 泛型优化：使用统一的 getEnvGeneric
-			RedisDB:            getEnvGeneric("REDIS_DB", DefaultRedisDB, func(s string) (int, bool) {
+			RedisDB: getEnvGeneric("REDIS_DB", DefaultRedisDB, func(s string) (int, bool) {
 				i, err := strconv.Atoi(s)
 				return i, err == nil
 			}),
-			IYYUSign: getEnv("IYUU_SIGN", ""),
-			InsecureSkipVerify: getEnvGeneric("INSECURE_SKIP_VERIFY", false, func(s string) (bool, bool) {
-				// 支持 true/false, 1/0, yes/no
-				return s == "true" || s == "1" || s == "yes", true
+			RedisKeyPrefix:        strings.TrimRight(getEnv("REDIS_KEY_PREFIX", DefaultRedisKeyPrefix), ":"),
+			RedisURL:              getEnv("REDIS_URL", ""),
+			RedisUsername:         getEnv("REDIS_USERNAME", ""),
+			RedisSentinelMaster:   getEnv("REDIS_SENTINEL_MASTER", ""),
+			RedisSentinelAddrs:    getEnvGeneric("REDIS_SENTINEL_ADDRS", []string(nil), parseList),
+			RedisSentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+			RedisClusterAddrs:     getEnvGeneric("REDIS_CLUSTER_ADDRS", []string(nil), parseList),
+			RedisTLS:              getEnvGeneric("REDIS_TLS", false, parseBool),
+			RedisTLSCAFile:        getEnv("REDIS_TLS_CA_FILE", ""),
+			RedisTLSCertFile:      getEnv("REDIS_TLS_CERT_FILE", ""),
+			RedisTLSKeyFile:       getEnv("REDIS_TLS_KEY_FILE", ""),
+			RedisPoolSize: getEnvGeneric("REDIS_POOL_SIZE", DefaultRedisPoolSize, func(s string) (int, bool) {
+				i, err := strconv.Atoi(s)
+				return i, err == nil && i > 0
+			}),
+			RedisMinIdleConns: getEnvGeneric("REDIS_MIN_IDLE_CONNS", DefaultRedisMinIdleConns, func(s string) (int, bool) {
+				i, err := strconv.Atoi(s)
+				return i, err == nil && i >= 0
+			}),
+			RedisPoolTimeout: getEnvGeneric("REDIS_POOL_TIMEOUT", time.Duration(DefaultRedisPoolTimeout), func(s string) (time.Duration, bool) {
+				d, err := time.ParseDuration(s)
+				return d, err == nil && d >= 0
+			}),
+			RedisConnMaxIdleTime: getEnvGeneric("REDIS_CONN_MAX_IDLE_TIME", DefaultRedisConnMaxIdle, func(s string) (time.Duration, bool) {
+				d, err := time.ParseDuration(s)
+				return d, err == nil && d >= 0
+			}),
+			RedisCompression: strings.ToLower(getEnv("REDIS_COMPRESSION", DefaultRedisCompression)),
+			HistoryMaxCount: getEnvGeneric("HISTORY_MAX_COUNT", DefaultHistoryMaxCount, func(s string) (int, bool) {
+				i, err := strconv.Atoi(s)
+				return i, err == nil && i >= 0
+			}),
+			HistoryMaxAge: getEnvGeneric("HISTORY_MAX_AGE", time.Duration(DefaultHistoryMaxAge), func(s string) (time.Duration, bool) {
+				d, err := time.ParseDuration(s)
+				return d, err == nil && d >= 0
+			}),
+			RawMaxCount: getEnvGeneric("RAW_MAX_COUNT", DefaultRawMaxCount, func(s string) (int, bool) {
+				i, err := strconv.Atoi(s)
+				return i, err == nil && i >= 0
+			}),
+			RawMaxAge: getEnvGeneric("RAW_MAX_AGE", DefaultRawMaxAge, func(s string) (time.Duration, bool) {
+				d, err := time.ParseDuration(s)
+				return d, err == nil && d >= 0
+			}),
+			AdminToken:    getEnv("ADMIN_TOKEN", ""),
+			SiteRulesFile: getEnv("SITE_RULES_FILE", ""),
+			APITokens:     getEnvGeneric("API_TOKENS", []string(nil), parseList),
+			SecretKey:     getEnv("SECRET_KEY", ""),
+			LockTTL: getEnvGeneric("LOCK_TTL", DefaultLockTTL, func(s string) (time.Duration, bool) {
+				d, err := time.ParseDuration(s)
+				return d, err == nil && d >= time.Second
+			}),
+			RefreshInterval: getEnvGeneric("REFRESH_INTERVAL", DefaultRefreshInterval, func(s string) (time.Duration, bool) {
+				d, err := time.ParseDuration(s)
+				return d, err == nil && d >= time.Second
+			}),
+			RefreshCron: getEnv("REFRESH_CRON", ""),
+			RefreshJitter: getEnvGeneric("REFRESH_JITTER", DefaultRefreshJitter, func(s string) (time.Duration, bool) {
+				d, err := time.ParseDuration(s)
+				return d, err == nil && d >= 0
+			}),
+			SourceURLs:        getEnvGeneric("SOURCE_URLS", []string{DefaultAPIURL}, parseList),
+			UpstreamTimezone:  getEnv("UPSTREAM_TIMEZONE", DefaultUpstreamTimezone),
+			ParserStrict:      getEnvGeneric("PARSER_STRICT", false, parseBool),
+			UpstreamProxy:     getEnv("UPSTREAM_PROXY", ""),
+			UpstreamCAFile:    getEnv("UPSTREAM_CA_FILE", ""),
+			UpstreamUserAgent: getEnv("UPSTREAM_USER_AGENT", DefaultUpstreamUserAgent),
+			UpstreamMode:      getEnv("UPSTREAM_MODE", DefaultUpstreamMode),
+			FixturesDir:       getEnv("UPSTREAM_FIXTURES_DIR", DefaultFixturesDir),
+			RetryAttempts: getEnvGeneric("UPSTREAM_RETRY_ATTEMPTS", DefaultRetryAttempts, func(s string) (int, bool) {
+				i, err := strconv.Atoi(s)
+				return i, err == nil && i >= 1
+			}),
+			RetryBaseDelay: getEnvGeneric("UPSTREAM_RETRY_BASE_DELAY", DefaultRetryBaseDelay, func(s string) (time.Duration, bool) {
+				d, err := time.ParseDuration(s)
+				return d, err == nil && d >= 0
 			}),
+			RetryMaxDelay: getEnvGeneric("UPSTREAM_RETRY_MAX_DELAY", DefaultRetryMaxDelay, func(s string) (time.Duration, bool) {
+				d, err := time.ParseDuration(s)
+				return d, err == nil && d >= 0
+			}),
+			RetryJitter: getEnvGeneric("UPSTREAM_RETRY_JITTER", DefaultRetryJitter, func(s string) (float64, bool) {
+				f, err := strconv.ParseFloat(s, 64)
+				return f, err == nil && f >= 0 && f <= 1
+			}),
+			BreakerThreshold: getEnvGeneric("UPSTREAM_BREAKER_THRESHOLD", DefaultBreakerThreshold, func(s string) (int, bool) {
+				i, err := strconv.Atoi(s)
+				return i, err == nil && i >= 0
+			}),
+			BreakerCooldown: getEnvGeneric("UPSTREAM_BREAKER_COOLDOWN", DefaultBreakerCooldown, func(s string) (time.Duration, bool) {
+				d, err := time.ParseDuration(s)
+				return d, err == nil && d >= time.Second
+			}),
+			GuardMinItems: getEnvGeneric("GUARD_MIN_ITEMS", DefaultGuardMinItems, func(s string) (int, bool) {
+				i, err := strconv.Atoi(s)
+				return i, err == nil && i >= 0
+			}),
+			GuardMaxDropPercent: getEnvGeneric("GUARD_MAX_DROP_PERCENT", DefaultGuardMaxDropPercent, func(s string) (float64, bool) {
+				f, err := strconv.ParseFloat(s, 64)
+				return f, err == nil && f >= 0 && f <= 100
+			}),
+			GuardMaxSkippedRatio: getEnvGeneric("GUARD_MAX_SKIPPED_RATIO", DefaultGuardMaxSkippedRatio, func(s string) (float64, bool) {
+				f, err := strconv.ParseFloat(s, 64)
+				return f, err == nil && f >= 0 && f <= 1
+			}),
+			GuardRejectTimeRewind: getEnvGeneric("GUARD_REJECT_TIME_REWIND", true, parseBool),
+			IYYUSign:              getEnv("IYUU_SIGN", ""),
+			InsecureSkipVerify:    getEnvGeneric("INSECURE_SKIP_VERIFY", false, parseBool),
 		}
+		cfg.upstreamLocation, _ = time.LoadLocation(cfg.UpstreamTimezone)
 		appConfig.Store(cfg)
 	})
 	return appConfig.Load().(*Config)
@@ -102,11 +322,63 @@ func Validate() error {
 	cfg := Get()
 	var errs ValidationError
 
-	if cfg.RedisAddr == "" {
-		errs.Add("REDIS_ADDR")
+	switch cfg.StorageBackend {
+	case StorageBackendRedis, "":
+		validateRedis(cfg, &errs)
+	case StorageBackendMemory:
+		// 内存后端无需额外配置
+	case StorageBackendFile:
+		if cfg.DataDir == "" {
+			errs.Add("DATA_DIR")
+		}
+	default:
+		errs.Add("STORAGE_BACKEND")
+	}
+
+	if cfg.RefreshCron != "" {
+		if _, err := cron.ParseStandard(cfg.RefreshCron); err != nil {
+			errs.Add("REFRESH_CRON")
+		}
+	}
+
+	if cfg.UpstreamTimezone != "" {
+		if _, err := time.LoadLocation(cfg.UpstreamTimezone); err != nil {
+			errs.Add("UPSTREAM_TIMEZONE")
+		}
+	}
+
+	if cfg.UpstreamProxy != "" && !validProxyURL(cfg.UpstreamProxy) {
+		errs.Add("UPSTREAM_PROXY")
+	}
+
+	if cfg.UpstreamCAFile != "" && !validCAFile(cfg.UpstreamCAFile) {
+		errs.Add("UPSTREAM_CA_FILE")
+	}
+
+	if cfg.SiteRulesFile != "" && !validJSONFile(cfg.SiteRulesFile) {
+		errs.Add("SITE_RULES_FILE")
 	}
-	if cfg.RedisPassword == "" {
-		errs.Add("REDIS_PASSWORD")
+
+	validateAPITokens(cfg, &errs)
+
+	switch cfg.UpstreamMode {
+	case UpstreamModeLive, UpstreamModeRecord, UpstreamModeReplay, "":
+	default:
+		errs.Add("UPSTREAM_MODE")
+	}
+	if (cfg.UpstreamMode == UpstreamModeRecord || cfg.UpstreamMode == UpstreamModeReplay) && cfg.FixturesDir == "" {
+		errs.Add("UPSTREAM_FIXTURES_DIR")
+	}
+
+	if cfg.RetryBaseDelay > cfg.RetryMaxDelay {
+		errs.Add("UPSTREAM_RETRY_MAX_DELAY")
+	}
+
+	for _, rawURL := range cfg.SourceURLs {
+		if !validSourceURL(rawURL) {
+			errs.Add("SOURCE_URLS")
+			break
+		}
 	}
 
 	if !errs.IsValid() {
@@ -115,6 +387,170 @@ func Validate() error {
 	return nil
 }
 
+// validSourceURL 检查数据源地址（http/https 需要主机，file 需要路径）
+func validSourceURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return u.Host != ""
+	case "file":
+		return u.Host+u.Path != ""
+	default:
+		return false
+	}
+}
+
+// validProxyURL 检查出站代理地址（http/https/socks5/socks5h，需要主机）
+func validProxyURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return true
+	default:
+		return false
+	}
+}
+
+// validCAFile 检查CA证书文件可读且至少包含一个证书
+func validCAFile(path string) bool {
+	pem, err := os.ReadFile(path)
+	return err == nil && x509.NewCertPool().AppendCertsFromPEM(pem)
+}
+
+// validJSONFile 检查文件可读且是合法的 JSON（内容由使用方进一步校验）
+func validJSONFile(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && json.Valid(data)
+}
+
+// validateAPITokens 验证用户令牌和凭据加密密钥
+// 令牌格式为 用户名:令牌，用户名和令牌都不能重复；启用令牌时必须配置有效的 SECRET_KEY
+func validateAPITokens(cfg *Config, errs *ValidationError) {
+	users := make(map[string]bool, len(cfg.APITokens))
+	tokens := make(map[string]bool, len(cfg.APITokens))
+	for _, entry := range cfg.APITokens {
+		user, token, ok := strings.Cut(entry, ":")
+		if !ok || user == "" || len(token) < MinAPITokenLength || users[user] || tokens[token] {
+			errs.Add("API_TOKENS")
+			break
+		}
+		users[user], tokens[token] = true, true
+	}
+
+	if (len(cfg.APITokens) > 0 || cfg.SecretKey != "") && cfg.SecretKeyBytes() == nil {
+		errs.Add("SECRET_KEY")
+	}
+}
+
+// validateRedis 验证 Redis 连接配置
+func validateRedis(cfg *Config, errs *ValidationError) {
+	if cfg.RedisSentinelMaster != "" && len(cfg.RedisClusterAddrs) > 0 {
+		// 哨兵与集群模式只能二选一
+		errs.Add("REDIS_CLUSTER_ADDRS")
+	}
+
+	if cfg.RedisURL != "" {
+		// 地址和密码由 URL 提供
+		if u, err := url.Parse(cfg.RedisURL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") {
+			errs.Add("REDIS_URL")
+		}
+	} else {
+		if cfg.RedisAddr == "" && cfg.RedisMode() == RedisModeStandalone {
+			errs.Add("REDIS_ADDR")
+		}
+		if cfg.RedisPassword == "" {
+			errs.Add("REDIS_PASSWORD")
+		}
+	}
+
+	switch cfg.RedisMode() {
+	case RedisModeSentinel:
+		if len(cfg.RedisSentinelAddrs) == 0 {
+			errs.Add("REDIS_SENTINEL_ADDRS")
+		}
+	case RedisModeCluster:
+		// 集群不支持 SELECT，只能使用 0 号库
+		if cfg.RedisDB != 0 {
+			errs.Add("REDIS_DB")
+		}
+	}
+
+	if strings.ContainsAny(cfg.RedisKeyPrefix, " \t\r\n") {
+		errs.Add("REDIS_KEY_PREFIX")
+	}
+
+	switch cfg.RedisCompression {
+	case CompressionNone, CompressionGzip, CompressionZstd, "":
+	default:
+		errs.Add("REDIS_COMPRESSION")
+	}
+
+	// 客户端证书和私钥必须成对配置
+	if cfg.RedisTLSCertFile != "" && cfg.RedisTLSKeyFile == "" {
+		errs.Add("REDIS_TLS_KEY_FILE")
+	}
+	if cfg.RedisTLSKeyFile != "" && cfg.RedisTLSCertFile == "" {
+		errs.Add("REDIS_TLS_CERT_FILE")
+	}
+}
+
+// RedisMode 根据配置推断 Redis 部署模式
+func (c *Config) RedisMode() string {
+	switch {
+	case c.RedisSentinelMaster != "":
+		return RedisModeSentinel
+	case len(c.RedisClusterAddrs) > 0:
+		return RedisModeCluster
+	default:
+		return RedisModeStandalone
+	}
+}
+
+// UpstreamLocation 返回上游数据时间所在时区
+// 与主机 TZ 无关；未配置或无效时使用 Asia/Shanghai
+func (c *Config) UpstreamLocation() *time.Location {
+	if c.upstreamLocation != nil {
+		return c.upstreamLocation
+	}
+
+	name := c.UpstreamTimezone
+	if name == "" {
+		name = DefaultUpstreamTimezone
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	return time.FixedZone("CST", 8*60*60)
+}
+
+// APITokenUsers 返回令牌到用户名的映射（忽略格式错误的项，Validate 会拒绝这些配置）
+func (c *Config) APITokenUsers() map[string]string {
+	users := make(map[string]string, len(c.APITokens))
+	for _, entry := range c.APITokens {
+		if user, token, ok := strings.Cut(entry, ":"); ok && user != "" && token != "" {
+			users[token] = user
+		}
+	}
+	return users
+}
+
+// SecretKeyBytes 解码 SECRET_KEY（未配置或不是 base64 编码的32字节时返回 nil）
+func (c *Config) SecretKeyBytes() []byte {
+	key, err := base64.StdEncoding.DecodeString(c.SecretKey)
+	if err != nil || len(key) != SecretKeyLength {
+		return nil
+	}
+	return key
+}
+
 // Get 获取配置实例（并发安全）
 func Get() *Config {
 	return Load()
@@ -127,3 +563,19 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// parseBool 解析布尔环境变量（支持 true/false, 1/0, yes/no）
+func parseBool(s string) (bool, bool) {
+	return s == "true" || s == "1" || s == "yes", true
+}
+
+// parseList 解析逗号分隔的列表（忽略空项）
+func parseList(s string) ([]string, bool) {
+	var items []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items, len(items) > 0
+}
@@ -0,0 +1,242 @@
+package crawler
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"top1000/internal/model"
+)
+
+// IYUU 文本格式：
+//
+//	create time 2026-01-19 07:50:56 by xxx
+//
+//	站名：测试站点 【ID：123】
+//	重复度：85.5%
+//	文件大小：1.2TB
+//
+// 每条数据以"站名"行开始，解析时在每个"站名"行重新同步，不依赖固定的行数：
+// 一行缺失或多出只影响所在的那一组。半角冒号、多余空白和空行都可以容忍（计入 IrregularLines），
+// 无法识别的行和无法解析的组记录到解析报告中；PARSER_STRICT 开启时任何异常都会拒绝整份数据
+
+const (
+	timePrefix       = "create time "
+	timeSuffix       = " by "
+	siteMarker       = "站名"
+	fieldDuplication = "重复度"
+	fieldSize        = "大小"
+	fieldSeparators  = ":："
+)
+
+var (
+	// siteRegex 站名行（容忍半角冒号和多余空白）
+	siteRegex = regexp.MustCompile(`^站名\s*[:：]\s*(.*?)\s*【\s*ID\s*[:：]\s*(\d+)\s*】`)
+	// canonicalSiteRegex 标准格式的站名行
+	canonicalSiteRegex = regexp.MustCompile(`^站名：\S.*? 【ID：\d+】$`)
+)
+
+// parseResponse 解析原始文本为结构化数据，同时返回解析报告
+func parseResponse(rawData string) (model.ProcessedData, *model.ParseReport) {
+	lines := strings.Split(normalizeLineEndings(rawData), "\n")
+
+	// 头部到第一个空行或第一个"站名"行为止（找不到"站名"行时，其余内容计为无法识别的行）
+	start := len(lines)
+	for i, line := range lines {
+		if isSiteLine(line) {
+			start = i
+			break
+		}
+		if i > 0 && strings.TrimSpace(line) == "" {
+			start = i + 1
+			break
+		}
+	}
+
+	report := &model.ParseReport{
+		Format:     model.ReportFormatText,
+		ParsedAt:   time.Now(),
+		Header:     lines[:start],
+		Time:       extractTime(headerTimeLine(lines[:start])),
+		TotalLines: len(lines),
+	}
+	items := parseDataLines(lines[start:], start, report)
+	report.LinesConsumed += start
+
+	logParsingWarnings(report)
+	log.Printf("[%s] 数据解析完成（%d 条）", logPrefix, len(items))
+
+	return model.ProcessedData{
+		Time:  report.Time,
+		Items: items,
+	}, report
+}
+
+// normalizeLineEndings 统一换行符为\n
+func normalizeLineEndings(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+// headerTimeLine 找出头部中的时间行（没有 create time 前缀时取第一个非空行）
+func headerTimeLine(header []string) string {
+	first := ""
+	for _, line := range header {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, timePrefix) {
+			return line
+		}
+		if first == "" {
+			first = line
+		}
+	}
+	return first
+}
+
+// isSiteLine 是否为一条数据的起始行
+func isSiteLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), siteMarker)
+}
+
+// isCanonicalLine 是否为标准格式的数据行（全角冒号，首尾没有空白）
+func isCanonicalLine(line string) bool {
+	if line != strings.TrimSpace(line) {
+		return false
+	}
+	if isSiteLine(line) {
+		return canonicalSiteRegex.MatchString(line)
+	}
+	return strings.Contains(line, "：") && !strings.Contains(line, ":")
+}
+
+// parseDataLines 按"站名"行分组解析数据行，offset 为第一行在原文中的下标
+// 跳过的组、无法识别的行和不规范的行记录到报告中
+func parseDataLines(dataLines []string, offset int, report *model.ParseReport) []model.SiteItem {
+	var items []model.SiteItem
+
+	// 末尾的空行（响应以换行结尾）不算不规范
+	last := len(dataLines) - 1
+	for last >= 0 && strings.TrimSpace(dataLines[last]) == "" {
+		last--
+	}
+
+	var group []model.ReportLine
+	flush := func() {
+		if len(group) == 0 {
+			return
+		}
+		report.Groups++
+
+		item, unknown, reason := parseItemGroup(group)
+		if reason != "" {
+			texts := make([]string, len(group))
+			for i, line := range group {
+				texts[i] = line.Text
+			}
+			report.AddSkipped(model.SkippedGroup{Line: group[0].Line, Reason: reason, Lines: texts})
+			group = nil
+			return
+		}
+
+		for _, line := range unknown {
+			report.AddLeftover(line)
+		}
+		report.LinesConsumed += len(group) - len(unknown)
+		item.ID = len(items) + 1
+		items = append(items, item)
+		group = nil
+	}
+
+	for i, text := range dataLines[:last+1] {
+		if strings.TrimSpace(text) == "" {
+			report.IrregularLines++
+			continue
+		}
+		if !isCanonicalLine(text) {
+			report.IrregularLines++
+		}
+
+		line := model.ReportLine{Line: offset + i + 1, Text: text}
+		switch {
+		case isSiteLine(text):
+			flush()
+			group = []model.ReportLine{line}
+		case group == nil:
+			report.AddLeftover(line)
+		default:
+			group = append(group, line)
+		}
+	}
+	flush()
+	report.Items = len(items)
+
+	return items
+}
+
+// parseItemGroup 解析一组数据（站名行和其后的字段行）
+// 返回解析出的条目、组内无法识别的行和跳过原因（reason 非空表示整组跳过）
+func parseItemGroup(group []model.ReportLine) (model.SiteItem, []model.ReportLine, string) {
+	match := siteRegex.FindStringSubmatch(strings.TrimSpace(group[0].Text))
+	if match == nil {
+		return model.SiteItem{}, nil, `站名行不符合"站名：... 【ID：...】"格式`
+	}
+	if match[1] == "" {
+		return model.SiteItem{}, nil, "站名为空"
+	}
+
+	item := model.SiteItem{SiteName: match[1], SiteID: match[2]}
+	var unknown []model.ReportLine
+	var hasDuplication, hasSize bool
+	for _, line := range group[1:] {
+		key, value, ok := splitField(line.Text)
+		switch {
+		case ok && strings.Contains(key, fieldDuplication) && !hasDuplication:
+			item.Duplication, hasDuplication = value, true
+		case ok && strings.Contains(key, fieldSize) && !hasSize:
+			item.Size, hasSize = value, true
+		default:
+			unknown = append(unknown, line)
+		}
+	}
+
+	switch {
+	case !hasDuplication:
+		return model.SiteItem{}, nil, "缺少重复度"
+	case !hasSize:
+		return model.SiteItem{}, nil, "缺少文件大小"
+	}
+
+	// 同时保存数值形式（sizeBytes/duplicationValue），方便排序和统计
+	item.ComputeValues()
+	return item, unknown, ""
+}
+
+// splitField 拆分"字段名：值"（全角或半角冒号）
+func splitField(line string) (string, string, bool) {
+	idx := strings.IndexAny(line, fieldSeparators)
+	if idx < 0 {
+		return "", "", false
+	}
+	_, size := utf8.DecodeRuneInString(line[idx:])
+	return strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+size:]), true
+}
+
+// extractTime 提取时间字符串，去除前缀和后缀
+func extractTime(rawTime string) string {
+	rawTime = strings.TrimPrefix(strings.TrimSpace(rawTime), timePrefix)
+	if idx := strings.Index(rawTime, timeSuffix); idx != -1 {
+		rawTime = rawTime[:idx]
+	}
+	return strings.TrimSpace(rawTime)
+}
+
+// logParsingWarnings 记录解析警告（详情见 GET /api/crawl/last）
+func logParsingWarnings(report *model.ParseReport) {
+	if report.LeftoverLines != 0 {
+		log.Printf("[%s] 警告：%d 行无法识别", logPrefix, report.LeftoverLines)
+	}
+	if report.SkippedGroups > 0 {
+		log.Printf("[%s] 警告：跳过 %d 条格式错误的数据", logPrefix, report.SkippedGroups)
+	}
+}
@@ -0,0 +1,84 @@
+package crawler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// parserSeeds 模糊测试的初始语料（标准格式和各种变形）
+var parserSeeds = []string{
+	testRawData,
+	"",
+	"\n\n\n",
+	"create time 2026-01-19 07:50:56 by xxx",
+	"create time 2026-01-19 07:50:56 by xxx\r\n\r\n站名：站点1 【ID：1】\r\n重复度：80\r\n文件大小：1TB\r\n",
+	"create time 2026-01-19 07:50:56 by xxx\n\n站名:站点1【ID:1】\n重复度:80\n\n\n文件大小:1TB\n",
+	"create time 2026-01-19 07:50:56 by xxx\n\n站名：\n站名：【ID：】\n站名：站点 【ID：99999999999999999999】\n重复度：\n文件大小：",
+	"站名：站点1 【ID：1】\n站名：站点2 【ID：2】\n重复度：1\n重复度：2\n文件大小：3\n文件大小：4",
+	"维护中\n<html><body>502 Bad Gateway</body></html>",
+	"站名",
+	"：：：:::\n站名：：：【ID：：】\n大小重复度：\xff\xfe",
+}
+
+func FuzzParseResponse(f *testing.F) {
+	for _, seed := range parserSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, rawData string) {
+		processed, report := parseResponse(rawData)
+
+		if report.Items != len(processed.Items) || report.Items+report.SkippedGroups != report.Groups {
+			t.Fatalf("Items/SkippedGroups/Groups = %d/%d/%d，与 %d 条数据不一致",
+				report.Items, report.SkippedGroups, report.Groups, len(processed.Items))
+		}
+		if report.LinesConsumed > report.TotalLines {
+			t.Fatalf("LinesConsumed %d 超过 TotalLines %d", report.LinesConsumed, report.TotalLines)
+		}
+		for i, item := range processed.Items {
+			if item.ID != i+1 || strings.TrimSpace(item.SiteName) == "" || item.SiteID == "" {
+				t.Fatalf("第 %d 条数据无效: %+v", i+1, item)
+			}
+		}
+		for _, skipped := range report.Skipped {
+			if skipped.Line < 1 || skipped.Line > report.TotalLines || skipped.Reason == "" {
+				t.Fatalf("跳过记录无效: %+v", skipped)
+			}
+		}
+
+		// 解析失败只返回错误，不会 panic
+		_, _ = parseBody("fuzz", []byte(rawData))
+	})
+}
+
+func TestParseBodyStrict(t *testing.T) {
+	original := parserStrict
+	parserStrict = func() bool { return true }
+	t.Cleanup(func() { parserStrict = original })
+
+	tests := []struct {
+		name    string
+		rawData string
+		wantErr bool
+	}{
+		{name: "标准格式", rawData: testRawData},
+		{name: "半角冒号", rawData: strings.Replace(testRawData, "重复度：", "重复度:", 1), wantErr: true},
+		{name: "多余空行", rawData: strings.Replace(testRawData, "\n重复度", "\n\n重复度", 1), wantErr: true},
+		{name: "无法识别的行", rawData: strings.Replace(testRawData, "\n重复度", "\n做种人数：5\n重复度", 1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseBody("test", []byte(tt.rawData))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var parseErr *ParseError
+			if tt.wantErr && (!errors.As(err, &parseErr) || parseErr.Report.Anomalies() == 0) {
+				t.Errorf("parseBody() error = %v，期望带解析报告的 *ParseError", err)
+			}
+		})
+	}
+}
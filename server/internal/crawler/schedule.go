@@ -0,0 +1,160 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	scheduleLogPrefix = "调度"
+	// taskTimeout 单个刷新任务的最长执行时间
+	taskTimeout = 2 * time.Minute
+)
+
+// Schedule 刷新计划（返回 t 之后的下一次执行时间，与 cron.Schedule 相同）
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// intervalSchedule 固定间隔计划
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Next 实现 Schedule 接口
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// ParseSchedule 解析刷新计划
+// cronExpr 不为空时优先使用（标准 5 段格式，也支持 @hourly、@every 30m 等描述符），
+// 否则按 interval 固定间隔执行
+func ParseSchedule(interval time.Duration, cronExpr string) (Schedule, error) {
+	if cronExpr != "" {
+		schedule, err := cron.ParseStandard(cronExpr)
+		if err != nil {
+			return nil, fmt.Errorf("解析cron表达式失败: %w", err)
+		}
+		return schedule, nil
+	}
+
+	if interval <= 0 {
+		return nil, fmt.Errorf("刷新间隔必须大于0: %v", interval)
+	}
+	return intervalSchedule{interval: interval}, nil
+}
+
+// Task 定时刷新任务
+type Task struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Scheduler 后台刷新调度器
+// 启动后立即执行一轮任务，之后按计划（加上随机抖动）重复执行；
+// 多个实例使用相同计划时，抖动可以错开对上游的请求
+type Scheduler struct {
+	schedule Schedule
+	jitter   time.Duration
+	tasks    []Task
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewScheduler 创建调度器（需调用 Start 启动）
+func NewScheduler(schedule Schedule, jitter time.Duration, tasks ...Task) *Scheduler {
+	return &Scheduler{
+		schedule: schedule,
+		jitter:   jitter,
+		tasks:    tasks,
+	}
+}
+
+// Start 启动调度协程（重复调用无效）
+// ctx 取消或调用 Stop 后停止
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done)
+}
+
+// Stop 停止调度并等待正在执行的任务结束（ctx 超时后不再等待）
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		log.Printf("[%s] 调度器已停止", scheduleLogPrefix)
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待刷新任务结束超时: %w", ctx.Err())
+	}
+}
+
+// run 调度循环
+func (s *Scheduler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	s.runTasks(ctx)
+	for {
+		next := s.schedule.Next(time.Now()).Add(s.randomJitter())
+		log.Printf("[%s] 下次刷新时间: %s", scheduleLogPrefix, next.Format(time.DateTime))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runTasks(ctx)
+	}
+}
+
+// runTasks 依次执行所有任务（单个任务失败不影响其他任务）
+func (s *Scheduler) runTasks(ctx context.Context) {
+	for _, task := range s.tasks {
+		if ctx.Err() != nil {
+			return
+		}
+
+		taskCtx, cancel := context.WithTimeout(ctx, taskTimeout)
+		err := task.Run(taskCtx)
+		cancel()
+
+		if err != nil {
+			log.Printf("[%s] 任务 %s 执行失败: %v", scheduleLogPrefix, task.Name, err)
+		}
+	}
+}
+
+// randomJitter 返回 [0, jitter) 内的随机延迟
+func (s *Scheduler) randomJitter() time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+	return rand.N(s.jitter)
+}
@@ -0,0 +1,131 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	base := time.Date(2026, 1, 19, 7, 50, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		cronExpr string
+		want     time.Time
+		wantErr  bool
+	}{
+		{name: "固定间隔", interval: 15 * time.Minute, want: base.Add(15 * time.Minute)},
+		{name: "cron优先", interval: 15 * time.Minute, cronExpr: "0 8 * * *", want: time.Date(2026, 1, 19, 8, 0, 0, 0, time.UTC)},
+		{name: "cron描述符", cronExpr: "@hourly", want: time.Date(2026, 1, 19, 8, 0, 0, 0, time.UTC)},
+		{name: "无效cron", cronExpr: "every day", wantErr: true},
+		{name: "间隔为0", interval: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.interval, tt.cronExpr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := schedule.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("启动时立即执行并按计划重复", func(t *testing.T) {
+		var first, second atomic.Int32
+		scheduler := NewScheduler(intervalSchedule{interval: 10 * time.Millisecond}, 0,
+			Task{Name: "first", Run: func(ctx context.Context) error { first.Add(1); return nil }},
+			// 单个任务失败不影响其他任务
+			Task{Name: "second", Run: func(ctx context.Context) error { second.Add(1); return errors.New("失败") }},
+		)
+		scheduler.Start(context.Background())
+
+		deadline := time.Now().Add(time.Second)
+		for first.Load() < 3 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if err := scheduler.Stop(context.Background()); err != nil {
+			t.Fatalf("Stop() error = %v", err)
+		}
+
+		if first.Load() < 3 || second.Load() < 2 {
+			t.Errorf("执行次数 first=%d second=%d，期望按计划重复执行", first.Load(), second.Load())
+		}
+	})
+
+	t.Run("停止时等待正在执行的任务", func(t *testing.T) {
+		started := make(chan struct{})
+		var finished atomic.Bool
+		scheduler := NewScheduler(intervalSchedule{interval: time.Hour}, 0,
+			Task{Name: "slow", Run: func(ctx context.Context) error {
+				close(started)
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				finished.Store(true)
+				return ctx.Err()
+			}},
+		)
+		scheduler.Start(context.Background())
+		<-started
+
+		if err := scheduler.Stop(context.Background()); err != nil {
+			t.Fatalf("Stop() error = %v", err)
+		}
+		if !finished.Load() {
+			t.Error("Stop() 应等待任务结束后返回")
+		}
+	})
+
+	t.Run("等待超时", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		scheduler := NewScheduler(intervalSchedule{interval: time.Hour}, 0,
+			Task{Name: "stuck", Run: func(ctx context.Context) error {
+				close(started)
+				<-release
+				return nil
+			}},
+		)
+		scheduler.Start(context.Background())
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := scheduler.Stop(ctx); err == nil {
+			t.Error("Stop() 期望返回超时错误")
+		}
+	})
+
+	t.Run("未启动时停止", func(t *testing.T) {
+		scheduler := NewScheduler(intervalSchedule{interval: time.Hour}, 0)
+		if err := scheduler.Stop(context.Background()); err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	})
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	scheduler := NewScheduler(intervalSchedule{interval: time.Hour}, time.Second)
+	for range 100 {
+		if jitter := scheduler.randomJitter(); jitter < 0 || jitter >= time.Second {
+			t.Fatalf("randomJitter() = %v, 超出 [0, 1s)", jitter)
+		}
+	}
+
+	scheduler.jitter = 0
+	if jitter := scheduler.randomJitter(); jitter != 0 {
+		t.Errorf("jitter 为 0 时 randomJitter() = %v, want 0", jitter)
+	}
+}
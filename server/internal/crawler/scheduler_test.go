@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 	"time"
 	"top1000/internal/model"
@@ -52,6 +53,9 @@ func TestParseResponse(t *testing.T) {
 				if items[0].Size != "1.2TB" {
 					t.Errorf("Size = %v, want %v", items[0].Size, "1.2TB")
 				}
+				if items[0].SizeBytes != 1_200_000_000_000 {
+					t.Errorf("SizeBytes = %v, want %v", items[0].SizeBytes, int64(1_200_000_000_000))
+				}
 				return nil
 			},
 		},
@@ -82,6 +86,26 @@ func TestParseResponse(t *testing.T) {
 			wantCount: 1,
 			wantTime:  "2026-01-19 07:50:56",
 		},
+		{
+			name: "空行、半角冒号和多余空白",
+			rawData: "create time 2026-01-19 07:50:56 by xxx\n" +
+				"\n" +
+				"站名：站点1 【ID：1】\n" +
+				"重复度: 80\n" +
+				"\n" +
+				"文件大小 ： 1TB\n" +
+				"  站名:站点2【ID:2】  \n" +
+				"重复度：90\n" +
+				"文件大小：2TB\n",
+			wantCount: 2,
+			wantTime:  "2026-01-19 07:50:56",
+			check: func(items []model.SiteItem) error {
+				if items[0].Size != "1TB" || items[1].SiteName != "站点2" || items[1].SiteID != "2" || items[1].ID != 2 {
+					t.Errorf("Items = %+v", items)
+				}
+				return nil
+			},
+		},
 		{
 			name: "数据行不完整(跳过)",
 			rawData: `create time 2026-01-19 07:50:56 by xxx
@@ -94,14 +118,15 @@ func TestParseResponse(t *testing.T) {
 站名：站点3 【ID：3】
 文件大小：3TB
 `,
-			wantCount: 2,
+			// 缺少字段的组单独跳过，不影响后面的数据
+			wantCount: 1,
 			wantTime:  "2026-01-19 07:50:56",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			processed := parseResponse(tt.rawData)
+			processed, _ := parseResponse(tt.rawData)
 			if processed.Time != tt.wantTime {
 				t.Errorf("parseResponse() Time = %v, want %v", processed.Time, tt.wantTime)
 			}
@@ -117,33 +142,86 @@ func TestParseResponse(t *testing.T) {
 	}
 }
 
-func TestExtractFieldValue(t *testing.T) {
+func TestParseResponseReport(t *testing.T) {
+	rawData := "create time 2026-01-19 07:50:56 by xxx\n" +
+		"\n" +
+		"站名：站点1 【ID：1】\n" +
+		"重复度：80\n" +
+		"文件大小：1TB\n" +
+		"做种人数：5\n" +
+		"站名：站点2\n" +
+		"重复度：90\n" +
+		"文件大小：2TB\n" +
+		"站名：站点3 【ID：3】\n" +
+		"重复度:95\n" +
+		"文件大小：3TB\n"
+
+	processed, report := parseResponse(rawData)
+	if len(processed.Items) != 2 || processed.Items[1].SiteName != "站点3" {
+		t.Fatalf("Items = %+v，期望跳过站点2后重新同步", processed.Items)
+	}
+
+	if report.Format != model.ReportFormatText || report.Time != "2026-01-19 07:50:56" || len(report.Header) != 2 {
+		t.Errorf("Format/Time/Header = %v/%v/%q", report.Format, report.Time, report.Header)
+	}
+	if report.TotalLines != 13 || report.LinesConsumed != 8 {
+		t.Errorf("TotalLines/LinesConsumed = %v/%v, want 13/8", report.TotalLines, report.LinesConsumed)
+	}
+	if report.Groups != 3 || report.Items != 2 || report.SkippedGroups != 1 {
+		t.Errorf("Groups/Items/SkippedGroups = %v/%v/%v, want 3/2/1", report.Groups, report.Items, report.SkippedGroups)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Line != 7 || report.Skipped[0].Reason == "" || len(report.Skipped[0].Lines) != 3 {
+		t.Errorf("Skipped = %+v，期望第 7 行开始的一组", report.Skipped)
+	}
+	want := []model.ReportLine{{Line: 6, Text: "做种人数：5"}}
+	if report.LeftoverLines != 1 || !slices.Equal(report.Leftover, want) {
+		t.Errorf("Leftover = %v (%d 行), want %v", report.Leftover, report.LeftoverLines, want)
+	}
+	if report.IrregularLines != 2 || report.Anomalies() != 4 {
+		t.Errorf("IrregularLines/Anomalies = %v/%v, want 2/4", report.IrregularLines, report.Anomalies())
+	}
+}
+
+func TestSplitField(t *testing.T) {
 	tests := []struct {
-		name  string
-		line  string
-		want  string
+		name      string
+		line      string
+		wantKey   string
+		wantValue string
+		wantOK    bool
 	}{
 		{
-			name: "标准格式",
-			line: "重复度：85.5%",
-			want: "85.5%",
+			name:      "标准格式",
+			line:      "重复度：85.5%",
+			wantKey:   "重复度",
+			wantValue: "85.5%",
+			wantOK:    true,
 		},
 		{
-			name: "带空格",
-			line: "文件大小： 1.2TB",
-			want: "1.2TB",
+			name:      "带空格",
+			line:      "文件大小： 1.2TB",
+			wantKey:   "文件大小",
+			wantValue: "1.2TB",
+			wantOK:    true,
+		},
+		{
+			name:      "半角冒号",
+			line:      "站名: 站点A 【ID:1】",
+			wantKey:   "站名",
+			wantValue: "站点A 【ID:1】",
+			wantOK:    true,
 		},
 		{
 			name: "无冒号",
 			line: "纯文本",
-			want: "",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := extractFieldValue(tt.line); got != tt.want {
-				t.Errorf("extractFieldValue() = %v, want %v", got, tt.want)
+			key, value, ok := splitField(tt.line)
+			if key != tt.wantKey || value != tt.wantValue || ok != tt.wantOK {
+				t.Errorf("splitField() = %q, %q, %v, want %q, %q, %v", key, value, ok, tt.wantKey, tt.wantValue, tt.wantOK)
 			}
 		})
 	}
@@ -221,22 +299,23 @@ func TestTaskMutex(t *testing.T) {
 			w.Write([]byte(`create time 2026-01-19 07:50:56 by xxx
 
 站名：测试站点 【ID：123】
-重复度：85.5%
+重复度：85.5
 文件大小：1.2TB
 `))
 		}))
 		defer server.Close()
 
 		ctx := context.Background()
+		source := NewHTTPSource(server.URL)
 		errChan := make(chan error, 2)
 
 		go func() {
-			_, err := FetchTop1000WithContext(ctx)
+			_, err := FetchFrom(ctx, source)
 			errChan <- err
 		}()
 
 		go func() {
-			_, err := FetchTop1000WithContext(ctx)
+			_, err := FetchFrom(ctx, source)
 			errChan <- err
 		}()
 
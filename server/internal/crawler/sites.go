@@ -0,0 +1,106 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+	"top1000/internal/upstream"
+)
+
+const (
+	sitesLogPrefix   = "站点"
+	sitesHTTPTimeout = 5 * time.Second
+	sitesService     = "App.Api.Sites"
+	sitesAPIVersion  = "2.0.0"
+)
+
+// FetchSitesWithContext 从IYUU获取站点目录
+// 上游返回错误信封（ret 非 200）或站点数据无效时返回错误，避免错误数据被缓存
+func FetchSitesWithContext(ctx context.Context, sign string) (*model.SiteCatalog, error) {
+	return fetchSites(ctx, config.DefaultSitesAPIURL, sign)
+}
+
+// fetchSites 请求指定地址的站点接口
+func fetchSites(ctx context.Context, baseURL, sign string) (*model.SiteCatalog, error) {
+	log.Printf("[%s] 开始获取站点数据...", sitesLogPrefix)
+
+	apiURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析基础URL失败: %w", err)
+	}
+	params := url.Values{}
+	params.Add("service", sitesService)
+	params.Add("sign", sign)
+	params.Add("version", sitesAPIVersion)
+	apiURL.RawQuery = params.Encode()
+
+	// 每次尝试单独计时，失败时按重试策略退避，连续失败后熔断
+	var catalog *model.SiteCatalog
+	err = upstream.Do(ctx, apiURL.Scheme+"://"+apiURL.Host+apiURL.Path, retryPolicy(), func(ctx context.Context) error {
+		catalog, err = requestSites(ctx, apiURL.String())
+		return err
+	})
+	return catalog, err
+}
+
+// requestSites 执行一次站点接口请求
+func requestSites(ctx context.Context, apiURL string) (*model.SiteCatalog, error) {
+	ctx, cancel := context.WithTimeout(ctx, sitesHTTPTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+
+	client := createHTTPClient(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := upstream.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	// 签名错误、数据无效等问题重试无法解决
+	catalog, err := parseSitesResponse(body)
+	if err != nil {
+		return nil, upstream.Permanent(err)
+	}
+	return catalog, nil
+}
+
+// parseSitesResponse 解析并检查IYUU站点接口响应
+func parseSitesResponse(body []byte) (*model.SiteCatalog, error) {
+	var resp model.SitesResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		// 错误响应的 data 可能是空数组，类型不匹配时仍以 ret/msg 为准
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) || resp.Ret == model.SitesRetOK {
+			return nil, fmt.Errorf("解析JSON失败: %w", err)
+		}
+	}
+
+	if err := resp.Check(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[%s] 站点数据获取成功（共 %d 个站点）", sitesLogPrefix, len(resp.Data.Sites))
+	return &resp.Data, nil
+}
@@ -0,0 +1,127 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"top1000/internal/upstream"
+)
+
+// useRetryPolicy 测试期间替换重试策略（不等待）
+func useRetryPolicy(t *testing.T, attempts int) {
+	t.Helper()
+	original := retryPolicy
+	retryPolicy = func() upstream.Policy { return upstream.Policy{Attempts: attempts} }
+	t.Cleanup(func() { retryPolicy = original })
+}
+
+func TestFetchSites(t *testing.T) {
+	useRetryPolicy(t, 3)
+
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   bool
+		wantSites int
+		wantCalls int32
+	}{
+		{
+			name:      "正常响应",
+			status:    http.StatusOK,
+			body:      `{"ret":200,"data":{"sites":[{"id":1,"site":"site1","nickname":"站点1","base_url":"site1.example","is_https":2}]},"msg":""}`,
+			wantSites: 1,
+			wantCalls: 1,
+		},
+		{
+			name:      "签名错误（data 为空数组）",
+			status:    http.StatusOK,
+			body:      `{"ret":403,"data":[],"msg":"sign错误"}`,
+			wantErr:   true,
+			wantCalls: 1, // 上游正常响应，重试无法解决
+		},
+		{
+			name:      "站点列表为空",
+			status:    http.StatusOK,
+			body:      `{"ret":200,"data":{"sites":[]},"msg":""}`,
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name:      "站点缺少域名",
+			status:    http.StatusOK,
+			body:      `{"ret":200,"data":{"sites":[{"id":1,"site":"site1"}]},"msg":""}`,
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name:      "非JSON响应",
+			status:    http.StatusOK,
+			body:      `<html>维护中</html>`,
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name:      "HTTP错误状态码",
+			status:    http.StatusBadGateway,
+			body:      ``,
+			wantErr:   true,
+			wantCalls: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				if got := r.URL.Query().Get("sign"); got != "test-sign" {
+					t.Errorf("sign = %v, want test-sign", got)
+				}
+				if got := r.URL.Query().Get("service"); got != sitesService {
+					t.Errorf("service = %v, want %v", got, sitesService)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			catalog, err := fetchSites(context.Background(), server.URL, "test-sign")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchSites() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(catalog.Sites) != tt.wantSites {
+				t.Errorf("fetchSites() 返回 %d 个站点，期望 %d 个", len(catalog.Sites), tt.wantSites)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("请求 %d 次，期望 %d 次", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFetchSitesRetry(t *testing.T) {
+	useRetryPolicy(t, 3)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ret":200,"data":{"sites":[{"id":1,"site":"site1","nickname":"站点1","base_url":"site1.example","is_https":2}]},"msg":""}`))
+	}))
+	defer server.Close()
+
+	catalog, err := fetchSites(context.Background(), server.URL, "test-sign")
+	if err != nil {
+		t.Fatalf("fetchSites() error = %v", err)
+	}
+	if len(catalog.Sites) != 1 || calls.Load() != 2 {
+		t.Errorf("fetchSites() 返回 %d 个站点，请求 %d 次，期望限流后重试成功", len(catalog.Sites), calls.Load())
+	}
+}
@@ -0,0 +1,429 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+	"top1000/internal/upstream"
+)
+
+// 数据源地址支持的协议
+const (
+	schemeHTTP  = "http"
+	schemeHTTPS = "https"
+	schemeFile  = "file"
+)
+
+// Source Top1000 数据源
+// 不同实现负责从各自的上游获取并解析数据，返回通过验证的 ProcessedData
+type Source interface {
+	// Name 数据源名称（用于日志，不包含查询参数等敏感信息）
+	Name() string
+
+	// Fetch 获取一次数据
+	Fetch(ctx context.Context) (*model.ProcessedData, error)
+}
+
+// NewSource 根据地址列表创建数据源
+// http(s):// 地址按 IYUU 接口请求，file:// 地址读取本地文件或目录；
+// 多个地址时按顺序故障转移
+func NewSource(rawURLs []string) (Source, error) {
+	if len(rawURLs) == 0 {
+		return nil, errors.New("未配置数据源")
+	}
+
+	sources := make([]Source, 0, len(rawURLs))
+	for _, rawURL := range rawURLs {
+		source, err := parseSource(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	if len(sources) == 1 {
+		return sources[0], nil
+	}
+	return NewFailoverSource(sources...), nil
+}
+
+// DefaultSource 根据 SOURCE_URLS 创建数据源
+// 配置已在启动时验证，这里出错时退回官方接口
+func DefaultSource() Source {
+	source, err := NewSource(config.Get().SourceURLs)
+	if err != nil {
+		log.Printf("[%s] 数据源配置无效，使用官方接口: %v", logPrefix, err)
+		return NewHTTPSource(config.DefaultAPIURL)
+	}
+	return source
+}
+
+// parseSource 解析单个数据源地址
+func parseSource(rawURL string) (Source, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析数据源地址失败: %w", err)
+	}
+
+	switch u.Scheme {
+	case schemeHTTP, schemeHTTPS:
+		if u.Host == "" {
+			return nil, fmt.Errorf("数据源地址缺少主机: %s", rawURL)
+		}
+		return NewHTTPSource(rawURL), nil
+	case schemeFile:
+		// file:///data/top1000.txt 或 file://./data（相对路径）
+		path := u.Host + u.Path
+		if path == "" {
+			return nil, fmt.Errorf("数据源地址缺少路径: %s", rawURL)
+		}
+		return NewFileSource(path), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据源协议: %q", u.Scheme)
+	}
+}
+
+// ===== HTTP 数据源 =====
+
+// HTTPSource 通过 HTTP 获取数据（IYUU 官方接口或自建镜像）
+// 上游返回 ETag/Last-Modified 时，后续请求带上条件请求头；
+// 返回 304 时复用上一次解析的结果，不再下载和解析完整内容（没有新的解析报告和原始内容）
+type HTTPSource struct {
+	url string
+
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	last         *model.ProcessedData // 上一次成功解析的数据（条件请求命中时返回，不含 Report/Raw）
+}
+
+// NewHTTPSource 创建 HTTP 数据源
+func NewHTTPSource(url string) *HTTPSource {
+	return &HTTPSource{url: url}
+}
+
+// Name 返回去掉查询参数和凭据的地址
+func (s *HTTPSource) Name() string {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return s.url
+	}
+	return u.Scheme + "://" + u.Host + u.Path
+}
+
+// Fetch 执行HTTP请求获取数据（经过该地址的熔断器，连续失败后直接跳过）
+func (s *HTTPSource) Fetch(ctx context.Context) (*model.ProcessedData, error) {
+	var data *model.ProcessedData
+	err := upstream.BreakerFor(s.Name()).Call(ctx, func(ctx context.Context) error {
+		var err error
+		data, err = s.fetch(ctx)
+		return err
+	})
+	return data, err
+}
+
+// fetch 执行一次HTTP请求
+// Go 1.26: 使用 createHTTPClient 辅助函数，io.ReadAll 性能已优化
+func (s *HTTPSource) fetch(ctx context.Context) (*model.ProcessedData, error) {
+	log.Printf("[%s] 开始从 %s 获取数据...", logPrefix, s.Name())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
+	}
+	s.setConditionalHeaders(req)
+
+	client := createHTTPClient(ctx)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("获取数据失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		if data := s.cached(); data != nil {
+			log.Printf("[%s] 上游数据未变化（304），沿用上次结果", logPrefix)
+			return data, nil
+		}
+	}
+
+	if err := upstream.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应体失败: %w", err)
+	}
+
+	log.Printf("[%s] 数据获取成功（%d 字节）", logPrefix, len(body))
+	data, err := parseBody(s.Name(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	s.remember(resp.Header, data)
+	return data, nil
+}
+
+// setConditionalHeaders 有上一次的结果时带上 If-None-Match/If-Modified-Since
+func (s *HTTPSource) setConditionalHeaders(req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == nil {
+		return
+	}
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+	if s.lastModified != "" {
+		req.Header.Set("If-Modified-Since", s.lastModified)
+	}
+}
+
+// remember 记录本次响应的校验信息和解析结果（上游不支持条件请求时不缓存数据）
+// 解析报告和原始内容属于本次爬取，不缓存：304 时没有重新解析，不能把旧报告当成最近一次爬取保存
+func (s *HTTPSource) remember(header http.Header, data *model.ProcessedData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.etag = header.Get("ETag")
+	s.lastModified = header.Get("Last-Modified")
+	s.last = nil
+	if s.etag != "" || s.lastModified != "" {
+		copied := *data
+		copied.Items = slices.Clone(data.Items)
+		copied.Report, copied.Raw = nil, nil
+		s.last = &copied
+	}
+}
+
+// cached 返回上一次解析结果的副本
+func (s *HTTPSource) cached() *model.ProcessedData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == nil {
+		return nil
+	}
+	copied := *s.last
+	copied.Items = slices.Clone(s.last.Items)
+	return &copied
+}
+
+// ===== 本地文件数据源 =====
+
+// FileSource 从本地文件读取数据
+// 路径为目录时读取其中最近修改的文件（方便定时任务持续写入新文件）
+type FileSource struct {
+	path string
+}
+
+// NewFileSource 创建本地文件数据源
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+// Name 返回文件路径
+func (s *FileSource) Name() string {
+	return schemeFile + "://" + s.path
+}
+
+// Fetch 读取并解析本地文件
+func (s *FileSource) Fetch(ctx context.Context) (*model.ProcessedData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	path, err := s.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[%s] 开始读取本地文件 %s...", logPrefix, path)
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取数据文件失败: %w", err)
+	}
+
+	return parseBody(s.Name(), body)
+}
+
+// resolve 返回实际要读取的文件（目录时取最近修改的普通文件，忽略隐藏文件）
+func (s *FileSource) resolve() (string, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", fmt.Errorf("读取数据文件失败: %w", err)
+	}
+	if !info.IsDir() {
+		return s.path, nil
+	}
+
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return "", fmt.Errorf("读取数据目录失败: %w", err)
+	}
+
+	var latest string
+	var latestInfo os.FileInfo
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if latestInfo == nil || info.ModTime().After(latestInfo.ModTime()) {
+			latest, latestInfo = entry.Name(), info
+		}
+	}
+
+	if latestInfo == nil {
+		return "", fmt.Errorf("数据目录为空: %s", s.path)
+	}
+	return filepath.Join(s.path, latest), nil
+}
+
+// ===== 故障转移数据源 =====
+
+// FailoverSource 按顺序尝试多个数据源，返回第一个成功的结果
+type FailoverSource struct {
+	sources []Source
+}
+
+// NewFailoverSource 创建故障转移数据源
+func NewFailoverSource(sources ...Source) *FailoverSource {
+	return &FailoverSource{sources: sources}
+}
+
+// Name 返回所有数据源名称
+func (s *FailoverSource) Name() string {
+	names := make([]string, len(s.sources))
+	for i, source := range s.sources {
+		names[i] = source.Name()
+	}
+	return strings.Join(names, " -> ")
+}
+
+// Fetch 依次尝试各个数据源，全部失败时返回所有错误
+func (s *FailoverSource) Fetch(ctx context.Context) (*model.ProcessedData, error) {
+	var errs []error
+	for i, source := range s.sources {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		data, err := source.Fetch(ctx)
+		if err == nil {
+			if i > 0 {
+				log.Printf("[%s] 已切换到备用数据源 %s", logPrefix, source.Name())
+			}
+			return data, nil
+		}
+
+		log.Printf("[%s] 数据源 %s 失败: %v", logPrefix, source.Name(), err)
+		errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+	}
+
+	return nil, fmt.Errorf("所有数据源均失败: %w", errors.Join(errs...))
+}
+
+// ===== 解析 =====
+
+// parserStrict 是否启用严格解析（PARSER_STRICT，测试中替换）
+var parserStrict = func() bool { return config.Get().ParserStrict }
+
+// ParseError 数据源内容无法通过验证（携带解析报告和原始内容，方便排查上游格式变化）
+type ParseError struct {
+	Err    error
+	Report *model.ParseReport
+	Raw    *model.RawPayload
+}
+
+// Error 实现 error 接口
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Parse 用当前解析器解析一份上游原始内容（reparse 用于修复历史快照）
+// 与爬取时相同，返回的数据已通过验证，错误中包含 *ParseError
+func Parse(source string, body []byte) (*model.ProcessedData, error) {
+	return parseBody(source, body)
+}
+
+// parseBody 解析数据源内容，解析报告和原始内容保存在返回数据的 Report/Raw 中
+// 以 { 开头时按 JSON（本服务 /top1000.json 的格式）解析，否则按 IYUU 文本格式解析；
+// 内容错误重试无法解决，标记为不可重试（错误中包含 *ParseError）
+func parseBody(source string, body []byte) (*model.ProcessedData, error) {
+	raw := model.NewRawPayload(source, body, time.Now())
+
+	var processed model.ProcessedData
+	var report *model.ParseReport
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &processed); err != nil {
+			return nil, upstream.Permanent(&ParseError{Err: fmt.Errorf("解析JSON失败: %w", err), Raw: raw})
+		}
+		// 旧版本镜像没有数值字段，按原始文本补齐
+		for i := range processed.Items {
+			processed.Items[i].ComputeValues()
+		}
+		report = &model.ParseReport{
+			Format:   model.ReportFormatJSON,
+			ParsedAt: time.Now(),
+			Time:     processed.Time,
+			Items:    len(processed.Items),
+			Groups:   len(processed.Items),
+		}
+	} else {
+		processed, report = parseResponse(string(body))
+	}
+	report.Source = source
+	processed.Report = report
+	processed.Raw = raw
+
+	// 严格模式：任何跳过的组、无法识别或不规范的行都拒绝整份数据
+	if parserStrict() && report.Anomalies() > 0 {
+		err := fmt.Errorf("严格模式下拒绝数据: 跳过 %d 组，%d 行无法识别，%d 行格式不规范",
+			report.SkippedGroups, report.LeftoverLines, report.IrregularLines)
+		log.Printf("[%s] %v", logPrefix, err)
+		return nil, upstream.Permanent(&ParseError{Err: err, Report: report, Raw: raw})
+	}
+
+	if err := processed.Validate(); err != nil {
+		log.Printf("[%s] 数据验证失败: %v", logPrefix, err)
+		return nil, upstream.Permanent(&ParseError{Err: err, Report: report, Raw: raw})
+	}
+
+	// 上游时间不带时区，按 UPSTREAM_TIMEZONE 解析（镜像 JSON 已带 createdAt 时沿用）
+	if processed.CreatedAt.IsZero() {
+		if err := processed.ParseTime(config.Get().UpstreamLocation()); err != nil {
+			log.Printf("[%s] 数据验证失败: %v", logPrefix, err)
+			return nil, upstream.Permanent(&ParseError{Err: err, Report: report, Raw: raw})
+		}
+	}
+
+	return &processed, nil
+}
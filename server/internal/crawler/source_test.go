@@ -0,0 +1,323 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"top1000/internal/model"
+	"top1000/internal/upstream"
+)
+
+const testRawData = `create time 2026-01-19 07:50:56 by xxx
+
+站名：测试站点 【ID：123】
+重复度：85.5
+文件大小：1.2TB
+`
+
+// stubSource 测试用数据源
+type stubSource struct {
+	name  string
+	data  *model.ProcessedData
+	err   error
+	calls int
+}
+
+func (s *stubSource) Name() string { return s.name }
+
+func (s *stubSource) Fetch(ctx context.Context) (*model.ProcessedData, error) {
+	s.calls++
+	return s.data, s.err
+}
+
+func TestNewSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		urls     []string
+		wantType string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "官方接口", urls: []string{"https://api.iyuu.cn/top1000.php"}, wantType: "*crawler.HTTPSource", wantName: "https://api.iyuu.cn/top1000.php"},
+		{name: "本地文件", urls: []string{"file:///data/top1000.txt"}, wantType: "*crawler.FileSource", wantName: "file:///data/top1000.txt"},
+		{name: "相对路径", urls: []string{"file://./data"}, wantType: "*crawler.FileSource", wantName: "file://./data"},
+		{
+			name:     "多个地址按顺序故障转移",
+			urls:     []string{"https://api.iyuu.cn/top1000.php", "https://mirror.example.com/top1000.txt?token=secret"},
+			wantType: "*crawler.FailoverSource",
+			wantName: "https://api.iyuu.cn/top1000.php -> https://mirror.example.com/top1000.txt",
+		},
+		{name: "未配置", urls: nil, wantErr: true},
+		{name: "不支持的协议", urls: []string{"ftp://mirror.example.com/top1000.txt"}, wantErr: true},
+		{name: "缺少主机", urls: []string{"https:///top1000.txt"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := NewSource(tt.urls)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := fmt.Sprintf("%T", source); got != tt.wantType {
+				t.Errorf("NewSource() = %v, want %v", got, tt.wantType)
+			}
+			if source.Name() != tt.wantName {
+				t.Errorf("Name() = %v, want %v", source.Name(), tt.wantName)
+			}
+		})
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantErr  bool
+		wantSite string
+	}{
+		{name: "IYUU文本格式", status: http.StatusOK, body: testRawData, wantSite: "测试站点"},
+		{
+			name:     "JSON格式（自建镜像）",
+			status:   http.StatusOK,
+			body:     `{"time":"2026-01-19 07:50:56","items":[{"siteName":"镜像站点","siteid":"1","duplication":"80","size":"1TB","id":1}]}`,
+			wantSite: "镜像站点",
+		},
+		{
+			name:    "时间格式错误",
+			status:  http.StatusOK,
+			body:    `{"time":"昨天","items":[{"siteName":"镜像站点","siteid":"1","duplication":"80","size":"1TB","id":1}]}`,
+			wantErr: true,
+		},
+		{name: "无效JSON", status: http.StatusOK, body: `{"time":`, wantErr: true},
+		{name: "无有效数据", status: http.StatusOK, body: "维护中", wantErr: true},
+		{name: "HTTP错误状态码", status: http.StatusBadGateway, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			data, err := NewHTTPSource(server.URL).Fetch(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && data.Items[0].SiteName != tt.wantSite {
+				t.Errorf("SiteName = %v, want %v", data.Items[0].SiteName, tt.wantSite)
+			}
+			// 文本和旧版 JSON 都应补齐数值字段
+			if err == nil && (data.Items[0].SizeBytes == 0 || data.Items[0].DuplicationValue == 0) {
+				t.Errorf("数值字段未计算: %+v", data.Items[0])
+			}
+			// 2026-01-19 07:50:56（Asia/Shanghai）= 2026-01-18T23:50:56Z
+			if err == nil && data.CreatedAtUnix != 1768780256 {
+				t.Errorf("CreatedAtUnix = %v, want %v", data.CreatedAtUnix, 1768780256)
+			}
+		})
+	}
+}
+
+func TestHTTPSourceConditional(t *testing.T) {
+	const etag = `"v1"`
+	var requests, full atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(testRawData))
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.URL)
+	first, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	second, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("304 时 Fetch() error = %v", err)
+	}
+
+	if requests.Load() != 2 || full.Load() != 1 {
+		t.Errorf("请求 %d 次（完整响应 %d 次），期望第二次命中 304", requests.Load(), full.Load())
+	}
+	if second == first || second.ContentHash() != first.ContentHash() {
+		t.Errorf("304 时应返回上次结果的副本，得到 %+v", second)
+	}
+	// 没有重新解析，不能带上一次的解析报告和原始内容（否则会被当成最近一次爬取保存）
+	if first.Report == nil || second.Report != nil || second.Raw != nil {
+		t.Errorf("304 时 Report = %+v, Raw = %+v，期望为空", second.Report, second.Raw)
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("读取文件", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "top1000.txt")
+		if err := os.WriteFile(path, []byte(testRawData), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := NewFileSource(path).Fetch(ctx)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if data.Time != "2026-01-19 07:50:56" || len(data.Items) != 1 {
+			t.Errorf("Fetch() = %+v", data)
+		}
+		if data.Report == nil || data.Report.Source != "file://"+path || data.Report.Items != 1 {
+			t.Errorf("Report = %+v", data.Report)
+		}
+	})
+
+	t.Run("格式变化时错误中带解析报告", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "top1000.txt")
+		changed := "create time 2026-01-19 07:50:56 by xxx\n\n站点: 测试站点 (123)\n重复度: 85.5%\n大小: 1.2TB\n"
+		if err := os.WriteFile(path, []byte(changed), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := NewFileSource(path).Fetch(ctx)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("Fetch() error = %v, want *ParseError", err)
+		}
+		if parseErr.Report.LeftoverLines != 3 || parseErr.Report.Leftover[0].Line != 3 {
+			t.Errorf("Report = %+v", parseErr.Report)
+		}
+		if upstream.Retryable(err) {
+			t.Error("格式错误不应重试")
+		}
+	})
+
+	t.Run("目录中读取最近修改的文件", func(t *testing.T) {
+		dir := t.TempDir()
+		older := filepath.Join(dir, "a.txt")
+		newer := filepath.Join(dir, "b.txt")
+		if err := os.WriteFile(older, []byte("维护中"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(newer, []byte(testRawData), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// 隐藏文件（如写入中的临时文件）应被忽略
+		if err := os.WriteFile(filepath.Join(dir, ".tmp"), []byte("维护中"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		past := time.Now().Add(-time.Hour)
+		if err := os.Chtimes(older, past, past); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := NewFileSource(dir).Fetch(ctx)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if data.Items[0].SiteName != "测试站点" {
+			t.Errorf("应读取最近修改的文件，得到 %+v", data)
+		}
+	})
+
+	t.Run("空目录", func(t *testing.T) {
+		if _, err := NewFileSource(t.TempDir()).Fetch(ctx); err == nil {
+			t.Error("Fetch() 期望返回错误")
+		}
+	})
+
+	t.Run("文件不存在", func(t *testing.T) {
+		if _, err := NewFileSource(filepath.Join(t.TempDir(), "missing.txt")).Fetch(ctx); err == nil {
+			t.Error("Fetch() 期望返回错误")
+		}
+	})
+}
+
+func TestFailoverSource(t *testing.T) {
+	ctx := context.Background()
+	data := &model.ProcessedData{Time: "2026-01-19 07:50:56", Items: []model.SiteItem{{SiteName: "镜像站点", SiteID: "1", ID: 1}}}
+
+	t.Run("主数据源失败时切换到镜像", func(t *testing.T) {
+		primary := &stubSource{name: "primary", err: errors.New("连接超时")}
+		mirror := &stubSource{name: "mirror", data: data}
+		unused := &stubSource{name: "unused", data: data}
+
+		got, err := NewFailoverSource(primary, mirror, unused).Fetch(ctx)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if got != data {
+			t.Errorf("Fetch() = %+v, want %+v", got, data)
+		}
+		if primary.calls != 1 || mirror.calls != 1 || unused.calls != 0 {
+			t.Errorf("调用次数 primary=%d mirror=%d unused=%d", primary.calls, mirror.calls, unused.calls)
+		}
+	})
+
+	t.Run("全部失败时返回所有错误", func(t *testing.T) {
+		errPrimary := errors.New("连接超时")
+		errMirror := errors.New("状态码 502")
+
+		_, err := NewFailoverSource(
+			&stubSource{name: "primary", err: errPrimary},
+			&stubSource{name: "mirror", err: errMirror},
+		).Fetch(ctx)
+		if !errors.Is(err, errPrimary) || !errors.Is(err, errMirror) {
+			t.Errorf("Fetch() error = %v，期望包含所有数据源的错误", err)
+		}
+	})
+
+	t.Run("已取消时不再尝试", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		source := &stubSource{name: "primary", data: data}
+		if _, err := NewFailoverSource(source).Fetch(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Fetch() error = %v, want context.Canceled", err)
+		}
+		if source.calls != 0 {
+			t.Errorf("已取消时不应请求数据源，实际请求 %d 次", source.calls)
+		}
+	})
+}
+
+func TestFetchFromRetry(t *testing.T) {
+	useRetryPolicy(t, 3)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(testRawData))
+	}))
+	defer server.Close()
+
+	data, err := FetchFrom(context.Background(), NewHTTPSource(server.URL))
+	if err != nil {
+		t.Fatalf("FetchFrom() error = %v", err)
+	}
+	if len(data.Items) != 1 || calls.Load() != 3 {
+		t.Errorf("FetchFrom() 返回 %d 条，请求 %d 次，期望重试后成功", len(data.Items), calls.Load())
+	}
+}
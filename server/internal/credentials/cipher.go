@@ -0,0 +1,59 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// sealVersion 密文格式版本（第一个字节，之后为 nonce 和 AES-GCM 密文）
+const sealVersion byte = 1
+
+// errSealedInvalid 密文格式错误、被篡改或密钥不匹配（不包含密文内容）
+var errSealedInvalid = errors.New("凭据密文无效或密钥不匹配")
+
+// sealer AES-256-GCM 加密
+type sealer struct {
+	aead cipher.AEAD
+}
+
+// newSealer 用 32 字节密钥创建加密器
+func newSealer(key []byte) (*sealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("创建凭据加密器失败: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("创建凭据加密器失败: %w", err)
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal 加密明文，associated 为附加数据（解密时必须相同）
+func (s *sealer) seal(plaintext, associated []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %w", err)
+	}
+
+	sealed := make([]byte, 0, 1+len(nonce)+len(plaintext)+s.aead.Overhead())
+	sealed = append(append(sealed, sealVersion), nonce...)
+	return s.aead.Seal(sealed, nonce, plaintext, associated), nil
+}
+
+// open 解密 seal 的结果
+func (s *sealer) open(sealed, associated []byte) ([]byte, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < 1+nonceSize || sealed[0] != sealVersion {
+		return nil, errSealedInvalid
+	}
+
+	plaintext, err := s.aead.Open(nil, sealed[1:1+nonceSize], sealed[1+nonceSize:], associated)
+	if err != nil {
+		return nil, errSealedInvalid
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,136 @@
+// Package credentials 用户站点凭据（passkey、downHash 等）的加密保存
+// 凭据用 SECRET_KEY 以 AES-256-GCM 加密后写入存储后端，用户名作为附加数据，
+// 一个用户的密文不能挪给其他用户解密；明文只在处理请求时存在于内存中，不写入日志和错误信息
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"top1000/internal/model"
+	"top1000/internal/siterules"
+	"top1000/internal/storage"
+)
+
+// maxValueLength 单个凭据值的最大长度
+const maxValueLength = 256
+
+// Vault 用户凭据的加密存储
+type Vault struct {
+	store  storage.CredentialStore
+	sealer *sealer
+}
+
+// NewVault 创建凭据存储（key 为 32 字节的 SECRET_KEY）
+func NewVault(store storage.CredentialStore, key []byte) (*Vault, error) {
+	s, err := newSealer(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{store: store, sealer: s}, nil
+}
+
+// Load 加载用户的凭据（没有保存过时返回空集合）
+func (v *Vault) Load(ctx context.Context, user string) (model.UserCredentials, error) {
+	sealed, err := v.store.LoadCredentials(ctx, user)
+	if errors.Is(err, storage.ErrCredentialsNotFound) {
+		return model.UserCredentials{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.open(user, sealed)
+}
+
+// Save 加密保存用户的全部凭据（为空时删除）
+func (v *Vault) Save(ctx context.Context, user string, creds model.UserCredentials) error {
+	if len(creds) == 0 {
+		return v.store.DeleteCredentials(ctx, user)
+	}
+
+	sealed, err := v.seal(user, creds)
+	if err != nil {
+		return err
+	}
+	return v.store.SaveCredentials(ctx, user, sealed)
+}
+
+// Update 原子地修改用户的凭据，返回修改后的凭据（修改后为空时删除）
+// 同一用户的并发修改互不覆盖，与其他修改冲突时 fn 会用最新的凭据重新调用
+func (v *Vault) Update(ctx context.Context, user string, fn func(model.UserCredentials)) (model.UserCredentials, error) {
+	var creds model.UserCredentials
+	err := v.store.UpdateCredentials(ctx, user, func(sealed []byte) ([]byte, error) {
+		creds = model.UserCredentials{}
+		if sealed != nil {
+			var err error
+			if creds, err = v.open(user, sealed); err != nil {
+				return nil, err
+			}
+		}
+
+		fn(creds)
+		if len(creds) == 0 {
+			return nil, nil
+		}
+		return v.seal(user, creds)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// open 解密用户的凭据密文
+func (v *Vault) open(user string, sealed []byte) (model.UserCredentials, error) {
+	plaintext, err := v.sealer.open(sealed, associatedData(user))
+	if err != nil {
+		return nil, fmt.Errorf("用户 %s 的凭据: %w", user, err)
+	}
+
+	var creds model.UserCredentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return nil, fmt.Errorf("用户 %s 的凭据格式错误", user)
+	}
+	return creds, nil
+}
+
+// seal 加密用户的凭据
+func (v *Vault) seal(user string, creds model.UserCredentials) ([]byte, error) {
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return nil, fmt.Errorf("序列化凭据失败: %w", err)
+	}
+	return v.sealer.seal(plaintext, associatedData(user))
+}
+
+// associatedData 加密附加数据（绑定用户名）
+func associatedData(user string) []byte {
+	return []byte("top1000/credentials:" + user)
+}
+
+// Validate 检查一个站点的凭据：名称必须是链接模板中的凭据占位符，值不能为空或包含空白字符
+// 返回的错误只包含名称，不包含凭据值
+func Validate(values model.SiteCredentials) error {
+	var errs model.ValidationErrors
+
+	if len(values) == 0 {
+		errs = append(errs, "凭据不能为空")
+	}
+	for name, value := range values {
+		switch {
+		case !siterules.IsCredential(name):
+			errs = append(errs, "不支持的凭据: "+name)
+		case value == "" || len(value) > maxValueLength || strings.ContainsFunc(value, unicode.IsSpace):
+			errs = append(errs, fmt.Sprintf("%s 的值无效（不能为空、包含空白字符或超过 %d 个字符）", name, maxValueLength))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"top1000/internal/model"
+	"top1000/internal/storage"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestVault(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	vault, err := NewVault(store, testKey)
+	if err != nil {
+		t.Fatalf("NewVault() error = %v", err)
+	}
+
+	if creds, err := vault.Load(ctx, "alice"); err != nil || len(creds) != 0 {
+		t.Fatalf("没有保存时 Load() = %v, %v，期望空集合", creds, err)
+	}
+
+	creds := model.UserCredentials{"hdsky": {"passkey": "alice-passkey"}, "hdchina": {"downHash": "alice-downhash"}}
+	if err := vault.Save(ctx, "alice", creds); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := vault.Load(ctx, "alice")
+	if err != nil || got["hdsky"]["passkey"] != "alice-passkey" || got["hdchina"]["downHash"] != "alice-downhash" {
+		t.Fatalf("Load() = %v, %v", got, err)
+	}
+
+	// 存储中只有密文
+	sealed, err := store.LoadCredentials(ctx, "alice")
+	if err != nil || bytes.Contains(sealed, []byte("alice-passkey")) {
+		t.Fatalf("存储中的内容 = %q, %v，不应包含明文", sealed, err)
+	}
+
+	// 密文挪给其他用户或换了密钥都无法解密，错误中不包含凭据
+	if err := store.SaveCredentials(ctx, "bob", sealed); err != nil {
+		t.Fatalf("SaveCredentials() error = %v", err)
+	}
+	if _, err := vault.Load(ctx, "bob"); err == nil || strings.Contains(err.Error(), "alice-passkey") {
+		t.Errorf("其他用户 Load() error = %v，期望解密失败", err)
+	}
+	other, _ := NewVault(store, []byte("fedcba9876543210fedcba9876543210"))
+	if _, err := other.Load(ctx, "alice"); err == nil {
+		t.Error("密钥不同时 Load() 应返回错误")
+	}
+
+	// 原子修改：返回修改后的凭据，其他站点保留
+	updated, err := vault.Update(ctx, "alice", func(creds model.UserCredentials) {
+		creds["hdsky"] = model.SiteCredentials{"passkey": "alice-new"}
+		delete(creds, "hdchina")
+	})
+	if err != nil || len(updated) != 1 || updated["hdsky"]["passkey"] != "alice-new" {
+		t.Fatalf("Update() = %v, %v", updated, err)
+	}
+	if got, err := vault.Load(ctx, "alice"); err != nil || len(got) != 1 || got["hdsky"]["passkey"] != "alice-new" {
+		t.Errorf("Update() 后 Load() = %v, %v", got, err)
+	}
+	if _, err := vault.Update(ctx, "bob", func(model.UserCredentials) { t.Error("密文无法解密时不应调用 fn") }); err == nil {
+		t.Error("密文无法解密时 Update() 应返回错误")
+	}
+
+	// 保存空集合即删除
+	if err := vault.Save(ctx, "alice", model.UserCredentials{}); err != nil {
+		t.Fatalf("Save(空) error = %v", err)
+	}
+	if _, err := store.LoadCredentials(ctx, "alice"); !errors.Is(err, storage.ErrCredentialsNotFound) {
+		t.Errorf("保存空集合后 LoadCredentials() error = %v，期望已删除", err)
+	}
+
+	if _, err := NewVault(store, []byte("short")); err == nil {
+		t.Error("密钥长度错误时 NewVault() 应返回错误")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  model.SiteCredentials
+		wantErr bool
+	}{
+		{name: "passkey", values: model.SiteCredentials{"passkey": "0123abcd"}},
+		{name: "多个凭据", values: model.SiteCredentials{"uid": "7", "hash": "abc", "authkey": "a", "torrent_pass": "b", "downHash": "c"}},
+		{name: "空", values: model.SiteCredentials{}, wantErr: true},
+		{name: "不支持的名称", values: model.SiteCredentials{"cookie": "x"}, wantErr: true},
+		{name: "空值", values: model.SiteCredentials{"passkey": ""}, wantErr: true},
+		{name: "包含空白字符", values: model.SiteCredentials{"passkey": "abc def"}, wantErr: true},
+		{name: "过长", values: model.SiteCredentials{"passkey": strings.Repeat("a", maxValueLength+1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, value := range tt.values {
+				if err != nil && value != "" && strings.Contains(err.Error(), value) {
+					t.Errorf("Validate() error = %v，不应包含凭据值", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Package guard 保存前的数据异常检测
+// Validate 只检查字段格式，被截断的上游响应（例如只有 40 条合法数据）也能通过；
+// 这里对比当前数据检查条目数、降幅、解析跳过比例和时间，拒绝可疑的数据
+package guard
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+// Rules 异常检测规则（数值为 0 表示不检查该项）
+type Rules struct {
+	MinItems         int     // 最少条目数
+	MaxDropPercent   float64 // 条目数相对当前数据最多减少的百分比
+	MaxSkippedRatio  float64 // 解析时最多跳过的数据比例（0-1，只对文本格式生效）
+	RejectTimeRewind bool    // 拒绝时间早于当前数据的数据
+}
+
+// DefaultRules 根据配置创建检测规则
+func DefaultRules() Rules {
+	cfg := config.Get()
+	return Rules{
+		MinItems:         cfg.GuardMinItems,
+		MaxDropPercent:   cfg.GuardMaxDropPercent,
+		MaxSkippedRatio:  cfg.GuardMaxSkippedRatio,
+		RejectTimeRewind: cfg.GuardRejectTimeRewind,
+	}
+}
+
+// Violations 违反的规则（实现 error 接口）
+type Violations []string
+
+// Error 实现 error 接口
+func (v Violations) Error() string {
+	return "数据异常: " + strings.Join(v, "; ")
+}
+
+// Check 检查新数据，current 为当前保存的数据（不存在时为 nil）
+// 通过时返回 nil，否则返回 Violations
+func (r Rules) Check(current, next *model.ProcessedData) error {
+	var violations Violations
+
+	count := len(next.Items)
+	if r.MinItems > 0 && count < r.MinItems {
+		violations = append(violations, fmt.Sprintf("条目数 %d 少于 %d", count, r.MinItems))
+	}
+
+	if ratio := next.Report.SkippedRatio(); r.MaxSkippedRatio > 0 && ratio > r.MaxSkippedRatio {
+		violations = append(violations, fmt.Sprintf("跳过 %d/%d 组数据（%.1f%%），超过 %.1f%%",
+			next.Report.SkippedGroups, next.Report.Groups, ratio*100, r.MaxSkippedRatio*100))
+	}
+
+	if current != nil && len(current.Items) > 0 {
+		previous := len(current.Items)
+		drop := float64(previous-count) / float64(previous) * 100
+		if r.MaxDropPercent > 0 && drop > r.MaxDropPercent {
+			violations = append(violations, fmt.Sprintf("条目数从 %d 降到 %d（减少 %.1f%%），超过 %.1f%%",
+				previous, count, drop, r.MaxDropPercent))
+		}
+
+		if r.RejectTimeRewind && timeRewound(current, next) {
+			violations = append(violations, fmt.Sprintf("数据时间 %s 早于当前数据 %s", next.Time, current.Time))
+		}
+	}
+
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// timeRewound 新数据的时间是否早于当前数据（任一时间无法解析时不判断）
+func timeRewound(current, next *model.ProcessedData) bool {
+	loc := config.Get().UpstreamLocation()
+	currentTime, err := current.CreatedTime(loc)
+	if err != nil {
+		return false
+	}
+	nextTime, err := next.CreatedTime(loc)
+	if err != nil {
+		return false
+	}
+	return nextTime.Before(currentTime)
+}
+
+// NewQuarantineEntry 创建隔离区记录
+func NewQuarantineEntry(data *model.ProcessedData, reasons []string, now time.Time) model.QuarantineEntry {
+	return model.QuarantineEntry{
+		ID:          fmt.Sprintf("%d", now.UnixMilli()),
+		RejectedAt:  now,
+		Reasons:     reasons,
+		Time:        data.Time,
+		ItemCount:   len(data.Items),
+		ContentHash: data.ContentHash(),
+		Data:        data,
+		Report:      data.Report,
+	}
+}
@@ -0,0 +1,80 @@
+package guard
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"top1000/internal/model"
+)
+
+// dataset 生成指定条目数的测试数据
+func dataset(dataTime string, count int, report *model.ParseReport) *model.ProcessedData {
+	data := &model.ProcessedData{Time: dataTime, Report: report}
+	for i := range count {
+		data.Items = append(data.Items, model.SiteItem{SiteName: "站点", SiteID: "1", ID: i + 1})
+	}
+	return data
+}
+
+func TestRulesCheck(t *testing.T) {
+	rules := Rules{MinItems: 100, MaxDropPercent: 50, MaxSkippedRatio: 0.2, RejectTimeRewind: true}
+	current := dataset("2026-01-19 07:50:56", 1000, nil)
+
+	tests := []struct {
+		name           string
+		rules          Rules
+		current        *model.ProcessedData
+		next           *model.ProcessedData
+		wantViolations int
+	}{
+		{name: "正常数据", rules: rules, current: current, next: dataset("2026-01-20 07:50:56", 990, nil)},
+		{name: "首次保存", rules: rules, next: dataset("2026-01-20 07:50:56", 200, nil)},
+		{name: "条目数过少", rules: rules, next: dataset("2026-01-20 07:50:56", 40, nil), wantViolations: 1},
+		{name: "条目数骤降", rules: rules, current: current, next: dataset("2026-01-20 07:50:56", 400, nil), wantViolations: 1},
+		{name: "截断的响应", rules: rules, current: current, next: dataset("2026-01-20 07:50:56", 40, nil), wantViolations: 2},
+		{
+			name:           "跳过比例过高",
+			rules:          rules,
+			current:        current,
+			next:           dataset("2026-01-20 07:50:56", 700, &model.ParseReport{Groups: 1000, SkippedGroups: 300}),
+			wantViolations: 1,
+		},
+		{name: "时间回退", rules: rules, current: current, next: dataset("2026-01-18 07:50:56", 1000, nil), wantViolations: 1},
+		{name: "时间无法解析时不判断回退", rules: rules, current: current, next: dataset("昨天", 1000, nil)},
+		{name: "规则为零值时不检查", current: current, next: dataset("2026-01-18 07:50:56", 1, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.Check(tt.current, tt.next)
+			if tt.wantViolations == 0 {
+				if err != nil {
+					t.Errorf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var violations Violations
+			if !errors.As(err, &violations) {
+				t.Fatalf("Check() error = %v, want Violations", err)
+			}
+			if len(violations) != tt.wantViolations {
+				t.Errorf("Check() = %v, want %d 条违规", violations, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestNewQuarantineEntry(t *testing.T) {
+	data := dataset("2026-01-20 07:50:56", 40, nil)
+	now := time.UnixMilli(1768780256000)
+
+	entry := NewQuarantineEntry(data, []string{"条目数 40 少于 100"}, now)
+	if entry.ID != "1768780256000" || !entry.RejectedAt.Equal(now) {
+		t.Errorf("ID = %v, RejectedAt = %v", entry.ID, entry.RejectedAt)
+	}
+	if entry.Time != data.Time || entry.ItemCount != 40 || entry.ContentHash != data.ContentHash() || entry.Data != data {
+		t.Errorf("NewQuarantineEntry() = %+v", entry)
+	}
+}
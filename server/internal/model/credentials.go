@@ -0,0 +1,8 @@
+package model
+
+// SiteCredentials 用户在一个站点的下载凭据（key 为链接模板中的占位符名，如 passkey、downHash）
+type SiteCredentials map[string]string
+
+// UserCredentials 一个用户保存的全部站点凭据（key 为站点标识）
+// 只在内存中以明文存在，保存时整体加密；不能写入日志或返回给其他用户
+type UserCredentials map[string]SiteCredentials
@@ -0,0 +1,124 @@
+package model
+
+// 条目变化字段
+const (
+	ChangeDuplication = "duplication"
+	ChangeSize        = "size"
+	ChangeRank        = "rank"
+)
+
+// ItemKey 条目唯一标识（站点名 + 站点内资源ID）
+type ItemKey struct {
+	SiteName string
+	SiteID   string
+}
+
+// Key 返回条目的唯一标识
+func (s *SiteItem) Key() ItemKey {
+	return ItemKey{SiteName: s.SiteName, SiteID: s.SiteID}
+}
+
+// ItemChange 两次快照间同一条目的变化
+type ItemChange struct {
+	SiteName string   `json:"siteName"`
+	SiteID   string   `json:"siteid"`
+	Changes  []string `json:"changes"`  // 变化的字段（duplication/size/rank）
+	RankDiff int      `json:"rankDiff"` // 排名变化（正数表示上升）
+	From     SiteItem `json:"from"`     // 旧快照中的条目
+	To       SiteItem `json:"to"`       // 新快照中的条目
+}
+
+// SnapshotDiff 两次快照的差异
+type SnapshotDiff struct {
+	From    string       `json:"from"`    // 旧快照时间
+	To      string       `json:"to"`      // 新快照时间
+	Added   []SiteItem   `json:"added"`   // 新进入榜单的条目
+	Removed []SiteItem   `json:"removed"` // 掉出榜单的条目
+	Changed []ItemChange `json:"changed"` // 重复度、大小或排名发生变化的条目
+}
+
+// DiffSnapshots 比较两次快照（以 SiteName + SiteID 识别同一条目，排名取 ID）
+// 结果顺序：Added、Changed 按新快照顺序，Removed 按旧快照顺序
+func DiffSnapshots(from, to *ProcessedData) SnapshotDiff {
+	diff := SnapshotDiff{
+		From:    from.Time,
+		To:      to.Time,
+		Added:   []SiteItem{},
+		Removed: []SiteItem{},
+		Changed: []ItemChange{},
+	}
+
+	oldItems := indexItems(from.Items)
+	newItems := indexItems(to.Items)
+
+	// 同一条目重复出现时只处理第一次出现的那条
+	seen := make(map[ItemKey]bool, len(to.Items))
+	for _, item := range to.Items {
+		key := item.Key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		old, ok := oldItems[key]
+		if !ok {
+			diff.Added = append(diff.Added, item)
+			continue
+		}
+		if change, changed := compareItems(old, item); changed {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+
+	clear(seen)
+	for _, item := range from.Items {
+		key := item.Key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if _, ok := newItems[key]; !ok {
+			diff.Removed = append(diff.Removed, item)
+		}
+	}
+
+	return diff
+}
+
+// indexItems 按唯一标识索引条目（重复时保留第一次出现）
+func indexItems(items []SiteItem) map[ItemKey]SiteItem {
+	index := make(map[ItemKey]SiteItem, len(items))
+	for _, item := range items {
+		if _, exists := index[item.Key()]; !exists {
+			index[item.Key()] = item
+		}
+	}
+	return index
+}
+
+// compareItems 比较同一条目的两个版本
+func compareItems(from, to SiteItem) (ItemChange, bool) {
+	var changes []string
+	if from.Duplication != to.Duplication {
+		changes = append(changes, ChangeDuplication)
+	}
+	if from.Size != to.Size {
+		changes = append(changes, ChangeSize)
+	}
+	if from.ID != to.ID {
+		changes = append(changes, ChangeRank)
+	}
+	if len(changes) == 0 {
+		return ItemChange{}, false
+	}
+
+	return ItemChange{
+		SiteName: to.SiteName,
+		SiteID:   to.SiteID,
+		Changes:  changes,
+		RankDiff: from.ID - to.ID,
+		From:     from,
+		To:       to,
+	}, true
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	from := &ProcessedData{
+		Time: "2026-01-18 07:50:56",
+		Items: []SiteItem{
+			{SiteName: "站点A", SiteID: "1", Duplication: "3", Size: "1.2TB", ID: 1},
+			{SiteName: "站点B", SiteID: "2", Duplication: "5", Size: "500GB", ID: 2},
+			{SiteName: "站点C", SiteID: "3", Duplication: "2", Size: "10GB", ID: 3},
+		},
+	}
+	to := &ProcessedData{
+		Time: "2026-01-19 07:50:56",
+		Items: []SiteItem{
+			{SiteName: "站点B", SiteID: "2", Duplication: "6", Size: "500GB", ID: 1},
+			{SiteName: "站点A", SiteID: "1", Duplication: "3", Size: "1.2TB", ID: 2},
+			{SiteName: "站点D", SiteID: "4", Duplication: "1", Size: "1GB", ID: 3},
+		},
+	}
+
+	diff := DiffSnapshots(from, to)
+
+	if diff.From != from.Time || diff.To != to.Time {
+		t.Errorf("From/To = %v/%v, want %v/%v", diff.From, diff.To, from.Time, to.Time)
+	}
+
+	t.Run("新增条目", func(t *testing.T) {
+		if len(diff.Added) != 1 || diff.Added[0].SiteName != "站点D" {
+			t.Errorf("Added = %+v, want [站点D]", diff.Added)
+		}
+	})
+
+	t.Run("移除条目", func(t *testing.T) {
+		if len(diff.Removed) != 1 || diff.Removed[0].SiteName != "站点C" {
+			t.Errorf("Removed = %+v, want [站点C]", diff.Removed)
+		}
+	})
+
+	t.Run("变化条目", func(t *testing.T) {
+		if len(diff.Changed) != 2 {
+			t.Fatalf("Changed = %+v, want 2 条", diff.Changed)
+		}
+
+		b := diff.Changed[0]
+		if b.SiteName != "站点B" || !slices.Equal(b.Changes, []string{ChangeDuplication, ChangeRank}) || b.RankDiff != 1 {
+			t.Errorf("站点B 变化 = %+v", b)
+		}
+
+		a := diff.Changed[1]
+		if a.SiteName != "站点A" || !slices.Equal(a.Changes, []string{ChangeRank}) || a.RankDiff != -1 {
+			t.Errorf("站点A 变化 = %+v", a)
+		}
+	})
+}
+
+func TestDiffSnapshotsSameSiteIDDifferentSite(t *testing.T) {
+	from := &ProcessedData{Items: []SiteItem{{SiteName: "站点A", SiteID: "1", ID: 1}}}
+	to := &ProcessedData{Items: []SiteItem{{SiteName: "站点B", SiteID: "1", ID: 1}}}
+
+	diff := DiffSnapshots(from, to)
+	if len(diff.Added) != 1 || len(diff.Removed) != 1 || len(diff.Changed) != 0 {
+		t.Errorf("不同站点的相同ID应视为不同条目: %+v", diff)
+	}
+}
+
+func TestDiffSnapshotsIdentical(t *testing.T) {
+	data := &ProcessedData{Items: []SiteItem{{SiteName: "站点A", SiteID: "1", Size: "1TB", ID: 1}}}
+
+	diff := DiffSnapshots(data, data)
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) != 0 {
+		t.Errorf("相同快照不应有差异: %+v", diff)
+	}
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// SnapshotMeta 历史快照元信息（不含条目内容）
+type SnapshotMeta struct {
+	Time      string    `json:"time"`      // 上游数据时间（快照标识）
+	ItemCount int       `json:"itemCount"` // 条目数量
+	SavedAt   time.Time `json:"savedAt"`   // 归档时间
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// QuarantineEntry 被异常检测拒绝的数据（隔离区记录）
+// 保留完整数据供人工检查，不会成为当前数据
+type QuarantineEntry struct {
+	ID          string         `json:"id"`               // 记录标识（拒绝时间的 Unix 毫秒）
+	RejectedAt  time.Time      `json:"rejectedAt"`       // 拒绝时间
+	Reasons     []string       `json:"reasons"`          // 拒绝原因
+	Time        string         `json:"time"`             // 被拒绝数据的时间
+	ItemCount   int            `json:"itemCount"`        // 被拒绝数据的条目数
+	ContentHash string         `json:"contentHash"`      // 被拒绝数据的内容哈希（用于去重）
+	Data        *ProcessedData `json:"data,omitempty"`   // 被拒绝的完整数据（列表接口不返回）
+	Report      *ParseReport   `json:"report,omitempty"` // 被拒绝数据的解析报告（列表接口不返回）
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// RawMeta 上游原始内容的元信息
+type RawMeta struct {
+	ID        string    `json:"id"`        // 记录标识（获取时间的 Unix 毫秒）
+	Source    string    `json:"source"`    // 数据源
+	FetchedAt time.Time `json:"fetchedAt"` // 获取时间
+	Size      int       `json:"size"`      // 原始大小（字节）
+	Hash      string    `json:"hash"`      // 原始内容的 SHA-256（用于去重）
+}
+
+// RawPayload 一次爬取的上游原始内容
+// 随爬取结果保存（压缩后按 RAW_MAX_COUNT/RAW_MAX_AGE 清理），解析器修复后可重新解析并改写历史快照
+type RawPayload struct {
+	RawMeta
+	Body []byte `json:"-"` // 原始内容（不参与 JSON 序列化）
+}
+
+// NewRawPayload 创建原始内容记录
+func NewRawPayload(source string, body []byte, fetchedAt time.Time) *RawPayload {
+	sum := sha256.Sum256(body)
+	return &RawPayload{
+		RawMeta: RawMeta{
+			ID:        fmt.Sprintf("%d", fetchedAt.UnixMilli()),
+			Source:    source,
+			FetchedAt: fetchedAt,
+			Size:      len(body),
+			Hash:      hex.EncodeToString(sum[:]),
+		},
+		Body: body,
+	}
+}
@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+// 解析报告的格式
+const (
+	ReportFormatText = "text" // IYUU 文本格式
+	ReportFormatJSON = "json" // 镜像 JSON 格式
+)
+
+// MaxReportDetails 报告中最多保留的跳过组和无法识别的行（超出部分只计数）
+const MaxReportDetails = 50
+
+// ParseReport 一次爬取的解析报告
+// 随快照保存，最近一次爬取的报告可通过 GET /api/crawl/last 查看（包括被拒绝或解析失败的数据）
+type ParseReport struct {
+	Source         string         `json:"source,omitempty"` // 数据源
+	Format         string         `json:"format"`           // 数据格式（text/json）
+	ParsedAt       time.Time      `json:"parsedAt"`         // 解析时间
+	Header         []string       `json:"header"`           // 头部原文（数据行之前的行）
+	Time           string         `json:"time"`             // 从头部提取的数据时间
+	TotalLines     int            `json:"totalLines"`       // 总行数
+	LinesConsumed  int            `json:"linesConsumed"`    // 解析为数据的行数（头部和成功解析的数据组）
+	Groups         int            `json:"groups"`           // 数据组数（以"站名"行开始）
+	Items          int            `json:"items"`            // 成功解析的条目数
+	SkippedGroups  int            `json:"skippedGroups"`    // 格式错误被跳过的组数
+	Skipped        []SkippedGroup `json:"skipped"`          // 被跳过的组（最多 MaxReportDetails 个）
+	LeftoverLines  int            `json:"leftoverLines"`    // 无法识别的行数（不属于任何数据组或组内多余的行）
+	Leftover       []ReportLine   `json:"leftover"`         // 无法识别的行（最多 MaxReportDetails 行）
+	IrregularLines int            `json:"irregularLines"`   // 格式不规范但已容忍的行数（半角冒号、多余空白、空行）
+}
+
+// SkippedGroup 被跳过的数据组
+type SkippedGroup struct {
+	Line   int      `json:"line"`   // 起始行号（从 1 开始）
+	Reason string   `json:"reason"` // 跳过原因
+	Lines  []string `json:"lines"`  // 原文
+}
+
+// ReportLine 带行号的原文
+type ReportLine struct {
+	Line int    `json:"line"` // 行号（从 1 开始）
+	Text string `json:"text"` // 原文
+}
+
+// AddSkipped 记录一个被跳过的组（超出 MaxReportDetails 时只计数）
+func (r *ParseReport) AddSkipped(group SkippedGroup) {
+	r.SkippedGroups++
+	if len(r.Skipped) < MaxReportDetails {
+		r.Skipped = append(r.Skipped, group)
+	}
+}
+
+// AddLeftover 记录一行无法识别的内容（超出 MaxReportDetails 时只计数）
+func (r *ParseReport) AddLeftover(line ReportLine) {
+	r.LeftoverLines++
+	if len(r.Leftover) < MaxReportDetails {
+		r.Leftover = append(r.Leftover, line)
+	}
+}
+
+// Anomalies 异常数量（跳过的组、无法识别的行和不规范的行，严格模式下不允许）
+func (r *ParseReport) Anomalies() int {
+	return r.SkippedGroups + r.LeftoverLines + r.IrregularLines
+}
+
+// SkippedRatio 被跳过的数据比例（0-1）
+func (r *ParseReport) SkippedRatio() float64 {
+	if r == nil || r.Groups == 0 {
+		return 0
+	}
+	return float64(r.SkippedGroups) / float64(r.Groups)
+}
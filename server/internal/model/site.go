@@ -0,0 +1,94 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 站点数据验证错误
+const (
+	errSiteKeyEmpty       = "站点标识不能为空"
+	errSiteBaseURLEmpty   = "站点域名不能为空"
+	errSitesEmpty         = "站点列表不能为空"
+	errSiteValidateFailed = "第%d个站点验证失败"
+	errUpstreamFailed     = "IYUU返回错误（ret=%d）: %s"
+)
+
+// SitesRetOK IYUU 接口成功时的 ret 值
+const SitesRetOK = 200
+
+// Site 一个 IYUU 站点（字段与 App.Api.Sites 接口及前端读取的字段一致）
+type Site struct {
+	ID             int    `json:"id"`
+	Site           string `json:"site"`            // 站点标识（与 Top1000 的 siteName 对应）
+	Nickname       string `json:"nickname"`        // 站点显示名称
+	BaseURL        string `json:"base_url"`        // 站点域名（不含协议）
+	DownloadPage   string `json:"download_page"`   // 下载链接模板（{} 为种子ID）
+	DetailsPage    string `json:"details_page"`    // 详情链接模板（{} 为种子ID）
+	IsHTTPS        int    `json:"is_https"`        // 大于等于1时使用 https
+	CookieRequired int    `json:"cookie_required"` // 下载是否需要 cookie
+}
+
+// Validate 验证单个站点
+func (s *Site) Validate() error {
+	var errs ValidationErrors
+
+	if strings.TrimSpace(s.Site) == "" {
+		errs = append(errs, errSiteKeyEmpty)
+	}
+	if strings.TrimSpace(s.BaseURL) == "" {
+		errs = append(errs, errSiteBaseURLEmpty)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// SiteCatalog 站点目录
+type SiteCatalog struct {
+	Sites []Site `json:"sites"`
+}
+
+// Validate 验证站点目录（收集所有站点的错误）
+func (c *SiteCatalog) Validate() error {
+	var errs ValidationErrors
+
+	if len(c.Sites) == 0 {
+		errs = append(errs, errSitesEmpty)
+	}
+
+	for i, site := range c.Sites {
+		if err := site.Validate(); err != nil {
+			prefix := fmt.Sprintf(errSiteValidateFailed, i+1)
+			if ve, ok := err.(ValidationErrors); ok {
+				for _, e := range ve {
+					errs = append(errs, fmt.Sprintf("%s: %s", prefix, e))
+				}
+			} else {
+				errs = append(errs, fmt.Sprintf("%s: %s", prefix, err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// SitesResponse IYUU 接口响应信封（/sites.json 也以此格式返回，保持前端兼容）
+type SitesResponse struct {
+	Ret  int         `json:"ret"`
+	Data SiteCatalog `json:"data"`
+	Msg  string      `json:"msg"`
+}
+
+// Check 检查响应信封和站点数据（ret 非 200 时返回上游的错误信息）
+func (r *SitesResponse) Check() error {
+	if r.Ret != SitesRetOK {
+		return fmt.Errorf(errUpstreamFailed, r.Ret, r.Msg)
+	}
+	return r.Data.Validate()
+}
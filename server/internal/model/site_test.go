@@ -0,0 +1,98 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSiteCatalog_Validate(t *testing.T) {
+	validSite := Site{ID: 1, Site: "m-team", Nickname: "馒头", BaseURL: "m-team.cc", DetailsPage: "details.php?id={}", IsHTTPS: 2}
+
+	tests := []struct {
+		name    string
+		catalog SiteCatalog
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "有效站点",
+			catalog: SiteCatalog{Sites: []Site{validSite}},
+			wantErr: false,
+		},
+		{
+			name:    "站点列表为空",
+			catalog: SiteCatalog{},
+			wantErr: true,
+			errMsg:  "站点列表不能为空",
+		},
+		{
+			name:    "站点标识为空",
+			catalog: SiteCatalog{Sites: []Site{validSite, {BaseURL: "example.com"}}},
+			wantErr: true,
+			errMsg:  "第2个站点验证失败: 站点标识不能为空",
+		},
+		{
+			name:    "站点域名为空",
+			catalog: SiteCatalog{Sites: []Site{{Site: "test"}}},
+			wantErr: true,
+			errMsg:  "站点域名不能为空",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.catalog.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SiteCatalog.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && !contains(err.Error(), tt.errMsg) {
+				t.Errorf("SiteCatalog.Validate() error = %v, 期望包含 %v", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestSitesResponse_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "正常响应",
+			body:    `{"ret":200,"data":{"sites":[{"id":1,"site":"hdsky","nickname":"天空","base_url":"hdsky.me","download_page":"download.php?id={}&passkey={passkey}","details_page":"details.php?id={}","is_https":2,"cookie_required":0}]},"msg":""}`,
+			wantErr: false,
+		},
+		{
+			name:    "签名错误",
+			body:    `{"ret":401,"data":[],"msg":"签名无效"}`,
+			wantErr: true,
+			errMsg:  "签名无效",
+		},
+		{
+			name:    "站点列表为空",
+			body:    `{"ret":200,"data":{"sites":[]},"msg":""}`,
+			wantErr: true,
+			errMsg:  "站点列表不能为空",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp SitesResponse
+			// 错误响应的 data 可能不是对象，解析失败时仍需检查 ret
+			_ = json.Unmarshal([]byte(tt.body), &resp)
+
+			err := resp.Check()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SitesResponse.Check() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && !contains(err.Error(), tt.errMsg) {
+				t.Errorf("SitesResponse.Check() error = %v, 期望包含 %v", err, tt.errMsg)
+			}
+		})
+	}
+}
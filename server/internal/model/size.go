@@ -0,0 +1,74 @@
+package model
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 文件大小单位（十进制 KB/MB/... 按 1000 进位，二进制 KiB/MiB/... 按 1024 进位）
+var sizeUnits = map[string]float64{
+	"B":   1,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"PB":  1e15,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+	"PIB": 1 << 50,
+}
+
+// ParseSize 将 "1.23 TB"、"512MiB" 这类文件大小解析为字节数
+// 单位不区分大小写，数值与单位之间可以有空格；KB/MB/GB/TB/PB 按 SI 十进制单位解析，
+// 与 KiB/MiB 等二进制单位不同（前端 convertSizeToKb 只用于排序旧数据，不以它为准）
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+
+	// 数值部分：数字和小数点
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if end <= 0 {
+		return 0, errors.New(errSizeInvalid)
+	}
+
+	value, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil {
+		return 0, errors.New(errSizeInvalid)
+	}
+
+	multiplier, ok := sizeUnits[strings.ToUpper(strings.TrimSpace(s[end:]))]
+	if !ok {
+		return 0, errors.New(errSizeInvalid)
+	}
+
+	bytes := math.Round(value * multiplier)
+	if bytes > math.MaxInt64 {
+		return 0, errors.New(errSizeInvalid)
+	}
+	return int64(bytes), nil
+}
+
+// ParseDuplication 解析重复度（如 "85.5"）
+func ParseDuplication(s string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, errors.New(errDuplicationInvalid)
+	}
+	return value, nil
+}
+
+// ComputeValues 根据原始字符串计算 SizeBytes 和 DuplicationValue
+// 无法解析的字段保持为 0（由 Validate 报告格式错误）
+func (s *SiteItem) ComputeValues() {
+	if size, err := ParseSize(s.Size); err == nil {
+		s.SizeBytes = size
+	}
+	if duplication, err := ParseDuplication(s.Duplication); err == nil {
+		s.DuplicationValue = duplication
+	}
+}
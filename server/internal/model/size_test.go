@@ -0,0 +1,86 @@
+package model
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		// KB/MB/GB/TB/PB 为十进制单位，与 KiB/MiB 等二进制单位不同
+		{input: "1.2TB", want: 1_200_000_000_000},
+		{input: "1.23 TB", want: 1_230_000_000_000},
+		{input: "512MB", want: 512_000_000},
+		{input: "1 GB", want: 1_000_000_000},
+		{input: "1 GiB", want: 1 << 30},
+		{input: "1.5 GiB", want: 1_610_612_736},
+		{input: "2KB", want: 2000},
+		{input: "2KiB", want: 2048},
+		{input: "1 PB", want: 1_000_000_000_000_000},
+		{input: "1PiB", want: 1 << 50},
+		{input: "100 B", want: 100},
+		{input: "1.2tb", want: 1_200_000_000_000},
+		{input: " 3 MiB ", want: 3 << 20},
+		{input: "", wantErr: true},
+		{input: "TB", wantErr: true},
+		{input: "1.2", wantErr: true},
+		{input: "1.2 XB", wantErr: true},
+		{input: "1.2.3TB", wantErr: true},
+		{input: "-1TB", wantErr: true},
+		{input: "99999999 PiB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDuplication(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{input: "85.5", want: 85.5},
+		{input: " 3 ", want: 3},
+		{input: "abc", wantErr: true},
+		{input: "NaN", wantErr: true},
+		{input: "Inf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDuplication(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDuplication(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDuplication(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSiteItem_ComputeValues(t *testing.T) {
+	item := SiteItem{Duplication: "85.5", Size: "1.5 GiB"}
+	item.ComputeValues()
+	if item.DuplicationValue != 85.5 || item.SizeBytes != 1_610_612_736 {
+		t.Errorf("ComputeValues() = %v, %v", item.DuplicationValue, item.SizeBytes)
+	}
+
+	// 无法解析时保持原值
+	item = SiteItem{Duplication: "未知", Size: "未知", SizeBytes: 42}
+	item.ComputeValues()
+	if item.DuplicationValue != 0 || item.SizeBytes != 42 {
+		t.Errorf("ComputeValues() = %v, %v, want 0, 42", item.DuplicationValue, item.SizeBytes)
+	}
+}
@@ -1,12 +1,18 @@
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// DataTimeLayout 上游数据时间格式（不含时区，按 UPSTREAM_TIMEZONE 解释）
+const DataTimeLayout = "2006-01-02 15:04:05"
+
 // Go 1.26: 验证错误集合 - 收集所有验证错误而不是只返回第一个
 // 这个SB结构让调用者能看到所有问题，不是只看到第一个
 type ValidationErrors []string
@@ -32,12 +38,20 @@ var (
 )
 
 // SiteItem 一条站点数据
+// Duplication/Size 保留上游的原始文本，DuplicationValue/SizeBytes 为解析后的数值（便于排序和统计）
 type SiteItem struct {
-	SiteName    string `json:"siteName"`
-	SiteID      string `json:"siteid"`
-	Duplication string `json:"duplication"`
-	Size        string `json:"size"`
-	ID          int    `json:"id"`
+	SiteName         string  `json:"siteName"`
+	SiteID           string  `json:"siteid"`
+	Duplication      string  `json:"duplication"`
+	DuplicationValue float64 `json:"duplicationValue"`
+	Size             string  `json:"size"`
+	SizeBytes        int64   `json:"sizeBytes"` // 字节数（KB/MB/GB/TB 按 1000 进位，KiB/MiB 等按 1024 进位）
+	ID               int     `json:"id"`
+
+	// 以下字段只在请求 ?enrich=1 时按站点目录补充，不保存
+	Nickname    string `json:"nickname,omitempty"`    // 站点显示名称
+	DetailsURL  string `json:"detailsUrl,omitempty"`  // 种子详情链接
+	DownloadURL string `json:"downloadUrl,omitempty"` // 种子下载链接（不含 passkey，需登录后下载）
 }
 
 // Validate 验证单条数据正确性
@@ -56,13 +70,15 @@ func (s *SiteItem) Validate() error {
 	}
 
 	if s.Duplication != "" {
-		if _, err := strconv.ParseFloat(s.Duplication, 64); err != nil {
+		if _, err := ParseDuplication(s.Duplication); err != nil {
 			errs = append(errs, fmt.Sprintf("%s: %s", errDuplicationInvalid, s.Duplication))
 		}
 	}
 
-	if s.Size != "" && !sizePattern.MatchString(s.Size) {
-		errs = append(errs, fmt.Sprintf("%s: %s", errSizeInvalid, s.Size))
+	if s.Size != "" {
+		if _, err := ParseSize(s.Size); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", errSizeInvalid, s.Size))
+		}
 	}
 
 	if s.ID <= 0 {
@@ -77,8 +93,60 @@ func (s *SiteItem) Validate() error {
 
 // ProcessedData 完整的Top1000数据
 type ProcessedData struct {
-	Time  string     `json:"time"`
-	Items []SiteItem `json:"items"`
+	Time          string     `json:"time"`                   // 上游原始时间文本（兼容旧客户端）
+	CreatedAt     time.Time  `json:"createdAt,omitzero"`     // 数据生成时间（RFC 3339，带时区）
+	CreatedAtUnix int64      `json:"createdAtUnix,omitzero"` // 数据生成时间（Unix 秒）
+	LastCheckedAt time.Time  `json:"lastCheckedAt,omitzero"` // 最近一次确认上游数据未变化的时间（由存储层维护）
+	Items         []SiteItem `json:"items"`
+
+	// Report 解析报告（不在数据中序列化，由存储层随快照单独保存）
+	Report *ParseReport `json:"-"`
+
+	// Raw 上游原始内容（不在数据中序列化，爬取后由存储层单独保存）
+	Raw *RawPayload `json:"-"`
+}
+
+// ParseTime 按上游时区解析 Time，填充 CreatedAt 和 CreatedAtUnix
+func (p *ProcessedData) ParseTime(loc *time.Location) error {
+	at, err := parseDataTime(p.Time, loc)
+	if err != nil {
+		return err
+	}
+
+	p.CreatedAt = at
+	p.CreatedAtUnix = at.Unix()
+	return nil
+}
+
+// CreatedTime 返回数据生成时间
+// 旧版本保存的数据没有 CreatedAt，按上游时区解析 Time
+func (p *ProcessedData) CreatedTime(loc *time.Location) (time.Time, error) {
+	if !p.CreatedAt.IsZero() {
+		return p.CreatedAt, nil
+	}
+
+	return parseDataTime(p.Time, loc)
+}
+
+// ContentHash 计算数据内容的哈希（SHA-256，十六进制）
+// 只包含上游原始字段（time 和各条目的原始文本），派生字段和存储元信息不影响结果，
+// 旧版本保存的数据与重新解析的同一份数据哈希相同
+func (p *ProcessedData) ContentHash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", p.Time)
+	for _, item := range p.Items {
+		fmt.Fprintf(h, "%d\x1f%s\x1f%s\x1f%s\x1f%s\n", item.ID, item.SiteID, item.SiteName, item.Duplication, item.Size)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// parseDataTime 按指定时区解析上游时间文本
+func parseDataTime(value string, loc *time.Location) (time.Time, error) {
+	at, err := time.ParseInLocation(DataTimeLayout, strings.TrimSpace(value), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %s", errTimeInvalid, value)
+	}
+	return at, nil
 }
 
 // Validate 验证完整数据
@@ -2,6 +2,7 @@ package model
 
 import (
 	"testing"
+	"time"
 )
 
 func TestSiteItem_Validate(t *testing.T) {
@@ -323,6 +324,103 @@ func TestProcessedData_Validate(t *testing.T) {
 	}
 }
 
+func TestProcessedData_ParseTime(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2026-01-19 07:50:56 +08:00
+	want := time.Date(2026, 1, 18, 23, 50, 56, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		time    string
+		loc     *time.Location
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "上海时区", time: "2026-01-19 07:50:56", loc: shanghai, want: want},
+		{name: "UTC", time: "2026-01-18 23:50:56", loc: time.UTC, want: want},
+		{name: "首尾空格", time: " 2026-01-19 07:50:56 ", loc: shanghai, want: want},
+		{name: "格式错误", time: "2026/01/19", loc: shanghai, wantErr: true},
+		{name: "空时间", time: "", loc: shanghai, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := ProcessedData{Time: tt.time}
+			err := data.ParseTime(tt.loc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !data.CreatedAt.Equal(tt.want) || data.CreatedAtUnix != tt.want.Unix() {
+				t.Errorf("ParseTime() = %v (%d), want %v (%d)", data.CreatedAt, data.CreatedAtUnix, tt.want, tt.want.Unix())
+			}
+		})
+	}
+}
+
+func TestProcessedData_CreatedTime(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("优先使用 CreatedAt", func(t *testing.T) {
+		createdAt := time.Date(2026, 1, 18, 23, 50, 56, 0, time.UTC)
+		data := ProcessedData{Time: "无效时间", CreatedAt: createdAt}
+		got, err := data.CreatedTime(shanghai)
+		if err != nil || !got.Equal(createdAt) {
+			t.Errorf("CreatedTime() = %v, %v, want %v", got, err, createdAt)
+		}
+	})
+
+	t.Run("旧数据按时区解析 Time", func(t *testing.T) {
+		data := ProcessedData{Time: "2026-01-19 07:50:56"}
+		got, err := data.CreatedTime(shanghai)
+		want := time.Date(2026, 1, 18, 23, 50, 56, 0, time.UTC)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("CreatedTime() = %v, %v, want %v", got, err, want)
+		}
+	})
+}
+
+func TestProcessedData_ContentHash(t *testing.T) {
+	base := ProcessedData{
+		Time:  "2026-01-19 07:50:56",
+		Items: []SiteItem{{SiteName: "测试站点", SiteID: "123", Duplication: "85.5", Size: "1.2TB", ID: 1}},
+	}
+	clone := func(modify func(*ProcessedData)) ProcessedData {
+		data := base
+		data.Items = append([]SiteItem(nil), base.Items...)
+		modify(&data)
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data ProcessedData
+		same bool
+	}{
+		{name: "派生字段不影响", data: clone(func(p *ProcessedData) { p.Items[0].ComputeValues(); _ = p.ParseTime(time.UTC) }), same: true},
+		{name: "检查时间不影响", data: clone(func(p *ProcessedData) { p.LastCheckedAt = time.Now() }), same: true},
+		{name: "时间变化", data: clone(func(p *ProcessedData) { p.Time = "2026-01-20 07:50:56" }), same: false},
+		{name: "条目变化", data: clone(func(p *ProcessedData) { p.Items[0].Size = "1.3TB" }), same: false},
+		{name: "条目增加", data: clone(func(p *ProcessedData) { p.Items = append(p.Items, SiteItem{SiteName: "站点2", SiteID: "2", ID: 2}) }), same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.data.ContentHash() == base.ContentHash(); got != tt.same {
+				t.Errorf("ContentHash() 相同 = %v, want %v", got, tt.same)
+			}
+		})
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && findSubstring(s, substr))
 }
@@ -0,0 +1,109 @@
+// Package reparse 用当前解析器重新解析保存的上游原始内容，改写对应的历史快照
+// 解析器修复后（例如上游格式变化导致部分数据被跳过），可以据此修复已保存的历史数据；
+// 只改写已有的快照，不会新建快照或替换为其他时间的数据（这些数据没有经过异常检测）
+package reparse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"top1000/internal/crawler"
+	"top1000/internal/storage"
+)
+
+const logPrefix = "reparse"
+
+// 重新解析结果状态
+const (
+	StatusRewritten = "rewritten" // 已改写快照
+	StatusPending   = "pending"   // 需要改写（dry run）
+	StatusUnchanged = "unchanged" // 解析结果与快照相同
+	StatusDuplicate = "duplicate" // 同一数据时间已由更新的原始内容处理
+	StatusMissing   = "missing"   // 没有对应时间的快照
+	StatusFailed    = "failed"    // 解析或改写失败
+)
+
+// Result 一份原始内容的重新解析结果
+type Result struct {
+	ID        string    `json:"id"`              // 原始内容记录 ID
+	Source    string    `json:"source"`          // 数据源
+	FetchedAt time.Time `json:"fetchedAt"`       // 获取时间
+	Time      string    `json:"time,omitempty"`  // 解析出的数据时间
+	Items     int       `json:"items"`           // 解析出的条目数
+	Status    string    `json:"status"`          // 处理结果
+	Error     string    `json:"error,omitempty"` // 失败原因
+}
+
+// Run 按获取时间倒序重新解析所有保存的原始内容，改写同一数据时间的快照
+// 同一数据时间只采用最新的原始内容；dryRun 为 true 时只检查不修改。
+// 单份内容解析失败不会中断，只有读取存储失败时返回错误
+func Run(ctx context.Context, raws storage.RawStore, history storage.HistoryStore, dryRun bool) ([]Result, error) {
+	metas, err := raws.ListRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("列出原始内容失败: %w", err)
+	}
+
+	results := make([]Result, 0, len(metas))
+	seen := make(map[string]bool, len(metas))
+	for _, meta := range metas {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result := Result{ID: meta.ID, Source: meta.Source, FetchedAt: meta.FetchedAt}
+		if err := reparseOne(ctx, raws, history, seen, dryRun, &result); err != nil {
+			result.Status, result.Error = StatusFailed, err.Error()
+			log.Printf("[%s] %s 处理失败: %v", logPrefix, meta.ID, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// reparseOne 重新解析一份原始内容，处理状态、数据时间和条目数写入 result
+// seen 记录已处理的数据时间
+func reparseOne(ctx context.Context, raws storage.RawStore, history storage.HistoryStore, seen map[string]bool, dryRun bool, result *Result) error {
+	raw, err := raws.LoadRaw(ctx, result.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := crawler.Parse(raw.Source, raw.Body)
+	if err != nil {
+		return err
+	}
+	result.Time, result.Items = data.Time, len(data.Items)
+
+	if seen[data.Time] {
+		result.Status = StatusDuplicate
+		return nil
+	}
+	seen[data.Time] = true
+
+	// LoadSnapshot 没有精确匹配时返回更早的快照，这里只改写同一时间的快照
+	snapshot, err := history.LoadSnapshot(ctx, data.Time)
+	if errors.Is(err, storage.ErrSnapshotNotFound) || (err == nil && snapshot.Time != data.Time) {
+		result.Status = StatusMissing
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case snapshot.ContentHash() == data.ContentHash():
+		result.Status = StatusUnchanged
+	case dryRun:
+		result.Status = StatusPending
+	default:
+		if err := history.RewriteSnapshot(ctx, *data); err != nil {
+			return err
+		}
+		result.Status = StatusRewritten
+		log.Printf("[%s] 已改写快照 %s（%d -> %d 条）", logPrefix, data.Time, len(snapshot.Items), len(data.Items))
+	}
+	return nil
+}
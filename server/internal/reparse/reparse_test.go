@@ -0,0 +1,99 @@
+package reparse
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"top1000/internal/model"
+	"top1000/internal/storage"
+)
+
+// rawText 生成指定时间和站点数的上游原始文本
+func rawText(dataTime string, sites ...string) string {
+	text := "create time " + dataTime + " by xxx\n"
+	for i, site := range sites {
+		text += "\n站名：" + site + " 【ID：" + strconv.Itoa(i+1) + "】\n重复度：1\n文件大小：1TB\n"
+	}
+	return text
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+
+	// 旧解析器只解析出第一个站点的快照
+	broken := model.ProcessedData{
+		Time:  "2026-01-18 07:50:56",
+		Items: []model.SiteItem{{SiteName: "站点A", SiteID: "1", Duplication: "1", Size: "1TB", ID: 1}},
+	}
+	if err := store.SaveData(ctx, broken); err != nil {
+		t.Fatalf("SaveData() error = %v", err)
+	}
+	unchanged := model.ProcessedData{
+		Time:  "2026-01-19 07:50:56",
+		Items: []model.SiteItem{{SiteName: "站点A", SiteID: "1", Duplication: "1", Size: "1TB", ID: 1}},
+	}
+	if err := store.SaveData(ctx, unchanged); err != nil {
+		t.Fatalf("SaveData() error = %v", err)
+	}
+
+	start := time.Now()
+	raws := []string{
+		rawText(broken.Time, "站点A", "站点B"), // 较早获取，被更新的同一时间内容取代
+		rawText(broken.Time, "站点A", "站点B", "站点C"),
+		rawText(unchanged.Time, "站点A"),
+		rawText("2026-01-20 07:50:56", "站点A"), // 没有对应的快照（例如当时被异常检测拒绝）
+		"<html>502 Bad Gateway</html>",
+	}
+	for i, body := range raws {
+		raw := model.NewRawPayload("test", []byte(body), start.Add(time.Duration(i)*time.Second))
+		if err := store.SaveRaw(ctx, *raw); err != nil {
+			t.Fatalf("SaveRaw() error = %v", err)
+		}
+	}
+
+	// 最新在前
+	want := []string{StatusFailed, StatusMissing, StatusUnchanged, StatusPending, StatusDuplicate}
+
+	results, err := Run(ctx, store, store, true)
+	if err != nil {
+		t.Fatalf("Run(dryRun) error = %v", err)
+	}
+	assertStatuses(t, results, want)
+	if snapshot, _ := store.LoadSnapshot(ctx, broken.Time); len(snapshot.Items) != 1 {
+		t.Errorf("dry run 不应改写快照: %+v", snapshot)
+	}
+
+	want[3] = StatusRewritten
+	results, err = Run(ctx, store, store, false)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	assertStatuses(t, results, want)
+	if results[0].Error == "" || results[3].Items != 3 {
+		t.Errorf("Run() = %+v", results)
+	}
+
+	snapshot, err := store.LoadSnapshot(ctx, broken.Time)
+	if err != nil || len(snapshot.Items) != 3 || snapshot.Report == nil {
+		t.Errorf("LoadSnapshot() = %+v, %v，期望改写为 3 条", snapshot, err)
+	}
+	if metas, _ := store.ListSnapshots(ctx); len(metas) != 2 {
+		t.Errorf("ListSnapshots() = %+v，不应新建快照", metas)
+	}
+}
+
+// assertStatuses 检查每份原始内容的处理状态
+func assertStatuses(t *testing.T, results []Result, want []string) {
+	t.Helper()
+	if len(results) != len(want) {
+		t.Fatalf("Run() 返回 %d 条结果，期望 %d 条: %+v", len(results), len(want), results)
+	}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("第 %d 条（%s）状态 = %s, want %s（%s）", i+1, result.Time, result.Status, want[i], result.Error)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -16,7 +17,9 @@ import (
 	"top1000/internal/api"
 	"top1000/internal/config"
 	"top1000/internal/crawler"
+	"top1000/internal/siterules"
 	"top1000/internal/storage"
+	"top1000/internal/upstream"
 
 	docs "top1000/docs" // Swagger docs
 
@@ -33,12 +36,17 @@ const (
 	separatorLength  = 40
 	// 默认优雅关闭超时时间
 	defaultShutdownTimeout = 30 * time.Second
+	// 后台刷新任务名称
+	dataUpdateTask  = "Top1000"
+	sitesUpdateTask = "Sites"
 )
 
 // Server 服务器结构体（保持状态，方便测试和优雅关闭）
 type Server struct {
 	app         *fiber.App
 	cfg         *config.Config
+	handler     *api.Handler
+	scheduler   *crawler.Scheduler
 	shutdownCtx context.Context
 	cancel      context.CancelFunc
 }
@@ -71,14 +79,21 @@ func (s *Server) Start(ctx context.Context) error {
 
 	// 初始化存储
 	if err := s.initStorage(); err != nil {
-		return fmt.Errorf("Redis初始化失败: %w", err)
+		return fmt.Errorf("存储初始化失败: %w", err)
+	}
+
+	// 加载站点链接规则（在创建 Handler 之前）
+	if err := siterules.Init(); err != nil {
+		return fmt.Errorf("站点链接规则加载失败: %w", err)
 	}
 
 	// 创建应用
 	s.app = s.createApp()
 
-	// 预加载数据
-	s.preloadData()
+	// 启动后台刷新（立即执行一轮，替代启动时的同步预加载）
+	if err := s.startScheduler(); err != nil {
+		return fmt.Errorf("刷新调度器启动失败: %w", err)
+	}
 
 	// 打印启动信息
 	s.printStartupInfo()
@@ -128,8 +143,21 @@ func (s *Server) shutdownWithTimeout(ctx context.Context) error {
 		}
 	}
 
-	// 关闭 Redis 连接
-	s.closeRedis()
+	// 等待正在执行的刷新任务结束后再关闭存储
+	if s.scheduler != nil {
+		if err := s.scheduler.Stop(shutdownCtx); err != nil {
+			log.Printf("刷新调度器关闭失败: %v", err)
+		}
+	}
+	// 调度器和过期请求触发的刷新都在 Handler 中执行，取消并等待它们结束
+	if s.handler != nil {
+		if err := s.handler.Close(shutdownCtx); err != nil {
+			log.Printf("后台刷新关闭失败: %v", err)
+		}
+	}
+
+	// 关闭存储连接
+	s.closeStorage()
 
 	log.Println("服务已安全关闭")
 	return nil
@@ -243,13 +271,17 @@ func securityHeadersMiddleware() fiber.Handler {
 
 // setupRoutes 配置路由
 func (s *Server) setupRoutes(app *fiber.App) {
-	handler := api.NewHandler(
+	s.handler = api.NewHandler(
 		storage.GetDefaultStore(),
 		storage.GetDefaultSitesStore(),
+		storage.GetDefaultHistoryStore(),
+		storage.GetDefaultQuarantineStore(),
+		storage.GetDefaultRawStore(),
+		storage.GetDefaultCredentialStore(),
 		storage.GetDefaultLock(),
 	)
 
-	handler.RegisterRoutes(app)
+	s.handler.RegisterRoutes(app)
 
 	app.Get("/swagger/*", swaggerUI)
 	app.Get("/swagger/doc.json", swaggerJSON)
@@ -328,26 +360,34 @@ func setCacheHeaders(c *fiber.Ctx) error {
 	return nil
 }
 
-// initStorage 初始化Redis连接
+// initStorage 初始化存储后端
 func (s *Server) initStorage() error {
-	log.Println("正在初始化Redis连接...")
-	if err := storage.InitRedis(); err != nil {
+	log.Printf("正在初始化存储（%s）...", s.storageBackend())
+	if err := storage.Init(); err != nil {
 		return err
 	}
-	log.Println("Redis初始化成功")
+	log.Println("存储初始化成功")
 	return nil
 }
 
-// closeRedis 关闭Redis连接
-func (s *Server) closeRedis() {
-	log.Println("正在关闭Redis连接...")
-	if err := storage.CloseRedis(); err != nil {
-		log.Printf("关闭Redis连接失败: %v", err)
+// closeStorage 关闭存储连接
+func (s *Server) closeStorage() {
+	log.Println("正在关闭存储连接...")
+	if err := storage.Close(); err != nil {
+		log.Printf("关闭存储连接失败: %v", err)
 	} else {
-		log.Println("Redis连接已关闭")
+		log.Println("存储连接已关闭")
 	}
 }
 
+// storageBackend 返回当前存储后端名称
+func (s *Server) storageBackend() string {
+	if s.cfg.StorageBackend == "" {
+		return config.DefaultStorageBackend
+	}
+	return s.cfg.StorageBackend
+}
+
 // printSeparator 打印分隔线
 func printSeparator() {
 	log.Println(strings.Repeat("=", separatorLength))
@@ -357,18 +397,72 @@ func printSeparator() {
 func (s *Server) printStartupInfo() {
 	printSeparator()
 	log.Printf("服务已启动，监听端口: %s", config.DefaultPort)
-	log.Printf("存储方式: Redis (%s)", s.cfg.RedisAddr)
-	log.Println("数据更新策略: 过期自动更新（容错机制）")
+	switch s.storageBackend() {
+	case config.StorageBackendMemory:
+		log.Println("存储方式: 内存（重启后数据丢失）")
+	case config.StorageBackendFile:
+		log.Printf("存储方式: 文件 (%s)", s.cfg.DataDir)
+	default:
+		log.Printf("存储方式: Redis %s", redisEndpoint(s.cfg))
+	}
+	if s.cfg.RefreshCron != "" {
+		log.Printf("数据更新策略: 后台定时刷新（cron: %s，抖动: %v）", s.cfg.RefreshCron, s.cfg.RefreshJitter)
+	} else {
+		log.Printf("数据更新策略: 后台定时刷新（每 %v，抖动: %v）", s.cfg.RefreshInterval, s.cfg.RefreshJitter)
+	}
+	log.Printf("数据源: %s", crawler.DefaultSource().Name())
+	log.Printf("出站代理: %s", upstream.ProxyName(s.cfg))
+	switch s.cfg.UpstreamMode {
+	case config.UpstreamModeRecord:
+		log.Printf("上游模式: 录制（%s）", s.cfg.FixturesDir)
+	case config.UpstreamModeReplay:
+		log.Printf("上游模式: 回放（%s，不访问网络）", s.cfg.FixturesDir)
+	}
+	if s.cfg.AdminToken != "" {
+		log.Println("管理接口: 已启用（/api/admin/*）")
+	}
+	if users := len(s.cfg.APITokenUsers()); users > 0 {
+		log.Printf("用户凭据: 已启用（%d 个用户，/api/credentials）", users)
+	}
+	if s.cfg.SiteRulesFile != "" {
+		log.Printf("站点链接规则: %s（%d 条）", s.cfg.SiteRulesFile, siterules.Default().Len())
+	}
 	log.Println("安全措施: 速率限制、安全响应头")
 	log.Println("优雅关闭: 已启用（SIGINT/SIGTERM）")
 	printSeparator()
 }
 
-// preloadData 启动时预加载数据
-func (s *Server) preloadData() {
-	printSeparator()
-	crawler.PreloadData()
-	printSeparator()
+// redisEndpoint 描述 Redis 连接目标（不输出 REDIS_URL 中的密码）
+func redisEndpoint(cfg *config.Config) string {
+	switch cfg.RedisMode() {
+	case config.RedisModeSentinel:
+		return fmt.Sprintf("哨兵 (%s @ %s)", cfg.RedisSentinelMaster, strings.Join(cfg.RedisSentinelAddrs, ","))
+	case config.RedisModeCluster:
+		return fmt.Sprintf("集群 (%s)", strings.Join(cfg.RedisClusterAddrs, ","))
+	}
+
+	addr := cfg.RedisAddr
+	if cfg.RedisURL != "" {
+		if u, err := url.Parse(cfg.RedisURL); err == nil {
+			addr = u.Scheme + "://" + u.Host
+		}
+	}
+	return fmt.Sprintf("(%s)", addr)
+}
+
+// startScheduler 启动后台刷新调度器（随 shutdownCtx 取消而停止）
+func (s *Server) startScheduler() error {
+	schedule, err := crawler.ParseSchedule(s.cfg.RefreshInterval, s.cfg.RefreshCron)
+	if err != nil {
+		return err
+	}
+
+	s.scheduler = crawler.NewScheduler(schedule, s.cfg.RefreshJitter,
+		crawler.Task{Name: dataUpdateTask, Run: s.handler.RefreshData},
+		crawler.Task{Name: sitesUpdateTask, Run: s.handler.RefreshSitesData},
+	)
+	s.scheduler.Start(s.shutdownCtx)
+	return nil
 }
 
 // ===== 以下为兼容性函数（保持向后兼容） =====
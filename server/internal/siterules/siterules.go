@@ -0,0 +1,250 @@
+// Package siterules 把 Top1000 的 siteName 对应到 IYUU 站点目录，并生成种子详情和下载链接
+// IYUU 目录中的数据不能全部直接使用（例如 m-team 的域名、下载链接中的 passkey 参数），
+// 内置规则处理已知的情况，SITE_RULES_FILE 可以按站点覆盖或补充
+package siterules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+// Rule 一个站点的链接规则（字段未设置时使用站点目录中的值）
+type Rule struct {
+	Site     string   `json:"site"`               // 站点目录中的站点标识
+	Aliases  []string `json:"aliases,omitempty"`  // Top1000 中对应这个站点的其他 siteName
+	BaseURL  string   `json:"baseUrl,omitempty"`  // 站点地址（含协议，例如 https://kp.m-team.cc）
+	Details  *string  `json:"details,omitempty"`  // 详情链接模板（空字符串表示不生成）
+	Download *string  `json:"download,omitempty"` // 下载链接模板（空字符串表示不生成）
+}
+
+// File 规则文件格式
+type File struct {
+	Rules []Rule `json:"rules"`
+}
+
+// builtinRules 内置规则（规则文件中同一站点的规则整条替换内置规则）
+var builtinRules = []Rule{
+	// IYUU 目录中的 m-team.cc 不是站点实际地址，下载接口需要 API 令牌
+	{Site: "m-team", BaseURL: "https://kp.m-team.cc", Download: new("")},
+}
+
+// defaultRuleSet 默认规则集（Init 之前只包含内置规则）
+var defaultRuleSet = Builtin()
+
+// Init 按 SITE_RULES_FILE 加载默认规则集（未设置时只使用内置规则）
+func Init() error {
+	path := config.Get().SiteRulesFile
+	if path == "" {
+		defaultRuleSet = Builtin()
+		return nil
+	}
+
+	set, err := Load(path)
+	if err != nil {
+		return err
+	}
+	defaultRuleSet = set
+	return nil
+}
+
+// Default 获取默认规则集
+func Default() *RuleSet {
+	return defaultRuleSet
+}
+
+// RuleSet 站点链接规则集（创建后只读，可并发使用）
+type RuleSet struct {
+	rules   map[string]Rule   // 站点标识 -> 规则
+	aliases map[string]string // siteName -> 站点标识
+}
+
+// New 创建规则集（规则无效时返回所有错误）
+func New(rules []Rule) (*RuleSet, error) {
+	set := &RuleSet{
+		rules:   make(map[string]Rule, len(rules)),
+		aliases: make(map[string]string),
+	}
+
+	var errs model.ValidationErrors
+	for i, rule := range rules {
+		prefix := fmt.Sprintf("第%d条规则（%s）", i+1, rule.Site)
+		for _, problem := range rule.validate() {
+			errs = append(errs, prefix+": "+problem)
+		}
+		if _, ok := set.rules[rule.Site]; ok {
+			errs = append(errs, prefix+": 站点重复")
+		}
+		set.rules[rule.Site] = rule
+
+		for _, alias := range rule.Aliases {
+			if site, ok := set.aliases[alias]; ok && site != rule.Site {
+				errs = append(errs, fmt.Sprintf("%s: 别名 %s 已属于 %s", prefix, alias, site))
+			}
+			set.aliases[alias] = rule.Site
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return set, nil
+}
+
+// Builtin 只包含内置规则的规则集
+func Builtin() *RuleSet {
+	set, err := New(builtinRules)
+	if err != nil {
+		panic(fmt.Sprintf("内置站点规则无效: %v", err))
+	}
+	return set
+}
+
+// Load 读取规则文件，与内置规则合并（同一站点以文件为准）
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取站点规则文件失败: %w", err)
+	}
+
+	var file File
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("解析站点规则文件失败: %w", err)
+	}
+
+	rules := file.Rules
+	for _, builtin := range builtinRules {
+		if !containsSite(file.Rules, builtin.Site) {
+			rules = append(rules, builtin)
+		}
+	}
+
+	set, err := New(rules)
+	if err != nil {
+		return nil, fmt.Errorf("站点规则文件无效: %w", err)
+	}
+	return set, nil
+}
+
+// containsSite 规则列表中是否有指定站点的规则
+func containsSite(rules []Rule, site string) bool {
+	for _, rule := range rules {
+		if rule.Site == site {
+			return true
+		}
+	}
+	return false
+}
+
+// validate 检查单条规则，返回问题列表
+func (r *Rule) validate() []string {
+	var problems []string
+
+	if strings.TrimSpace(r.Site) == "" {
+		problems = append(problems, "站点标识不能为空")
+	}
+	for _, alias := range r.Aliases {
+		if strings.TrimSpace(alias) == "" {
+			problems = append(problems, "别名不能为空")
+		}
+	}
+	if r.BaseURL != "" {
+		if u, err := url.Parse(r.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, "baseUrl 需要 http:// 或 https:// 开头的地址: "+r.BaseURL)
+		}
+	}
+	for _, template := range []struct {
+		name  string
+		value *string
+	}{{"details", r.Details}, {"download", r.Download}} {
+		if template.value == nil {
+			continue
+		}
+		if unknown := unknownPlaceholders(*template.value); len(unknown) > 0 {
+			problems = append(problems, fmt.Sprintf("%s 模板包含未知占位符 %s", template.name, strings.Join(unknown, ", ")))
+		}
+	}
+	return problems
+}
+
+// Len 规则数量
+func (s *RuleSet) Len() int {
+	return len(s.rules)
+}
+
+// Lookup 查找 siteName 对应的站点（先按别名转换为站点标识，站点目录中没有时 ok 为 false）
+func (s *RuleSet) Lookup(sites map[string]*model.Site, siteName string) (*model.Site, bool) {
+	key := siteName
+	if site, ok := s.aliases[siteName]; ok {
+		key = site
+	}
+	site, ok := sites[key]
+	return site, ok
+}
+
+// DetailsURL 种子详情链接（没有模板或无法生成时为空）
+func (s *RuleSet) DetailsURL(site *model.Site, torrentID string) string {
+	rule := s.rules[site.Site]
+	return link(origin(site, rule), pick(rule.Details, site.DetailsPage), torrentID, nil)
+}
+
+// DownloadURL 种子下载链接（没有模板或无法生成时为空）
+// passkey 等凭据用 credentials 填充，没有的凭据参数被去掉（需登录后下载）。
+// 站点目录中的模板只有 download.php 去掉凭据后仍可用，其他模板（Gazelle、API 接口等）
+// 需要用户保存了全部所需凭据；规则中的模板由运维负责，不受此限制
+func (s *RuleSet) DownloadURL(site *model.Site, torrentID string, credentials model.SiteCredentials) string {
+	rule := s.rules[site.Site]
+	if rule.Download == nil && !downloadable(site.DownloadPage, credentials) {
+		return ""
+	}
+	return link(origin(site, rule), pick(rule.Download, site.DownloadPage), torrentID, credentials)
+}
+
+// Enrich 返回补充了站点名称和链接的数据副本（站点目录中没有的站点保持不变）
+// credentials 为请求用户的站点凭据（匿名请求为 nil），下载链接按其填充
+func (s *RuleSet) Enrich(data *model.ProcessedData, catalog *model.SiteCatalog, credentials model.UserCredentials) *model.ProcessedData {
+	sites := make(map[string]*model.Site, len(catalog.Sites))
+	for i := range catalog.Sites {
+		sites[catalog.Sites[i].Site] = &catalog.Sites[i]
+	}
+
+	enriched := *data
+	enriched.Items = make([]model.SiteItem, len(data.Items))
+	for i, item := range data.Items {
+		if site, ok := s.Lookup(sites, item.SiteName); ok {
+			item.Nickname = site.Nickname
+			item.DetailsURL = s.DetailsURL(site, item.SiteID)
+			item.DownloadURL = s.DownloadURL(site, item.SiteID, credentials[site.Site])
+		}
+		enriched.Items[i] = item
+	}
+	return &enriched
+}
+
+// pick 规则设置了模板时使用规则，否则使用站点目录中的模板
+func pick(override *string, fallback string) string {
+	if override != nil {
+		return *override
+	}
+	return fallback
+}
+
+// origin 站点地址（协议和域名，不含末尾的 /）
+func origin(site *model.Site, rule Rule) string {
+	if rule.BaseURL != "" {
+		return strings.TrimRight(rule.BaseURL, "/")
+	}
+	scheme := "http"
+	if site.IsHTTPS >= 1 {
+		scheme = "https"
+	}
+	return scheme + "://" + strings.TrimRight(site.BaseURL, "/")
+}
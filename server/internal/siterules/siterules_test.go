@@ -0,0 +1,251 @@
+package siterules
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"top1000/internal/model"
+)
+
+func TestLink(t *testing.T) {
+	const origin = "https://test.org"
+	credentials := model.SiteCredentials{"passkey": "pk&1", "uid": "7", "hash": "h", "authkey": "ak", "torrent_pass": "tp", "downHash": "dh/1"}
+	tests := []struct {
+		name        string
+		template    string
+		credentials model.SiteCredentials
+		want        string
+	}{
+		{name: "种子ID在查询参数中", template: "details.php?id={}&hit=1", want: "https://test.org/details.php?id=42&hit=1"},
+		{name: "种子ID在路径中", template: "detail/{}", want: "https://test.org/detail/42"},
+		{name: "种子ID出现多次", template: "t/{}/download?id={}", want: "https://test.org/t/42/download?id=42"},
+		{name: "去掉 passkey", template: "download.php?id={}&passkey={passkey}", want: "https://test.org/download.php?id=42"},
+		{name: "去掉 downHash", template: "download.php?id={}&downhash={downHash}", want: "https://test.org/download.php?id=42"},
+		{name: "去掉 uid 和 hash", template: "download.php?id={}&uid={uid}&hash={hash}", want: "https://test.org/download.php?id=42"},
+		{name: "去掉 Gazelle 凭据", template: "torrents.php?action=download&id={}&authkey={authkey}&torrent_pass={torrent_pass}", want: "https://test.org/torrents.php?action=download&id=42"},
+		{name: "凭据参数在种子ID之前", template: "download.php?passkey={passkey}&id={}", want: "https://test.org/download.php?id=42"},
+		{name: "只剩路径", template: "download/{}?passkey={passkey}", want: "https://test.org/download/42"},
+		{name: "凭据在路径中", template: "rss/{passkey}/{}", want: ""},
+		{name: "未知占位符", template: "download.php?id={}&key={rsskey}", want: ""},
+		{name: "开头的斜杠", template: "/details.php?id={}", want: "https://test.org/details.php?id=42"},
+		{name: "完整地址", template: "https://cdn.test.org/t/{}", want: "https://cdn.test.org/t/42"},
+		{name: "空模板", template: "", want: ""},
+		{name: "填充 passkey", template: "download.php?id={}&passkey={passkey}", credentials: credentials, want: "https://test.org/download.php?id=42&passkey=pk%261"},
+		{name: "填充 downHash", template: "download.php?id={}&downhash={downHash}", credentials: credentials, want: "https://test.org/download.php?id=42&downhash=dh%2F1"},
+		{name: "填充 uid 和 hash", template: "download.php?id={}&uid={uid}&hash={hash}", credentials: credentials, want: "https://test.org/download.php?id=42&uid=7&hash=h"},
+		{name: "填充 Gazelle 凭据", template: "torrents.php?action=download&id={}&authkey={authkey}&torrent_pass={torrent_pass}", credentials: credentials, want: "https://test.org/torrents.php?action=download&id=42&authkey=ak&torrent_pass=tp"},
+		{name: "填充路径中的凭据", template: "rss/{passkey}/{}", credentials: credentials, want: "https://test.org/rss/pk&1/42"},
+		{name: "只填充已保存的凭据", template: "download.php?id={}&passkey={passkey}&downhash={downHash}", credentials: model.SiteCredentials{"passkey": "pk"}, want: "https://test.org/download.php?id=42&passkey=pk"},
+		{name: "忽略未知的凭据名", template: "download.php?id={}&key={rsskey}", credentials: model.SiteCredentials{"rsskey": "x"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := link(origin, tt.template, "42", tt.credentials); got != tt.want {
+				t.Errorf("link(%q) = %v, want %v", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleSet_URLs(t *testing.T) {
+	custom, err := New(append(slices.Clone(builtinRules),
+		Rule{Site: "hdcity", BaseURL: "https://hdcity.city/", Details: new("t-{}"), Download: new("download?id={}&passkey={passkey}")},
+	))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		site         model.Site
+		wantDetails  string
+		wantDownload string
+	}{
+		{
+			name:         "https 站点去掉凭据参数",
+			site:         model.Site{Site: "hdsky", BaseURL: "hdsky.me", DetailsPage: "details.php?id={}", DownloadPage: "download.php?id={}&passkey={passkey}", IsHTTPS: 2},
+			wantDetails:  "https://hdsky.me/details.php?id=42",
+			wantDownload: "https://hdsky.me/download.php?id=42",
+		},
+		{
+			name:         "http 站点",
+			site:         model.Site{Site: "test", BaseURL: "test.org", DetailsPage: "details.php?id={}", DownloadPage: "download.php?id={}&passkey={passkey}&downhash={downHash}"},
+			wantDetails:  "http://test.org/details.php?id=42",
+			wantDownload: "http://test.org/download.php?id=42",
+		},
+		{
+			name:        "内置规则：m-team 使用实际域名且不生成下载链接",
+			site:        model.Site{Site: "m-team", BaseURL: "m-team.cc", DetailsPage: "detail/{}", DownloadPage: "api/rss/dl?id={}", IsHTTPS: 1},
+			wantDetails: "https://kp.m-team.cc/detail/42",
+		},
+		{
+			name:         "规则覆盖地址和模板",
+			site:         model.Site{Site: "hdcity", BaseURL: "hdcity.leniter.org", DetailsPage: "details.php?id={}", IsHTTPS: 1},
+			wantDetails:  "https://hdcity.city/t-42",
+			wantDownload: "https://hdcity.city/download?id=42",
+		},
+		{
+			name:        "Gazelle 站点缺少凭据时不生成下载链接",
+			site:        model.Site{Site: "gazelle", BaseURL: "gazelle.org", DetailsPage: "torrents.php?torrentid={}", DownloadPage: "torrents.php?action=download&id={}&authkey={authkey}&torrent_pass={torrent_pass}", IsHTTPS: 1},
+			wantDetails: "https://gazelle.org/torrents.php?torrentid=42",
+		},
+		{
+			name:        "没有凭据占位符的接口模板不生成下载链接",
+			site:        model.Site{Site: "api", BaseURL: "api.org", DetailsPage: "detail/{}", DownloadPage: "api/dl?id={}", IsHTTPS: 1},
+			wantDetails: "https://api.org/detail/42",
+		},
+		{
+			name: "没有模板",
+			site: model.Site{Site: "test", BaseURL: "test.org"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := custom.DetailsURL(&tt.site, "42"); got != tt.wantDetails {
+				t.Errorf("DetailsURL() = %v, want %v", got, tt.wantDetails)
+			}
+			if got := custom.DownloadURL(&tt.site, "42", nil); got != tt.wantDownload {
+				t.Errorf("DownloadURL() = %v, want %v", got, tt.wantDownload)
+			}
+		})
+	}
+}
+
+func TestRuleSet_DownloadURL_Credentials(t *testing.T) {
+	rules, err := New(nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	gazelle := model.Site{Site: "gazelle", BaseURL: "gazelle.org", DownloadPage: "torrents.php?action=download&id={}&authkey={authkey}&torrent_pass={torrent_pass}", IsHTTPS: 1}
+	rss := model.Site{Site: "rss", BaseURL: "rss.org", DownloadPage: "rss/{passkey}/{}", IsHTTPS: 1}
+	tests := []struct {
+		name        string
+		site        *model.Site
+		credentials model.SiteCredentials
+		want        string
+	}{
+		{name: "Gazelle 凭据齐全", site: &gazelle, credentials: model.SiteCredentials{"authkey": "ak", "torrent_pass": "tp"}, want: "https://gazelle.org/torrents.php?action=download&id=42&authkey=ak&torrent_pass=tp"},
+		{name: "Gazelle 缺少部分凭据", site: &gazelle, credentials: model.SiteCredentials{"authkey": "ak"}},
+		{name: "Gazelle 没有凭据", site: &gazelle},
+		{name: "路径中的凭据", site: &rss, credentials: model.SiteCredentials{"passkey": "pk"}, want: "https://rss.org/rss/pk/42"},
+		{name: "路径中缺少凭据", site: &rss},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.DownloadURL(tt.site, "42", tt.credentials); got != tt.want {
+				t.Errorf("DownloadURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleSet_Enrich(t *testing.T) {
+	rules, err := New([]Rule{{Site: "hdsky", Aliases: []string{"HDSky", "天空"}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	data := &model.ProcessedData{
+		Time: "2026-01-19 07:50:56",
+		Items: []model.SiteItem{
+			{SiteName: "hdsky", SiteID: "42", ID: 1},
+			{SiteName: "天空", SiteID: "43", ID: 2},
+			{SiteName: "unknown", SiteID: "7", ID: 3},
+		},
+	}
+	catalog := &model.SiteCatalog{Sites: []model.Site{
+		{Site: "hdsky", Nickname: "天空", BaseURL: "hdsky.me", DetailsPage: "details.php?id={}", DownloadPage: "download.php?id={}", IsHTTPS: 1},
+	}}
+
+	enriched := rules.Enrich(data, catalog, nil)
+	if got := enriched.Items[0]; got.Nickname != "天空" || got.DetailsURL != "https://hdsky.me/details.php?id=42" || got.DownloadURL == "" {
+		t.Errorf("Items[0] = %+v", got)
+	}
+	if got := enriched.Items[1]; got.DetailsURL != "https://hdsky.me/details.php?id=43" {
+		t.Errorf("别名应对应到站点目录中的 hdsky: %+v", got)
+	}
+	if got := enriched.Items[2]; got.Nickname != "" || got.DetailsURL != "" {
+		t.Errorf("目录中没有的站点不应补充: %+v", got)
+	}
+	if data.Items[0].DetailsURL != "" || enriched.ContentHash() != data.ContentHash() {
+		t.Error("Enrich() 不应修改原数据或影响内容哈希")
+	}
+
+	// 按请求用户的凭据填充下载链接，其他站点的凭据不影响
+	catalog.Sites[0].DownloadPage = "download.php?id={}&passkey={passkey}"
+	personal := rules.Enrich(data, catalog, model.UserCredentials{"hdsky": {"passkey": "secret"}, "other": {"passkey": "x"}})
+	if got := personal.Items[0].DownloadURL; got != "https://hdsky.me/download.php?id=42&passkey=secret" {
+		t.Errorf("个性化 DownloadURL = %v", got)
+	}
+	if got := rules.Enrich(data, catalog, nil).Items[0].DownloadURL; got != "https://hdsky.me/download.php?id=42" {
+		t.Errorf("匿名 DownloadURL = %v", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantErr     string
+		wantRules   int
+		wantMTeamDL string
+	}{
+		{
+			name:      "补充规则并保留内置规则",
+			content:   `{"rules":[{"site":"hdsky","aliases":["天空"],"details":"details.php?id={}"}]}`,
+			wantRules: 2,
+		},
+		{
+			name:        "覆盖内置规则",
+			content:     `{"rules":[{"site":"m-team","baseUrl":"https://m-team.io","download":"download.php?id={}&passkey={passkey}"}]}`,
+			wantRules:   1,
+			wantMTeamDL: "https://m-team.io/download.php?id=42",
+		},
+		{name: "JSON 格式错误", content: `{"rules":[`, wantErr: "解析站点规则文件失败"},
+		{name: "未知字段", content: `{"rules":[{"site":"hdsky","detail":"details.php?id={}"}]}`, wantErr: "解析站点规则文件失败"},
+		{name: "站点标识为空", content: `{"rules":[{"aliases":["天空"]}]}`, wantErr: "站点标识不能为空"},
+		{name: "站点重复", content: `{"rules":[{"site":"hdsky"},{"site":"hdsky"}]}`, wantErr: "站点重复"},
+		{name: "别名冲突", content: `{"rules":[{"site":"a","aliases":["x"]},{"site":"b","aliases":["x"]}]}`, wantErr: "别名 x 已属于 a"},
+		{name: "地址缺少协议", content: `{"rules":[{"site":"hdsky","baseUrl":"hdsky.me"}]}`, wantErr: "baseUrl"},
+		{name: "未知占位符", content: `{"rules":[{"site":"hdsky","download":"dl/{}?key={rsskey}"}]}`, wantErr: "{rsskey}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "site-rules.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			rules, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want 包含 %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if rules.Len() != tt.wantRules {
+				t.Errorf("Len() = %d, want %d", rules.Len(), tt.wantRules)
+			}
+
+			mteam := model.Site{Site: "m-team", BaseURL: "m-team.cc", DownloadPage: "api/rss/dl?id={}", IsHTTPS: 1}
+			if got := rules.DownloadURL(&mteam, "42", nil); got != tt.wantMTeamDL {
+				t.Errorf("m-team DownloadURL() = %v, want %v", got, tt.wantMTeamDL)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Load() 文件不存在时应返回错误")
+	}
+}
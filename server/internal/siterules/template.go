@@ -0,0 +1,121 @@
+package siterules
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"top1000/internal/model"
+)
+
+// 链接模板占位符（与 IYUU 站点目录的 details_page/download_page 一致）
+const (
+	PlaceholderTorrentID   = "{}"             // 种子ID
+	PlaceholderPasskey     = "{passkey}"      // 用户 passkey（NexusPHP 等）
+	PlaceholderDownHash    = "{downHash}"     // 下载校验值（hdchina 等）
+	PlaceholderUID         = "{uid}"          // 用户ID
+	PlaceholderHash        = "{hash}"         // 用户校验值
+	PlaceholderAuthKey     = "{authkey}"      // Gazelle 站点的 authkey
+	PlaceholderTorrentPass = "{torrent_pass}" // Gazelle 站点的 torrent_pass
+)
+
+// credentialPlaceholders 需要用户凭据的占位符（用户没有保存对应凭据时，所在的查询参数直接去掉）
+var credentialPlaceholders = []string{
+	PlaceholderPasskey,
+	PlaceholderDownHash,
+	PlaceholderUID,
+	PlaceholderHash,
+	PlaceholderAuthKey,
+	PlaceholderTorrentPass,
+}
+
+// IsCredential name（不含花括号，如 passkey）是否为凭据占位符
+func IsCredential(name string) bool {
+	return slices.Contains(credentialPlaceholders, "{"+name+"}")
+}
+
+// placeholderPattern 匹配模板中的占位符
+var placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// unknownPlaceholders 模板中不认识的占位符
+func unknownPlaceholders(template string) []string {
+	var unknown []string
+	for _, placeholder := range placeholderPattern.FindAllString(template, -1) {
+		if placeholder != PlaceholderTorrentID && !slices.Contains(credentialPlaceholders, placeholder) {
+			unknown = append(unknown, placeholder)
+		}
+	}
+	return unknown
+}
+
+// hasCredential 是否包含凭据占位符
+func hasCredential(s string) bool {
+	return slices.ContainsFunc(credentialPlaceholders, func(placeholder string) bool {
+		return strings.Contains(s, placeholder)
+	})
+}
+
+// nexusDownloadPage NexusPHP 的下载页（去掉 passkey 等参数后登录状态下仍可下载）
+const nexusDownloadPage = "download.php"
+
+// downloadable 站点目录中的下载模板能否生成可用的链接
+// download.php 总是可以；其他模板需要至少一个凭据占位符且都有用户保存的凭据
+// （没有凭据占位符时无法判断站点如何认证，和网页端一样不生成）
+func downloadable(template string, credentials model.SiteCredentials) bool {
+	if strings.Contains(template, nexusDownloadPage) {
+		return true
+	}
+	if !hasCredential(template) {
+		return false
+	}
+	for _, placeholder := range placeholderPattern.FindAllString(template, -1) {
+		if slices.Contains(credentialPlaceholders, placeholder) && credentials[strings.Trim(placeholder, "{}")] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// fillCredentials 用用户凭据替换占位符（escape 为路径或查询参数的转义函数）
+func fillCredentials(s string, credentials model.SiteCredentials, escape func(string) string) string {
+	for name, value := range credentials {
+		if IsCredential(name) {
+			s = strings.ReplaceAll(s, "{"+name+"}", escape(value))
+		}
+	}
+	return s
+}
+
+// link 按模板生成链接
+// 相对路径拼接到站点地址之后，http(s):// 开头的模板直接使用；凭据占位符用 credentials 填充，
+// 没有对应凭据的查询参数被去掉，之后仍有种子ID以外的占位符（例如在路径中）时无法生成，返回空
+func link(origin, template, torrentID string, credentials model.SiteCredentials) string {
+	if template == "" || torrentID == "" {
+		return ""
+	}
+
+	page, query, ok := strings.Cut(template, "?")
+	page = fillCredentials(page, credentials, url.PathEscape)
+	if ok {
+		query = fillCredentials(query, credentials, url.QueryEscape)
+		params := slices.DeleteFunc(strings.Split(query, "&"), func(param string) bool {
+			return param == "" || hasCredential(param)
+		})
+		if len(params) > 0 {
+			page += "?" + strings.Join(params, "&")
+		}
+	}
+
+	if slices.ContainsFunc(placeholderPattern.FindAllString(page, -1), func(placeholder string) bool {
+		return placeholder != PlaceholderTorrentID
+	}) {
+		return ""
+	}
+	page = strings.ReplaceAll(page, PlaceholderTorrentID, url.PathEscape(torrentID))
+
+	if strings.HasPrefix(page, "http://") || strings.HasPrefix(page, "https://") {
+		return page
+	}
+	return origin + "/" + strings.TrimPrefix(page, "/")
+}
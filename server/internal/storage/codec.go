@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"top1000/internal/config"
+)
+
+// payloadMagic 压缩数据头（后跟 1 字节编码标识）
+// JSON 不可能以 0x00 开头，没有数据头的值按旧版本的未压缩 JSON 读取
+const payloadMagic = "\x00t1k"
+
+// 编码标识
+const (
+	codecGzip byte = 'g'
+	codecZstd byte = 'z'
+)
+
+// zstd 编解码器可以并发复用（EncodeAll/DecodeAll 并发安全）
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// encodePayload 按配置的压缩算法编码数据（none 时原样返回）
+func encodePayload(compression string, data []byte) ([]byte, error) {
+	var codec byte
+	switch compression {
+	case config.CompressionNone, "":
+		return data, nil
+	case config.CompressionGzip:
+		codec = codecGzip
+	case config.CompressionZstd:
+		codec = codecZstd
+	default:
+		return nil, fmt.Errorf("%s: 不支持的压缩算法 %s", errPayloadEncodeFailed, compression)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(payloadMagic) + 1 + len(data)/4)
+	buf.WriteString(payloadMagic)
+	buf.WriteByte(codec)
+
+	switch codec {
+	case codecGzip:
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", errPayloadEncodeFailed, err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("%s: %w", errPayloadEncodeFailed, err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return zstdEncoder.EncodeAll(data, buf.Bytes()), nil
+	}
+}
+
+// decodePayload 解码数据（兼容没有数据头的旧版本未压缩数据）
+func decodePayload(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(payloadMagic)) || len(data) <= len(payloadMagic) {
+		return data, nil
+	}
+
+	codec, body := data[len(payloadMagic)], data[len(payloadMagic)+1:]
+	switch codec {
+	case codecGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errPayloadDecodeFailed, err)
+		}
+		defer zr.Close()
+
+		decoded, err := io.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errPayloadDecodeFailed, err)
+		}
+		return decoded, nil
+	case codecZstd:
+		decoded, err := zstdDecoder.DecodeAll(body, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errPayloadDecodeFailed, err)
+		}
+		return decoded, nil
+	default:
+		return nil, fmt.Errorf("%s: 未知编码 %q", errPayloadDecodeFailed, codec)
+	}
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+func TestPayloadCodec(t *testing.T) {
+	jsonData := []byte(`{"time":"2026-01-19 07:50:56","items":[` + strings.Repeat(`{"siteName":"测试站点","siteid":"1"},`, 50) + `{}]}`)
+
+	for _, compression := range []string{config.CompressionNone, config.CompressionGzip, config.CompressionZstd} {
+		t.Run(compression, func(t *testing.T) {
+			encoded, err := encodePayload(compression, jsonData)
+			if err != nil {
+				t.Fatalf("encodePayload() error = %v", err)
+			}
+			if compression != config.CompressionNone && len(encoded) >= len(jsonData) {
+				t.Errorf("压缩后 %d 字节，原始 %d 字节", len(encoded), len(jsonData))
+			}
+
+			decoded, err := decodePayload(encoded)
+			if err != nil {
+				t.Fatalf("decodePayload() error = %v", err)
+			}
+			if !bytes.Equal(decoded, jsonData) {
+				t.Errorf("解码结果与原始数据不一致")
+			}
+		})
+	}
+
+	t.Run("未压缩的旧数据原样返回", func(t *testing.T) {
+		decoded, err := decodePayload(jsonData)
+		if err != nil || !bytes.Equal(decoded, jsonData) {
+			t.Errorf("decodePayload() = %s, %v", decoded, err)
+		}
+	})
+
+	t.Run("不支持的压缩算法", func(t *testing.T) {
+		if _, err := encodePayload("lz4", jsonData); err == nil {
+			t.Error("encodePayload() 期望返回错误")
+		}
+	})
+
+	t.Run("未知编码标识", func(t *testing.T) {
+		if _, err := decodePayload([]byte(payloadMagic + "x" + "data")); err == nil {
+			t.Error("decodePayload() 期望返回错误")
+		}
+	})
+
+	t.Run("损坏的压缩数据", func(t *testing.T) {
+		if _, err := decodePayload([]byte(payloadMagic + string(codecGzip) + "not gzip")); err == nil {
+			t.Error("decodePayload() 期望返回错误")
+		}
+	})
+}
+
+func TestRedisStoreCompression(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	store := setupTestStore(t, mr)
+	data := model.ProcessedData{
+		Time:  "2026-01-19 07:50:56",
+		Items: []model.SiteItem{{SiteName: "测试站点", SiteID: "123", ID: 1}},
+	}
+
+	t.Run("写入压缩数据", func(t *testing.T) {
+		store.compression = config.CompressionGzip
+		if err := store.SaveData(ctx, data); err != nil {
+			t.Fatalf("SaveData() error = %v", err)
+		}
+
+		raw, _ := mr.Get(legacyRedisKeys.data())
+		if !strings.HasPrefix(raw, payloadMagic) {
+			t.Errorf("Redis 中的数据应带压缩头: %q", raw[:min(len(raw), 16)])
+		}
+		snapshot := mr.HGet(legacyRedisKeys.historyData(), data.Time)
+		if !strings.HasPrefix(snapshot, payloadMagic) {
+			t.Error("历史快照应压缩保存")
+		}
+	})
+
+	t.Run("切换算法后仍可读取旧数据", func(t *testing.T) {
+		store.compression = config.CompressionZstd
+
+		loaded, err := store.LoadData(ctx)
+		if err != nil || loaded.Time != data.Time {
+			t.Fatalf("LoadData() = %v, %v", loaded, err)
+		}
+		if _, err := store.LoadSnapshot(ctx, data.Time); err != nil {
+			t.Errorf("LoadSnapshot() error = %v", err)
+		}
+	})
+
+	t.Run("读取未压缩的旧数据", func(t *testing.T) {
+		legacy := `{"time":"2026-01-18 07:50:56","items":[{"siteName":"旧站点","siteid":"1","id":1}]}`
+		if err := mr.Set(legacyRedisKeys.data(), legacy); err != nil {
+			t.Fatalf("写入旧数据失败: %v", err)
+		}
+
+		loaded, err := store.LoadData(ctx)
+		if err != nil || loaded.Items[0].SiteName != "旧站点" {
+			t.Errorf("LoadData() = %v, %v", loaded, err)
+		}
+	})
+}
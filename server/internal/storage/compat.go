@@ -4,33 +4,84 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/redis/go-redis/v9"
 	"top1000/internal/config"
 )
 
 var (
-	defaultStore      DataStore
-	defaultSitesStore SitesStore
-	defaultLock       UpdateLock
-	redisClient       *redis.Client
+	defaultStore       DataStore
+	defaultSitesStore  SitesStore
+	defaultHistory     HistoryStore
+	defaultQuarantine  QuarantineStore
+	defaultRaw         RawStore
+	defaultCredentials CredentialStore
+	defaultLock        UpdateLock
+	redisClient        redis.UniversalClient
 )
 
+// Init 根据配置初始化存储后端（redis/memory/file）
+func Init() error {
+	cfg := config.Get()
+	switch backend := cfg.StorageBackend; backend {
+	case config.StorageBackendMemory:
+		InitMemory()
+		return nil
+	case config.StorageBackendFile:
+		return InitFile(cfg.DataDir)
+	case config.StorageBackendRedis, "":
+		return InitRedis()
+	default:
+		return fmt.Errorf("不支持的存储后端: %s", backend)
+	}
+}
+
+// InitMemory 初始化内存存储（无需 Redis）
+func InitMemory() {
+	memoryStore := NewMemoryStore()
+	defaultStore = memoryStore.AsDataStore()
+	defaultSitesStore = memoryStore.AsSitesStore()
+	defaultHistory = memoryStore.AsHistoryStore()
+	defaultQuarantine = memoryStore.AsQuarantineStore()
+	defaultRaw = memoryStore.AsRawStore()
+	defaultCredentials = memoryStore.AsCredentialStore()
+	defaultLock = memoryStore.AsUpdateLock()
+
+	log.Println("已启用内存存储（数据不会持久化）")
+}
+
+// InitFile 初始化文件存储（无需 Redis，数据保存在 dir 目录）
+func InitFile(dir string) error {
+	fileStore, err := NewFileStore(dir)
+	if err != nil {
+		return fmt.Errorf("文件存储初始化失败: %w", err)
+	}
+
+	defaultStore = fileStore.AsDataStore()
+	defaultSitesStore = fileStore.AsSitesStore()
+	defaultHistory = fileStore.AsHistoryStore()
+	defaultQuarantine = fileStore.AsQuarantineStore()
+	defaultRaw = fileStore.AsRawStore()
+	defaultCredentials = fileStore.AsCredentialStore()
+	defaultLock = fileStore.AsUpdateLock()
+
+	log.Printf("已启用文件存储: %s", fileStore.Path())
+	return nil
+}
+
 // InitRedis 初始化 Redis 连接
 func InitRedis() error {
 	cfg := config.Get()
-	log.Printf("正在连接Redis: %s (DB: %d)", cfg.RedisAddr, cfg.RedisDB)
-
-	redisClient = redis.NewClient(&redis.Options{
-		Addr:         cfg.RedisAddr,
-		Password:     cfg.RedisPassword,
-		DB:           cfg.RedisDB,
-		DialTimeout:  dialTimeout,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
-		PoolSize:     poolSize,
-		MinIdleConns: minIdleConns,
-	})
+	opts, err := redisOptions(cfg)
+	if err != nil {
+		return fmt.Errorf("Redis配置无效: %w", err)
+	}
+	redisClient = newRedisClient(cfg.RedisMode(), opts)
+	redisStore := NewRedisStore(redisClient)
+	log.Printf("正在连接Redis（%s）: %s (DB: %d, TLS: %v, 连接池: %d, 命名空间: %s)",
+		cfg.RedisMode(), strings.Join(opts.Addrs, ","), opts.DB, opts.TLSConfig != nil, opts.PoolSize,
+		redisStore.keys.prefix)
 
 	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
 	defer cancel()
@@ -40,15 +91,23 @@ func InitRedis() error {
 		return fmt.Errorf("Redis连接失败: %w", err)
 	}
 
-	redisStore := NewRedisStore(redisClient)
 	defaultStore = redisStore.AsDataStore()
 	defaultSitesStore = redisStore.AsSitesStore()
+	defaultHistory = redisStore.AsHistoryStore()
+	defaultQuarantine = redisStore.AsQuarantineStore()
+	defaultRaw = redisStore.AsRawStore()
+	defaultCredentials = redisStore.AsCredentialStore()
 	defaultLock = redisStore.AsUpdateLock()
 
 	log.Println("Redis连接成功")
 	return nil
 }
 
+// Close 关闭存储后端（内存和文件后端无需关闭，每次写入已落盘）
+func Close() error {
+	return CloseRedis()
+}
+
 // CloseRedis 关闭 Redis 连接
 func CloseRedis() error {
 	if redisClient != nil {
@@ -67,6 +126,26 @@ func GetDefaultSitesStore() SitesStore {
 	return defaultSitesStore
 }
 
+// GetDefaultHistoryStore 获取默认历史快照存储实例
+func GetDefaultHistoryStore() HistoryStore {
+	return defaultHistory
+}
+
+// GetDefaultQuarantineStore 获取默认隔离区实例
+func GetDefaultQuarantineStore() QuarantineStore {
+	return defaultQuarantine
+}
+
+// GetDefaultRawStore 获取默认原始内容存储实例
+func GetDefaultRawStore() RawStore {
+	return defaultRaw
+}
+
+// GetDefaultCredentialStore 获取默认用户凭据存储实例
+func GetDefaultCredentialStore() CredentialStore {
+	return defaultCredentials
+}
+
 // GetDefaultLock 获取默认更新锁实例
 func GetDefaultLock() UpdateLock {
 	return defaultLock
@@ -1,13 +1,36 @@
 package storage
 
+import "errors"
+
+// ErrSnapshotNotFound 历史快照不存在（API 层据此返回 404）
+var ErrSnapshotNotFound = errors.New("历史快照不存在")
+
+// ErrSnapshotTimeInvalid 快照查询时间格式错误（API 层据此返回 400）
+var ErrSnapshotTimeInvalid = errors.New("快照时间格式错误")
+
+// ErrReportNotFound 还没有解析报告（API 层据此返回 404）
+var ErrReportNotFound = errors.New("解析报告不存在")
+
+// ErrRawNotFound 原始内容不存在（API 层据此返回 404）
+var ErrRawNotFound = errors.New("原始内容不存在")
+
+// ErrCredentialsNotFound 用户没有保存凭据
+var ErrCredentialsNotFound = errors.New("用户凭据不存在")
+
 // 错误常量 - 遵循 DRY 原则，避免重复的字符串
 const (
 	errDataNotFound      = "数据不存在"
 	errSitesNotFound     = "站点数据不存在"
 	errRedisReadFailed   = "从Redis读取数据失败"
 	errRedisSaveFailed   = "保存数据到Redis失败"
+	errStoreSaveFailed   = "保存数据失败"
+	errFileReadFailed    = "读取存储文件失败"
 	errJSONMarshalFailed = "序列化数据失败"
 	errJSONUnmarshalFailed = "解析JSON失败"
 	errDataInvalid       = "数据验证失败"
 	errCheckExistsFailed = "检查数据存在性失败"
+	errRedisURLInvalid   = "解析REDIS_URL失败"
+	errRedisTLSInvalid   = "加载Redis TLS证书失败"
+	errPayloadEncodeFailed = "压缩数据失败"
+	errPayloadDecodeFailed = "解压数据失败"
 )
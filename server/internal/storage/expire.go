@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"log"
+	"time"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+// isDataExpired 根据数据 time 字段判断是否过期（各存储后端共用）
+func isDataExpired(data *model.ProcessedData) bool {
+	age, err := dataAge(data)
+	if err != nil {
+		log.Printf("解析数据时间失败: %v", err)
+		return true // 解析失败，认为过期，强制更新
+	}
+
+	if age > config.DefaultDataExpire && recentlyChecked(data) {
+		log.Printf("数据时间较旧但上游未变化（数据时间: %v, 最近检查: %v）", data.Time, data.LastCheckedAt.Format(time.DateTime))
+		return false
+	}
+
+	isExpired := age > config.DefaultDataExpire
+
+	// 统一日志输出
+	logDataStatus(data.Time, age.Round(time.Minute), isExpired, config.DefaultDataExpire)
+	return isExpired
+}
+
+// IsExpired 判断已加载的数据是否过期（不输出日志，供请求路径使用）
+func IsExpired(data *model.ProcessedData) bool {
+	age, err := dataAge(data)
+	return err != nil || (age > config.DefaultDataExpire && !recentlyChecked(data))
+}
+
+// recentlyChecked 最近是否确认过上游没有新数据
+// 上游长时间不更新时，避免每次检查都判定为过期并重复爬取；
+// 半个刷新周期内不再重复检查（调度器的每次运行都会重新检查）
+func recentlyChecked(data *model.ProcessedData) bool {
+	if data.LastCheckedAt.IsZero() {
+		return false
+	}
+
+	interval := config.Get().RefreshInterval
+	if interval <= 0 {
+		interval = config.DefaultRefreshInterval
+	}
+	return time.Since(data.LastCheckedAt) < interval/2
+}
+
+// dataAge 计算数据距今的时间
+// 时间按上游时区（UPSTREAM_TIMEZONE）解释，与主机 TZ 无关
+func dataAge(data *model.ProcessedData) (time.Duration, error) {
+	createdAt, err := data.CreatedTime(config.Get().UpstreamLocation())
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(createdAt), nil
+}
+
+// logDataStatus 记录数据状态日志
+func logDataStatus(dataTime string, age time.Duration, isExpired bool, threshold time.Duration) {
+	if isExpired {
+		log.Printf("数据过期了（数据时间: %v, 距今: %v，阈值: %v）", dataTime, age, threshold)
+	} else {
+		log.Printf("数据还新鲜（数据时间: %v, 距今: %v）", dataTime, age)
+	}
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+func TestIsExpired(t *testing.T) {
+	shanghai := config.Get().UpstreamLocation()
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		data model.ProcessedData
+		want bool
+	}{
+		{name: "刚生成", data: model.ProcessedData{Time: now.In(shanghai).Format(model.DataTimeLayout)}, want: false},
+		{name: "23小时前", data: model.ProcessedData{Time: now.Add(-23 * time.Hour).In(shanghai).Format(model.DataTimeLayout)}, want: false},
+		{name: "25小时前", data: model.ProcessedData{Time: now.Add(-25 * time.Hour).In(shanghai).Format(model.DataTimeLayout)}, want: true},
+		{name: "优先使用 createdAt", data: model.ProcessedData{Time: "2020-01-01 00:00:00", CreatedAt: now}, want: false},
+		{name: "时间格式错误", data: model.ProcessedData{Time: "昨天"}, want: true},
+	}
+
+	// 结果不应受主机时区影响
+	for _, local := range []string{"UTC", "America/New_York", "Asia/Shanghai"} {
+		loc, err := time.LoadLocation(local)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		original := time.Local
+		time.Local = loc
+		for _, tt := range tests {
+			t.Run(local+"/"+tt.name, func(t *testing.T) {
+				if got := IsExpired(&tt.data); got != tt.want {
+					t.Errorf("IsExpired() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+		time.Local = original
+	}
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+)
+
+const (
+	fileStoreName = "top1000-store.json" // 数据目录中的主存储文件名（检查时间、解析报告、凭据等小字段）
+	dataDirPerm   = 0o755
+)
+
+// fileSection 单独保存到一个文件的大字段
+// 每次写入只重写变化了的文件，未变化的历史快照、原始内容等不再随每次刷新重写
+type fileSection struct {
+	// name 文件名
+	name string
+
+	// pick 取出该文件保存的字段（其他字段为零值，序列化时省略）
+	pick func(s *memoryState) memoryState
+
+	// clear 从主文件的内容中去掉该字段
+	clear func(s *memoryState)
+
+	// changed 字段是否变化（update 只整体替换字段，未变化的字段仍是同一份数据）
+	changed func(prev, next *memoryState) bool
+}
+
+// fileSections 主文件之外单独保存的字段
+var fileSections = []fileSection{
+	{
+		name:    "top1000-store.data.json",
+		pick:    func(s *memoryState) memoryState { return memoryState{Data: s.Data} },
+		clear:   func(s *memoryState) { s.Data = nil },
+		changed: func(prev, next *memoryState) bool { return !sameSlice(prev.Data, next.Data) },
+	},
+	{
+		name:    "top1000-store.sites.json",
+		pick:    func(s *memoryState) memoryState { return memoryState{Sites: s.Sites} },
+		clear:   func(s *memoryState) { s.Sites = nil },
+		changed: func(prev, next *memoryState) bool { return !sameSlice(prev.Sites, next.Sites) },
+	},
+	{
+		name:    "top1000-store.history.json",
+		pick:    func(s *memoryState) memoryState { return memoryState{History: s.History} },
+		clear:   func(s *memoryState) { s.History = nil },
+		changed: func(prev, next *memoryState) bool { return !sameMap(prev.History, next.History) },
+	},
+	{
+		name:    "top1000-store.quarantine.json",
+		pick:    func(s *memoryState) memoryState { return memoryState{Quarantine: s.Quarantine} },
+		clear:   func(s *memoryState) { s.Quarantine = nil },
+		changed: func(prev, next *memoryState) bool { return !sameSlice(prev.Quarantine, next.Quarantine) },
+	},
+	{
+		name:    "top1000-store.raw.json",
+		pick:    func(s *memoryState) memoryState { return memoryState{Raw: s.Raw} },
+		clear:   func(s *memoryState) { s.Raw = nil },
+		changed: func(prev, next *memoryState) bool { return !sameSlice(prev.Raw, next.Raw) },
+	},
+}
+
+// FileStore 文件持久化实现（DataStore + SitesStore + HistoryStore + QuarantineStore + RawStore + CredentialStore + UpdateLock）
+// 基于 MemoryStore，每次写入后把变化的部分原子写入数据目录中的 JSON 文件：
+// 当前数据、站点数据、历史快照、隔离区和原始内容各占一个文件，其余小字段在主文件中，
+// 适用于不想额外部署 Redis 的小型部署，重启后数据仍在
+type FileStore struct {
+	*MemoryStore
+	dir string
+}
+
+// NewFileStore 创建文件存储实例（数据目录不存在时自动创建）
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, dataDirPerm); err != nil {
+		return nil, fmt.Errorf("创建数据目录失败: %w", err)
+	}
+
+	f := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		dir:         dir,
+	}
+	f.name = "文件"
+
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	f.persist = f.writeChanged
+
+	return f, nil
+}
+
+// Path 返回主存储文件路径
+func (f *FileStore) Path() string {
+	return filepath.Join(f.dir, fileStoreName)
+}
+
+// load 从存储文件恢复状态（文件不存在视为空存储）
+// 旧版本把全部状态写在主文件中，读取后立即拆分到各自的文件
+func (f *FileStore) load() error {
+	found, err := f.readFile(fileStoreName, &f.state)
+	if err != nil {
+		return err
+	}
+	if !found {
+		log.Printf("存储文件不存在，将在首次写入时创建: %s", f.Path())
+	}
+
+	var empty memoryState
+	legacy := slices.ContainsFunc(fileSections, func(section fileSection) bool {
+		return section.changed(&empty, &f.state)
+	})
+
+	for _, section := range fileSections {
+		if _, err := f.readFile(section.name, &f.state); err != nil {
+			return err
+		}
+	}
+
+	if legacy {
+		if err := f.writeChanged(&memoryState{}, &f.state); err != nil {
+			return fmt.Errorf("拆分旧版存储文件失败: %w", err)
+		}
+		log.Printf("已将旧版存储文件拆分保存: %s", f.dir)
+	}
+
+	if found {
+		log.Printf("已从存储文件恢复数据: %s", f.Path())
+	}
+	return nil
+}
+
+// readFile 把数据目录中的一个文件解析到 state（只覆盖文件中出现的字段，文件不存在时返回 false）
+func (f *FileStore) readFile(name string, state *memoryState) (bool, error) {
+	path := filepath.Join(f.dir, name)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", errFileReadFailed, err)
+	}
+
+	// 文件损坏时拒绝启动，避免下一次写入覆盖掉可人工恢复的数据
+	if err := json.Unmarshal(content, state); err != nil {
+		return false, fmt.Errorf("%s: %s: %w", errFileReadFailed, path, err)
+	}
+	return true, nil
+}
+
+// writeChanged 写入 prev 到 next 之间变化的文件
+// 先写单独保存的字段，最后写主文件；每个文件各自原子替换
+func (f *FileStore) writeChanged(prev, next *memoryState) error {
+	main := *next
+	for _, section := range fileSections {
+		section.clear(&main)
+		if !section.changed(prev, next) {
+			continue
+		}
+		if err := f.writeFile(section.name, section.pick(next)); err != nil {
+			return err
+		}
+	}
+	return f.writeFile(fileStoreName, main)
+}
+
+// writeFile 原子写入数据目录中的一个文件（临时文件 + fsync + rename，文件权限 0600）
+func (f *FileStore) writeFile(name string, state memoryState) error {
+	content, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+	}
+
+	tmp, err := os.CreateTemp(f.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	tmpPath := tmp.Name()
+	// rename 成功后临时文件已不存在，Remove 是空操作
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("同步临时文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("关闭临时文件失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(f.dir, name)); err != nil {
+		return fmt.Errorf("替换存储文件失败: %w", err)
+	}
+	return nil
+}
+
+// sameSlice 两个切片是否为同一份数据（长度相同且指向同一底层数组）
+func sameSlice[S ~[]E, E any](a, b S) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// sameMap 两个 map 是否为同一个（都为空也视为相同）
+func sameMap[M ~map[K]V, K comparable, V any](a, b M) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+}
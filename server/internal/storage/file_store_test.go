@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+func TestFileStoreSuite(t *testing.T) {
+	runStoreSuite(t, func(t *testing.T) suiteStore {
+		store, err := NewFileStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewFileStore() error = %v", err)
+		}
+		return store
+	})
+}
+
+func TestFileStorePersistence(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	data := model.ProcessedData{
+		Time:  "2026-01-19 07:50:56",
+		Items: []model.SiteItem{{SiteName: "测试站点", SiteID: "123", Size: "1.2TB", ID: 1}},
+	}
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	if err := store.SaveData(ctx, data); err != nil {
+		t.Fatalf("SaveData() error = %v", err)
+	}
+	if err := store.SaveSitesData(ctx, testSites); err != nil {
+		t.Fatalf("SaveSitesData() error = %v", err)
+	}
+
+	t.Run("重新打开后数据仍在", func(t *testing.T) {
+		reopened, err := NewFileStore(dir)
+		if err != nil {
+			t.Fatalf("NewFileStore() error = %v", err)
+		}
+
+		loaded, err := reopened.LoadData(ctx)
+		if err != nil {
+			t.Fatalf("LoadData() error = %v", err)
+		}
+		if loaded.Time != data.Time || len(loaded.Items) != 1 {
+			t.Errorf("LoadData() = %+v, want %+v", loaded, data)
+		}
+		if exists, _ := reopened.SitesDataExists(ctx); !exists {
+			t.Error("SitesDataExists() = false, want true")
+		}
+	})
+
+	t.Run("站点数据TTL跨重启生效", func(t *testing.T) {
+		reopened, err := NewFileStore(dir)
+		if err != nil {
+			t.Fatalf("NewFileStore() error = %v", err)
+		}
+		reopened.now = func() time.Time { return time.Now().Add(config.DefaultSitesExpire + time.Minute) }
+
+		if exists, _ := reopened.SitesDataExists(ctx); exists {
+			t.Error("SitesDataExists() = true, want false (站点数据应已过期)")
+		}
+	})
+
+	t.Run("目录中不残留临时文件", func(t *testing.T) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("ReadDir() error = %v", err)
+		}
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".tmp") {
+				t.Errorf("数据目录中残留临时文件: %s", entry.Name())
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, fileStoreName)); err != nil {
+			t.Errorf("主存储文件不存在: %v", err)
+		}
+	})
+
+	t.Run("只重写变化的文件", func(t *testing.T) {
+		// 删除历史快照和当前数据文件后记录检查时间，只应重写主文件
+		history := filepath.Join(dir, "top1000-store.history.json")
+		current := filepath.Join(dir, "top1000-store.data.json")
+		for _, path := range []string{history, current} {
+			if err := os.Remove(path); err != nil {
+				t.Fatalf("Remove() error = %v", err)
+			}
+		}
+
+		checkedAt := time.Date(2026, 1, 19, 8, 0, 0, 0, time.UTC)
+		if err := store.TouchData(ctx, checkedAt); err != nil {
+			t.Fatalf("TouchData() error = %v", err)
+		}
+		for _, path := range []string{history, current} {
+			if _, err := os.Stat(path); err == nil {
+				t.Errorf("TouchData() 不应重写 %s", filepath.Base(path))
+			}
+		}
+
+		main, err := os.ReadFile(filepath.Join(dir, fileStoreName))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if !strings.Contains(string(main), "dataCheckedAt") || strings.Contains(string(main), "测试站点") {
+			t.Errorf("主存储文件 = %s，期望只包含检查时间等小字段", main)
+		}
+	})
+}
+
+func TestFileStoreLegacyFile(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// 旧版本把全部状态写在一个文件中
+	legacy := NewMemoryStore()
+	data := model.ProcessedData{
+		Time:  "2026-01-19 07:50:56",
+		Items: []model.SiteItem{{SiteName: "测试站点", SiteID: "123", ID: 1}},
+	}
+	if err := legacy.SaveData(ctx, data); err != nil {
+		t.Fatalf("SaveData() error = %v", err)
+	}
+	content, err := json.Marshal(legacy.state)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, fileStoreName), content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	for range 2 {
+		store, err := NewFileStore(dir)
+		if err != nil {
+			t.Fatalf("NewFileStore() error = %v", err)
+		}
+		if loaded, err := store.LoadData(ctx); err != nil || loaded.Time != data.Time {
+			t.Fatalf("LoadData() = %+v, %v", loaded, err)
+		}
+		if metas, _ := store.ListSnapshots(ctx); len(metas) != 1 {
+			t.Errorf("ListSnapshots() = %+v，期望保留旧文件中的快照", metas)
+		}
+	}
+
+	main, err := os.ReadFile(filepath.Join(dir, fileStoreName))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(main), "测试站点") {
+		t.Errorf("拆分后主存储文件仍包含数据: %s", main)
+	}
+}
+
+func TestFileStoreCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, fileStoreName), []byte("{broken"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := NewFileStore(dir); err == nil {
+		t.Error("NewFileStore() 期望在文件损坏时返回错误")
+	}
+}
+
+func TestFileStoreWriteFailureKeepsState(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+
+	// 数据目录被删除后写入失败，内存状态不应被修改
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("RemoveAll() error = %v", err)
+	}
+	data := model.ProcessedData{
+		Time:  "2026-01-19 07:50:56",
+		Items: []model.SiteItem{{SiteName: "测试站点", SiteID: "123", ID: 1}},
+	}
+	if err := store.SaveData(ctx, data); err == nil {
+		t.Fatal("SaveData() 期望返回错误")
+	}
+	if exists, _ := store.DataExists(ctx); exists {
+		t.Error("写入失败后 DataExists() 应为 false")
+	}
+}
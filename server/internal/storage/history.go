@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+// dateFormat 仅日期的快照查询格式
+const dateFormat = "2006-01-02"
+
+// historyRecord 历史快照存储格式（内存/文件后端）
+type historyRecord struct {
+	Meta   model.SnapshotMeta `json:"meta"`
+	Data   json.RawMessage    `json:"data"`
+	Report json.RawMessage    `json:"report,omitempty"` // 解析报告（旧快照没有）
+}
+
+// newSnapshotMeta 生成快照元信息
+func newSnapshotMeta(data model.ProcessedData, savedAt time.Time) model.SnapshotMeta {
+	return model.SnapshotMeta{
+		Time:      data.Time,
+		ItemCount: len(data.Items),
+		SavedAt:   savedAt,
+	}
+}
+
+// decodeReport 解析保存的解析报告
+func decodeReport(jsonData []byte) (*model.ParseReport, error) {
+	var report model.ParseReport
+	if err := json.Unmarshal(jsonData, &report); err != nil {
+		return nil, fmt.Errorf("%s: %w", errJSONUnmarshalFailed, err)
+	}
+	return &report, nil
+}
+
+// sortSnapshots 按数据时间倒序排列（time 字段为固定格式，字符串序即时间序）
+func sortSnapshots(metas []model.SnapshotMeta) {
+	slices.SortFunc(metas, func(a, b model.SnapshotMeta) int {
+		return strings.Compare(b.Time, a.Time)
+	})
+}
+
+// expiredSnapshots 按保留策略找出需要清理的快照（metas 需已倒序）
+// maxCount 为 0 表示不限数量，maxAge 为 0 表示不限时长
+func expiredSnapshots(metas []model.SnapshotMeta, maxCount int, maxAge time.Duration, now time.Time) []string {
+	var expired []string
+	for i, meta := range metas {
+		if (maxCount > 0 && i >= maxCount) || (maxAge > 0 && now.Sub(meta.SavedAt) > maxAge) {
+			expired = append(expired, meta.Time)
+		}
+	}
+	return expired
+}
+
+// historyRetention 读取历史快照保留策略
+func historyRetention() (int, time.Duration) {
+	cfg := config.Get()
+	return cfg.HistoryMaxCount, cfg.HistoryMaxAge
+}
+
+// parseSnapshotTime 解析快照查询时间（model.DataTimeLayout 或 dateFormat）
+// 只给日期时取当天最后一秒；两边都不含时区，比较时统一按 UTC 解析
+func parseSnapshotTime(at string) (time.Time, error) {
+	at = strings.TrimSpace(at)
+	if t, err := time.Parse(model.DataTimeLayout, at); err == nil {
+		return t, nil
+	}
+	if day, err := time.Parse(dateFormat, at); err == nil {
+		return day.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %q，应为 %s 或 %s", ErrSnapshotTimeInvalid, at, model.DataTimeLayout, dateFormat)
+}
+
+// resolveSnapshot 找出与 at 对应的快照时间（metas 需已倒序）
+// 优先精确匹配，否则返回不晚于 at 的最近快照；只给日期时匹配当天最后一个快照
+// at 格式错误时返回 ErrSnapshotTimeInvalid，没有匹配的快照时返回 ErrSnapshotNotFound
+func resolveSnapshot(metas []model.SnapshotMeta, at string) (string, error) {
+	target, err := parseSnapshotTime(at)
+	if err != nil {
+		return "", err
+	}
+
+	for _, meta := range metas {
+		saved, err := time.Parse(model.DataTimeLayout, meta.Time)
+		if err != nil {
+			continue // 保存时已校验，格式错误的快照无法比较
+		}
+		if !saved.After(target) {
+			return meta.Time, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrSnapshotNotFound, at)
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"top1000/internal/model"
+)
+
+func TestExpiredSnapshots(t *testing.T) {
+	now := time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)
+	metas := []model.SnapshotMeta{
+		{Time: "2026-01-19 07:50:56", SavedAt: now.Add(-1 * time.Hour)},
+		{Time: "2026-01-18 07:50:56", SavedAt: now.Add(-25 * time.Hour)},
+		{Time: "2026-01-17 07:50:56", SavedAt: now.Add(-49 * time.Hour)},
+	}
+
+	tests := []struct {
+		name     string
+		maxCount int
+		maxAge   time.Duration
+		want     []string
+	}{
+		{name: "不限制", want: nil},
+		{name: "按数量", maxCount: 2, want: []string{"2026-01-17 07:50:56"}},
+		{name: "按时长", maxAge: 24 * time.Hour, want: []string{"2026-01-18 07:50:56", "2026-01-17 07:50:56"}},
+		{name: "数量和时长同时生效", maxCount: 1, maxAge: 48 * time.Hour, want: []string{"2026-01-18 07:50:56", "2026-01-17 07:50:56"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expiredSnapshots(metas, tt.maxCount, tt.maxAge, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expiredSnapshots() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expiredSnapshots()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestResolveSnapshot(t *testing.T) {
+	metas := []model.SnapshotMeta{
+		{Time: "2026-01-19 07:50:56"},
+		{Time: "2026-01-18 07:50:56"},
+	}
+
+	tests := []struct {
+		name    string
+		at      string
+		want    string
+		wantErr error
+	}{
+		{name: "精确匹配", at: "2026-01-18 07:50:56", want: "2026-01-18 07:50:56"},
+		{name: "取不晚于该时间的快照", at: "2026-01-19 07:00:00", want: "2026-01-18 07:50:56"},
+		{name: "仅日期", at: "2026-01-19", want: "2026-01-19 07:50:56"},
+		{name: "前后空白", at: " 2026-01-19 ", want: "2026-01-19 07:50:56"},
+		{name: "早于所有快照", at: "2026-01-01", wantErr: ErrSnapshotNotFound},
+		{name: "无法解析", at: "foo", wantErr: ErrSnapshotTimeInvalid},
+		{name: "RFC 3339 格式", at: "2026-01-19T10:00:00", wantErr: ErrSnapshotTimeInvalid},
+		{name: "日期不存在", at: "2026-02-30", wantErr: ErrSnapshotTimeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSnapshot(metas, tt.at)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("resolveSnapshot() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
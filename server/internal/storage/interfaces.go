@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 	"top1000/internal/model"
 )
 
@@ -17,35 +18,108 @@ type DataStore interface {
 	// DataExists 检查数据是否存在
 	DataExists(ctx context.Context) (bool, error)
 
-	// IsDataExpired 检查数据是否过期（基于时间字段）
+	// IsDataExpired 检查数据是否过期（基于时间字段和最近检查时间）
 	IsDataExpired(ctx context.Context) (bool, error)
+
+	// TouchData 记录上游数据未变化（只更新 LastCheckedAt，不重写数据和历史快照）
+	// 数据不存在时返回错误
+	TouchData(ctx context.Context, checkedAt time.Time) error
 }
 
 // SitesStore 站点数据存储接口
 // 分离关注点，独立接口
 type SitesStore interface {
-	// LoadSitesData 加载站点数据
-	LoadSitesData(ctx context.Context) (any, error)
+	// LoadSitesData 加载站点目录
+	LoadSitesData(ctx context.Context) (*model.SiteCatalog, error)
 
-	// SaveSitesData 保存站点数据
-	SaveSitesData(ctx context.Context, data any) error
+	// SaveSitesData 保存站点目录（验证失败时拒绝保存）
+	SaveSitesData(ctx context.Context, data model.SiteCatalog) error
 
 	// SitesDataExists 检查站点数据是否存在
 	SitesDataExists(ctx context.Context) (bool, error)
+
+	// SitesDataTTL 站点数据剩余有效期（不存在时返回0）
+	SitesDataTTL(ctx context.Context) (time.Duration, error)
+}
+
+// HistoryStore 历史快照存储接口
+// 每次 SaveData 成功后按数据 time 字段自动归档（包括数据的解析报告），并按配置的数量/时长清理
+type HistoryStore interface {
+	// ListSnapshots 列出所有历史快照（按数据时间倒序，最新在前）
+	ListSnapshots(ctx context.Context) ([]model.SnapshotMeta, error)
+
+	// LoadSnapshot 加载指定时间的快照
+	// at 可以是完整时间（2006-01-02 15:04:05）或日期（2006-01-02），
+	// 没有精确匹配时返回不晚于该时间的最近一个快照；格式错误时返回 ErrSnapshotTimeInvalid
+	LoadSnapshot(ctx context.Context, at string) (*model.ProcessedData, error)
+
+	// SaveCrawlReport 保存最近一次爬取的解析报告（数据被拒绝或解析失败时也保存）
+	SaveCrawlReport(ctx context.Context, report model.ParseReport) error
+
+	// LoadCrawlReport 加载最近一次爬取的解析报告，没有时返回 ErrReportNotFound
+	LoadCrawlReport(ctx context.Context) (*model.ParseReport, error)
+
+	// RewriteSnapshot 用重新解析的数据覆盖同一时间的已有快照（保留原归档时间）
+	// 当前数据是同一时间时一并覆盖；快照不存在时返回 ErrSnapshotNotFound
+	RewriteSnapshot(ctx context.Context, data model.ProcessedData) error
+}
+
+// RawStore 上游原始内容存储接口
+// 原始内容压缩保存，按 RAW_MAX_COUNT/RAW_MAX_AGE 清理，供解析器修复后重新解析
+type RawStore interface {
+	// SaveRaw 保存一次爬取的原始内容
+	SaveRaw(ctx context.Context, raw model.RawPayload) error
+
+	// ListRaw 列出保存的原始内容（最新在前，不含内容）
+	ListRaw(ctx context.Context) ([]model.RawMeta, error)
+
+	// LoadRaw 加载指定的原始内容，不存在时返回 ErrRawNotFound
+	LoadRaw(ctx context.Context, id string) (*model.RawPayload, error)
+}
+
+// CredentialStore 用户站点凭据存储接口
+// 只保存调用方加密后的内容（见 internal/credentials），存储后端不接触明文
+type CredentialStore interface {
+	// SaveCredentials 保存用户的凭据密文（覆盖已有内容）
+	SaveCredentials(ctx context.Context, user string, sealed []byte) error
+
+	// LoadCredentials 加载用户的凭据密文，没有时返回 ErrCredentialsNotFound
+	LoadCredentials(ctx context.Context, user string) ([]byte, error)
+
+	// DeleteCredentials 删除用户的凭据（不存在时不报错）
+	DeleteCredentials(ctx context.Context, user string) error
+
+	// UpdateCredentials 原子地修改用户的凭据密文，并发修改同一用户时不会丢失其中一次
+	// fn 收到当前密文（没有时为 nil），返回新密文（nil 表示删除）；fn 返回错误时不做修改，
+	// 与其他修改冲突时 fn 会用最新密文重新调用
+	UpdateCredentials(ctx context.Context, user string, fn func(sealed []byte) ([]byte, error)) error
+}
+
+// QuarantineStore 隔离区接口（被异常检测拒绝的数据）
+// 只保留最近 config.DefaultQuarantineMax 条，供人工检查
+type QuarantineStore interface {
+	// Quarantine 保存一条被拒绝的数据
+	Quarantine(ctx context.Context, entry model.QuarantineEntry) error
+
+	// ListQuarantine 列出隔离的数据（最新在前，包含完整数据）
+	ListQuarantine(ctx context.Context) ([]model.QuarantineEntry, error)
 }
 
 // UpdateLock 更新锁接口（并发控制）
 // 分离锁逻辑，方便测试和替换实现
 type UpdateLock interface {
-	// IsUpdating 检查是否正在更新
-	IsUpdating() bool
+	// Do 合并同名的并发刷新：同一时间只执行一次 fn，并发调用者等待并共享同一个结果
+	// fn 在调用者之外的 goroutine 中运行（需自行控制超时），ctx 只控制等待时间
+	Do(ctx context.Context, name string, fn func() error) error
 
-	// SetUpdating 设置更新标记
-	SetUpdating(bool)
+	// InFlight 检查指定名称的刷新是否正在进行
+	InFlight(name string) bool
 
-	// IsSitesUpdating 检查是否正在更新站点数据
-	IsSitesUpdating() bool
+	// Wait 等待同名的刷新结束并返回它的结果，不发起新的刷新
+	// 没有正在进行的刷新时立即返回 false；ctx 只控制等待时间
+	Wait(ctx context.Context, name string) (bool, error)
 
-	// SetSitesUpdating 设置站点数据更新标记
-	SetSitesUpdating(bool)
+	// AcquireLease 获取刷新租约（Redis 后端为集群级，其余后端为进程级）
+	// 租约已被持有时返回 ErrLeaseHeld
+	AcquireLease(ctx context.Context, name string) (Lease, error)
 }
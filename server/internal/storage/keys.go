@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"top1000/internal/config"
+)
+
+// redisKeys Redis key 布局
+// 存储包使用的所有 key 都由这里生成，统一以命名空间前缀开头，
+// 多个环境共享同一个 Redis 库时只需配置不同的 REDIS_KEY_PREFIX
+type redisKeys struct {
+	prefix string
+}
+
+// newRedisKeys 创建指定命名空间的 key 布局（前缀为空时使用默认命名空间）
+func newRedisKeys(prefix string) redisKeys {
+	prefix = strings.TrimRight(prefix, ":")
+	if prefix == "" {
+		prefix = config.DefaultRedisKeyPrefix
+	}
+	return redisKeys{prefix: prefix}
+}
+
+// redisKeysFor 按客户端选择 key 布局（集群客户端使用带哈希标签的前缀，见 hashTagged）
+func redisKeysFor(client redis.UniversalClient, prefix string) redisKeys {
+	keys := newRedisKeys(prefix)
+	if _, ok := client.(*redis.ClusterClient); ok {
+		return keys.hashTagged()
+	}
+	return keys
+}
+
+// hashTagged 集群模式的 key 布局：前缀中没有哈希标签时用 {} 包裹（top1000 -> {top1000}）
+// 集群按哈希标签分配哈希槽，命名空间内的所有 key 落在同一个槽，
+// 写入数据和历史快照等多 key 事务在集群中仍然是一个 MULTI/EXEC
+func (k redisKeys) hashTagged() redisKeys {
+	if hasHashTag(k.prefix) {
+		return k
+	}
+	return redisKeys{prefix: "{" + k.prefix + "}"}
+}
+
+// hasHashTag 是否包含 Redis 集群哈希标签（第一个 { 之后有非空内容和 }）
+func hasHashTag(s string) bool {
+	_, rest, ok := strings.Cut(s, "{")
+	return ok && strings.IndexByte(rest, '}') > 0
+}
+
+// legacyRedisKeys 旧版本使用的固定 key（top1000:*）
+var legacyRedisKeys = newRedisKeys(config.DefaultRedisKeyPrefix)
+
+// key 拼接命名空间内的 key
+func (k redisKeys) key(parts ...string) string {
+	return k.prefix + ":" + strings.Join(parts, ":")
+}
+
+// data Top1000 数据
+func (k redisKeys) data() string {
+	return k.key("data")
+}
+
+// dataCheckedAt Top1000 数据最近一次确认上游的时间（Unix 毫秒）
+func (k redisKeys) dataCheckedAt() string {
+	return k.key("data", "checked")
+}
+
+// sites 站点数据
+func (k redisKeys) sites() string {
+	return k.key("sites")
+}
+
+// historyMeta 历史快照元信息（hash，field 为数据时间）
+func (k redisKeys) historyMeta() string {
+	return k.key("history", "meta")
+}
+
+// historyData 历史快照内容（hash，field 为数据时间）
+func (k redisKeys) historyData() string {
+	return k.key("history", "data")
+}
+
+// historyReport 历史快照的解析报告（hash，field 为数据时间）
+func (k redisKeys) historyReport() string {
+	return k.key("history", "report")
+}
+
+// crawlReport 最近一次爬取的解析报告
+func (k redisKeys) crawlReport() string {
+	return k.key("crawl", "report")
+}
+
+// quarantine 隔离区（list，最新在前）
+func (k redisKeys) quarantine() string {
+	return k.key("quarantine")
+}
+
+// rawMeta 上游原始内容元信息（hash，field 为记录 ID）
+func (k redisKeys) rawMeta() string {
+	return k.key("raw", "meta")
+}
+
+// rawBody 上游原始内容（hash，field 为记录 ID，压缩保存）
+func (k redisKeys) rawBody() string {
+	return k.key("raw", "body")
+}
+
+// credentials 用户站点凭据（hash，field 为用户名，内容已加密）
+func (k redisKeys) credentials() string {
+	return k.key("credentials")
+}
+
+// lock 刷新租约
+func (k redisKeys) lock(name string) string {
+	return k.key("lock", name)
+}
+
+// persistent 需要迁移的持久化 key（租约是临时 key，不迁移）
+func (k redisKeys) persistent() []string {
+	return []string{k.data(), k.dataCheckedAt(), k.sites(), k.historyMeta(), k.historyData(), k.historyReport(), k.crawlReport(), k.quarantine(), k.rawMeta(), k.rawBody(), k.credentials()}
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"top1000/internal/model"
+)
+
+func TestRedisKeys(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		wantData string
+		wantLock string
+	}{
+		{prefix: "", wantData: "top1000:data", wantLock: "top1000:lock:sites"},
+		{prefix: "top1000", wantData: "top1000:data", wantLock: "top1000:lock:sites"},
+		{prefix: "staging:", wantData: "staging:data", wantLock: "staging:lock:sites"},
+		{prefix: "{prod}", wantData: "{prod}:data", wantLock: "{prod}:lock:sites"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			keys := newRedisKeys(tt.prefix)
+			if got := keys.data(); got != tt.wantData {
+				t.Errorf("data() = %v, want %v", got, tt.wantData)
+			}
+			if got := keys.lock(LeaseSites); got != tt.wantLock {
+				t.Errorf("lock() = %v, want %v", got, tt.wantLock)
+			}
+		})
+	}
+}
+
+func TestRedisKeysHashTagged(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		wantData string
+	}{
+		{prefix: "top1000", wantData: "{top1000}:data"},
+		{prefix: "staging:top1000", wantData: "{staging:top1000}:data"},
+		{prefix: "{prod}", wantData: "{prod}:data"},
+		{prefix: "app:{prod}", wantData: "app:{prod}:data"},
+		{prefix: "{}x", wantData: "{{}x}:data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if got := newRedisKeys(tt.prefix).hashTagged().data(); got != tt.wantData {
+				t.Errorf("hashTagged().data() = %v, want %v", got, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestRedisKeyPrefixIsolation(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+
+	newStore := func(prefix string) *RedisStore {
+		store := NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+		store.keys = newRedisKeys(prefix)
+		return store
+	}
+	staging, production := newStore("staging"), newStore("production")
+
+	data := model.ProcessedData{
+		Time:  "2026-01-19 07:50:56",
+		Items: []model.SiteItem{{SiteName: "测试站点", SiteID: "1", ID: 1}},
+	}
+	if err := staging.SaveData(ctx, data); err != nil {
+		t.Fatalf("SaveData() error = %v", err)
+	}
+
+	if exists, _ := production.DataExists(ctx); exists {
+		t.Error("不同命名空间的数据不应互相可见")
+	}
+	if metas, _ := production.ListSnapshots(ctx); len(metas) != 0 {
+		t.Errorf("不同命名空间的历史快照不应互相可见: %v", metas)
+	}
+
+	lease, err := staging.AcquireLease(ctx, LeaseTop1000)
+	if err != nil {
+		t.Fatalf("AcquireLease() error = %v", err)
+	}
+	defer lease.Release(ctx)
+	other, err := production.AcquireLease(ctx, LeaseTop1000)
+	if err != nil {
+		t.Fatalf("不同命名空间的租约应互不影响: %v", err)
+	}
+	_ = other.Release(ctx)
+}
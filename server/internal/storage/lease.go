@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// 租约名称（每类刷新任务一个）
+const (
+	LeaseTop1000 = "top1000" // Top1000 数据刷新
+	LeaseSites   = "sites"   // 站点数据刷新
+)
+
+// ErrLeaseHeld 租约已被其他持有者占用
+var ErrLeaseHeld = errors.New("刷新任务正在其他实例执行")
+
+// ErrLeaseLost 持有期间租约丢失（已过期或被其他实例获取）
+var ErrLeaseLost = errors.New("刷新租约已丢失")
+
+// Lease 刷新租约
+// 持有期间自动续期，持有者崩溃时在 TTL 后自动过期
+type Lease interface {
+	// Done 租约丢失时关闭（正常释放时不关闭）
+	// 关闭后其他实例可能已经获取租约，持有者必须停止写入
+	Done() <-chan struct{}
+
+	// Release 释放租约（只会释放自己持有的租约）
+	Release(ctx context.Context) error
+}
+
+// WithLease 返回租约丢失时取消的 context（context.Cause 为 ErrLeaseLost）
+// 持有者用它执行刷新，租约丢失后爬取和保存随之中断
+func WithLease(parent context.Context, lease Lease) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	go func() {
+		select {
+		case <-lease.Done():
+			cancel(ErrLeaseLost)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// localLease 进程内租约（内存/文件后端使用，单实例部署无需跨进程协调）
+type localLease struct {
+	lock *localLock
+	name string
+	once sync.Once
+
+	// lost 进程内租约不会丢失，始终不关闭
+	lost chan struct{}
+}
+
+// AcquireLease 获取进程内租约
+func (l *localLock) AcquireLease(ctx context.Context, name string) (Lease, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	l.leaseMutex.Lock()
+	defer l.leaseMutex.Unlock()
+
+	if l.leases == nil {
+		l.leases = make(map[string]bool)
+	}
+	if l.leases[name] {
+		return nil, ErrLeaseHeld
+	}
+	l.leases[name] = true
+
+	return &localLease{lock: l, name: name, lost: make(chan struct{})}, nil
+}
+
+// Done 进程内租约不会丢失
+func (l *localLease) Done() <-chan struct{} {
+	return l.lost
+}
+
+// Release 释放进程内租约（重复调用安全）
+func (l *localLease) Release(ctx context.Context) error {
+	l.once.Do(func() {
+		l.lock.leaseMutex.Lock()
+		defer l.lock.leaseMutex.Unlock()
+		delete(l.lock.leases, l.name)
+	})
+	return nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+// localLock 进程内更新锁（UpdateLock 的默认实现）
+// 各存储后端内嵌使用，避免重复实现同一套合并逻辑
+type localLock struct {
+	// 正在进行的刷新（按名称合并）
+	flights     map[string]*flight
+	flightMutex sync.Mutex
+
+	// 进程内租约（见 lease.go）
+	leases     map[string]bool
+	leaseMutex sync.Mutex
+}
+
+// flight 一次正在进行的刷新，结束后所有等待者共享同一个结果
+type flight struct {
+	done chan struct{}
+	err  error
+}
+
+// Do 合并同名的并发刷新
+// 同一时间只执行一次 fn，期间的其他调用者等待并共享它的结果；
+// ctx 只控制当前调用者的等待时间，调用者超时不会中断正在进行的刷新
+func (l *localLock) Do(ctx context.Context, name string, fn func() error) error {
+	l.flightMutex.Lock()
+	f, ok := l.flights[name]
+	if !ok {
+		f = &flight{done: make(chan struct{})}
+		if l.flights == nil {
+			l.flights = make(map[string]*flight)
+		}
+		l.flights[name] = f
+		go l.run(name, f, fn)
+	}
+	l.flightMutex.Unlock()
+
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Wait 等待同名的刷新结束并共享它的结果（没有正在进行的刷新时返回 false）
+func (l *localLock) Wait(ctx context.Context, name string) (bool, error) {
+	l.flightMutex.Lock()
+	f, ok := l.flights[name]
+	l.flightMutex.Unlock()
+	if !ok {
+		return false, nil
+	}
+
+	select {
+	case <-f.done:
+		return true, f.err
+	case <-ctx.Done():
+		return true, ctx.Err()
+	}
+}
+
+// run 执行刷新并通知所有等待者
+func (l *localLock) run(name string, f *flight, fn func() error) {
+	defer func() {
+		l.flightMutex.Lock()
+		delete(l.flights, name)
+		l.flightMutex.Unlock()
+		close(f.done)
+	}()
+
+	f.err = fn()
+}
+
+// InFlight 检查指定名称的刷新是否正在进行
+func (l *localLock) InFlight(name string) bool {
+	l.flightMutex.Lock()
+	defer l.flightMutex.Unlock()
+	_, ok := l.flights[name]
+	return ok
+}
@@ -0,0 +1,672 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+// MemoryStore 内存实现（DataStore + SitesStore + HistoryStore + QuarantineStore + RawStore + CredentialStore + UpdateLock）
+// 不依赖 Redis，适用于本地开发和 CI，进程退出后数据丢失
+type MemoryStore struct {
+	mu    sync.RWMutex
+	state memoryState
+
+	// name 后端名称（用于日志）
+	name string
+
+	// now 时间函数（测试时可替换）
+	now func() time.Time
+
+	// persist 状态变更钩子（FileStore 用于落盘，返回错误时放弃本次变更）
+	persist func(prev, next *memoryState) error
+
+	// 更新锁（进程内）
+	localLock
+}
+
+// memoryState 内存存储的全部状态（可直接序列化为 JSON 快照）
+// 以 JSON 形式保存数据，保证读写双方拿到的是独立副本（与 Redis 行为一致）
+type memoryState struct {
+	Data          json.RawMessage `json:"data,omitempty"`
+	DataCheckedAt time.Time       `json:"dataCheckedAt,omitzero"`
+	Sites         json.RawMessage `json:"sites,omitempty"`
+	SitesExpireAt time.Time       `json:"sitesExpireAt,omitzero"`
+
+	// History 历史快照（key 为数据时间）
+	History map[string]historyRecord `json:"history,omitempty"`
+
+	// Quarantine 隔离区（最新在前，每条为序列化后的 QuarantineEntry）
+	Quarantine []json.RawMessage `json:"quarantine,omitempty"`
+
+	// CrawlReport 最近一次爬取的解析报告
+	CrawlReport json.RawMessage `json:"crawlReport,omitempty"`
+
+	// Raw 上游原始内容（最新在前）
+	Raw []rawRecord `json:"raw,omitempty"`
+
+	// Credentials 用户站点凭据密文（key 为用户名）
+	Credentials map[string][]byte `json:"credentials,omitempty"`
+}
+
+// NewMemoryStore 创建内存存储实例
+// 返回的实例同时实现 DataStore、SitesStore、HistoryStore、QuarantineStore、RawStore、CredentialStore、UpdateLock 七个接口
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{name: "内存", now: time.Now}
+}
+
+// AsDataStore 将 MemoryStore 转换为 DataStore 接口
+func (m *MemoryStore) AsDataStore() DataStore {
+	return m
+}
+
+// AsSitesStore 将 MemoryStore 转换为 SitesStore 接口
+func (m *MemoryStore) AsSitesStore() SitesStore {
+	return m
+}
+
+// AsHistoryStore 将 MemoryStore 转换为 HistoryStore 接口
+func (m *MemoryStore) AsHistoryStore() HistoryStore {
+	return m
+}
+
+// AsQuarantineStore 将 MemoryStore 转换为 QuarantineStore 接口
+func (m *MemoryStore) AsQuarantineStore() QuarantineStore {
+	return m
+}
+
+// AsRawStore 将 MemoryStore 转换为 RawStore 接口
+func (m *MemoryStore) AsRawStore() RawStore {
+	return m
+}
+
+// AsCredentialStore 将 MemoryStore 转换为 CredentialStore 接口
+func (m *MemoryStore) AsCredentialStore() CredentialStore {
+	return m
+}
+
+// AsUpdateLock 将 MemoryStore 转换为 UpdateLock 接口
+func (m *MemoryStore) AsUpdateLock() UpdateLock {
+	return m
+}
+
+// ===== DataStore 接口实现 =====
+
+// LoadData 加载数据
+func (m *MemoryStore) LoadData(ctx context.Context) (*model.ProcessedData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	jsonData, checkedAt := m.state.Data, m.state.DataCheckedAt
+	m.mu.RUnlock()
+
+	if jsonData == nil {
+		return nil, fmt.Errorf("%s", errDataNotFound)
+	}
+
+	var data model.ProcessedData
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		return nil, fmt.Errorf("%s: %w", errJSONUnmarshalFailed, err)
+	}
+	data.LastCheckedAt = checkedAt
+
+	log.Printf("从%s加载数据成功（共 %d 条记录）", m.name, len(data.Items))
+	return &data, nil
+}
+
+// SaveData 保存数据
+func (m *MemoryStore) SaveData(ctx context.Context, data model.ProcessedData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := data.Validate(); err != nil {
+		log.Printf("数据验证失败，拒绝保存: %v", err)
+		return fmt.Errorf("%s: %w", errDataInvalid, err)
+	}
+
+	// LastCheckedAt 单独保存，不写入数据和历史快照
+	data.LastCheckedAt = time.Time{}
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+	}
+
+	record := historyRecord{Meta: newSnapshotMeta(data, m.now()), Data: jsonData}
+	if data.Report != nil {
+		if record.Report, err = json.Marshal(data.Report); err != nil {
+			return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+		}
+	}
+	err = m.update(func(s *memoryState) {
+		s.Data = jsonData
+		s.DataCheckedAt = time.Time{} // 新数据重新开始计算
+		s.History = m.archive(s.History, record)
+	})
+	if err != nil {
+		log.Printf("保存数据失败: %v", err)
+		return fmt.Errorf("%s: %w", errStoreSaveFailed, err)
+	}
+
+	log.Printf("数据已保存到%s（过期判断基于数据time字段），已归档快照 %s", m.name, data.Time)
+	return nil
+}
+
+// DataExists 检查数据是否存在
+func (m *MemoryStore) DataExists(ctx context.Context) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("%s: %w", errCheckExistsFailed, err)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.Data != nil, nil
+}
+
+// IsDataExpired 检查数据是否过期
+func (m *MemoryStore) IsDataExpired(ctx context.Context) (bool, error) {
+	data, err := m.LoadData(ctx)
+	if err != nil {
+		return true, nil // 数据不存在或读取失败，认为过期
+	}
+
+	return isDataExpired(data), nil
+}
+
+// TouchData 记录上游数据未变化
+func (m *MemoryStore) TouchData(ctx context.Context, checkedAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.RLock()
+	exists := m.state.Data != nil
+	m.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("%s", errDataNotFound)
+	}
+
+	if err := m.update(func(s *memoryState) { s.DataCheckedAt = checkedAt }); err != nil {
+		return fmt.Errorf("%s: %w", errStoreSaveFailed, err)
+	}
+	return nil
+}
+
+// ===== HistoryStore 接口实现 =====
+
+// ListSnapshots 列出所有历史快照
+func (m *MemoryStore) ListSnapshots(ctx context.Context) ([]model.SnapshotMeta, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return snapshotMetas(m.state.History), nil
+}
+
+// LoadSnapshot 加载指定时间的快照
+func (m *MemoryStore) LoadSnapshot(ctx context.Context, at string) (*model.ProcessedData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	snapshotTime, err := resolveSnapshot(snapshotMetas(m.state.History), at)
+	record := m.state.History[snapshotTime]
+	m.mu.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data model.ProcessedData
+	if err := json.Unmarshal(record.Data, &data); err != nil {
+		return nil, fmt.Errorf("%s: %w", errJSONUnmarshalFailed, err)
+	}
+	if record.Report != nil {
+		report, err := decodeReport(record.Report)
+		if err != nil {
+			return nil, err
+		}
+		data.Report = report
+	}
+	return &data, nil
+}
+
+// SaveCrawlReport 保存最近一次爬取的解析报告
+func (m *MemoryStore) SaveCrawlReport(ctx context.Context, report model.ParseReport) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+	}
+
+	if err := m.update(func(s *memoryState) { s.CrawlReport = jsonData }); err != nil {
+		return fmt.Errorf("%s: %w", errStoreSaveFailed, err)
+	}
+	return nil
+}
+
+// LoadCrawlReport 加载最近一次爬取的解析报告
+func (m *MemoryStore) LoadCrawlReport(ctx context.Context) (*model.ParseReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	jsonData := m.state.CrawlReport
+	m.mu.RUnlock()
+
+	if jsonData == nil {
+		return nil, ErrReportNotFound
+	}
+	return decodeReport(jsonData)
+}
+
+// RewriteSnapshot 用重新解析的数据覆盖同一时间的已有快照
+func (m *MemoryStore) RewriteSnapshot(ctx context.Context, data model.ProcessedData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := data.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", errDataInvalid, err)
+	}
+
+	data.LastCheckedAt = time.Time{}
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+	}
+	var report json.RawMessage
+	if data.Report != nil {
+		if report, err = json.Marshal(data.Report); err != nil {
+			return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+		}
+	}
+
+	// 在写锁内确认快照仍存在，避免与保留策略清理或并发保存交错时把已删除的快照写回
+	errNotFound := fmt.Errorf("%w: %s", ErrSnapshotNotFound, data.Time)
+	err = m.tryUpdate(func(s *memoryState) error {
+		old, ok := s.History[data.Time]
+		if !ok {
+			return errNotFound
+		}
+
+		next := maps.Clone(s.History)
+		next[data.Time] = historyRecord{Meta: newSnapshotMeta(data, old.Meta.SavedAt), Data: jsonData, Report: report}
+		s.History = next
+
+		var current struct {
+			Time string `json:"time"`
+		}
+		if s.Data != nil && json.Unmarshal(s.Data, &current) == nil && current.Time == data.Time {
+			s.Data = jsonData
+		}
+		return nil
+	})
+	if err == errNotFound {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", errStoreSaveFailed, err)
+	}
+	return nil
+}
+
+// archive 返回加入新快照并按保留策略清理后的历史（写时复制，不修改原 map）
+func (m *MemoryStore) archive(history map[string]historyRecord, record historyRecord) map[string]historyRecord {
+	next := make(map[string]historyRecord, len(history)+1)
+	for k, v := range history {
+		next[k] = v
+	}
+	next[record.Meta.Time] = record
+
+	maxCount, maxAge := historyRetention()
+	for _, expired := range expiredSnapshots(snapshotMetas(next), maxCount, maxAge, m.now()) {
+		delete(next, expired)
+	}
+	return next
+}
+
+// snapshotMetas 提取倒序排列的快照元信息
+func snapshotMetas(history map[string]historyRecord) []model.SnapshotMeta {
+	metas := make([]model.SnapshotMeta, 0, len(history))
+	for _, record := range history {
+		metas = append(metas, record.Meta)
+	}
+	sortSnapshots(metas)
+	return metas
+}
+
+// ===== QuarantineStore 接口实现 =====
+
+// Quarantine 保存一条被拒绝的数据
+func (m *MemoryStore) Quarantine(ctx context.Context, entry model.QuarantineEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+	}
+
+	err = m.update(func(s *memoryState) {
+		s.Quarantine = trimQuarantine(append([]json.RawMessage{jsonData}, s.Quarantine...))
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", errStoreSaveFailed, err)
+	}
+	return nil
+}
+
+// ListQuarantine 列出隔离的数据
+func (m *MemoryStore) ListQuarantine(ctx context.Context) ([]model.QuarantineEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	records := m.state.Quarantine
+	m.mu.RUnlock()
+
+	return decodeQuarantine(records)
+}
+
+// ===== RawStore 接口实现 =====
+
+// SaveRaw 保存一次爬取的原始内容
+func (m *MemoryStore) SaveRaw(ctx context.Context, raw model.RawPayload) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	body, err := encodeRaw(raw.Body)
+	if err != nil {
+		return err
+	}
+
+	record := rawRecord{Meta: raw.RawMeta, Body: body}
+	err = m.update(func(s *memoryState) {
+		s.Raw = m.trimRaw(append([]rawRecord{record}, s.Raw...))
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", errStoreSaveFailed, err)
+	}
+	return nil
+}
+
+// ListRaw 列出保存的原始内容
+func (m *MemoryStore) ListRaw(ctx context.Context) ([]model.RawMeta, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return rawMetas(m.state.Raw), nil
+}
+
+// LoadRaw 加载指定的原始内容
+func (m *MemoryStore) LoadRaw(ctx context.Context, id string) (*model.RawPayload, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	i := slices.IndexFunc(m.state.Raw, func(r rawRecord) bool { return r.Meta.ID == id })
+	var record rawRecord
+	if i >= 0 {
+		record = m.state.Raw[i]
+	}
+	m.mu.RUnlock()
+
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRawNotFound, id)
+	}
+
+	body, err := decodePayload(record.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &model.RawPayload{RawMeta: record.Meta, Body: body}, nil
+}
+
+// trimRaw 按保留策略清理原始内容（records 需已倒序，返回新切片）
+func (m *MemoryStore) trimRaw(records []rawRecord) []rawRecord {
+	maxCount, maxAge := rawRetention()
+	expired := expiredRaw(rawMetas(records), maxCount, maxAge, m.now())
+	return slices.DeleteFunc(records, func(r rawRecord) bool { return slices.Contains(expired, r.Meta.ID) })
+}
+
+// rawMetas 提取倒序排列的原始内容元信息
+func rawMetas(records []rawRecord) []model.RawMeta {
+	metas := make([]model.RawMeta, 0, len(records))
+	for _, record := range records {
+		metas = append(metas, record.Meta)
+	}
+	sortRaw(metas)
+	return metas
+}
+
+// ===== CredentialStore 接口实现 =====
+
+// SaveCredentials 保存用户的凭据密文
+func (m *MemoryStore) SaveCredentials(ctx context.Context, user string, sealed []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := m.update(func(s *memoryState) {
+		next := maps.Clone(s.Credentials)
+		if next == nil {
+			next = make(map[string][]byte, 1)
+		}
+		next[user] = slices.Clone(sealed)
+		s.Credentials = next
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", errStoreSaveFailed, err)
+	}
+	return nil
+}
+
+// LoadCredentials 加载用户的凭据密文
+func (m *MemoryStore) LoadCredentials(ctx context.Context, user string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	sealed, ok := m.state.Credentials[user]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrCredentialsNotFound
+	}
+	return slices.Clone(sealed), nil
+}
+
+// DeleteCredentials 删除用户的凭据
+func (m *MemoryStore) DeleteCredentials(ctx context.Context, user string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := m.update(func(s *memoryState) {
+		next := maps.Clone(s.Credentials)
+		delete(next, user)
+		s.Credentials = next
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", errStoreSaveFailed, err)
+	}
+	return nil
+}
+
+// UpdateCredentials 在写锁内修改用户的凭据密文
+func (m *MemoryStore) UpdateCredentials(ctx context.Context, user string, fn func(sealed []byte) ([]byte, error)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var fnErr error
+	err := m.tryUpdate(func(s *memoryState) error {
+		var sealed []byte
+		if sealed, fnErr = fn(slices.Clone(s.Credentials[user])); fnErr != nil {
+			return fnErr
+		}
+
+		next := maps.Clone(s.Credentials)
+		if sealed == nil {
+			delete(next, user)
+		} else {
+			if next == nil {
+				next = make(map[string][]byte, 1)
+			}
+			next[user] = slices.Clone(sealed)
+		}
+		s.Credentials = next
+		return nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", errStoreSaveFailed, err)
+	}
+	return nil
+}
+
+// ===== SitesStore 接口实现 =====
+
+// LoadSitesData 加载站点目录
+func (m *MemoryStore) LoadSitesData(ctx context.Context) (*model.SiteCatalog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	jsonData, ok := m.loadSites()
+	if !ok {
+		return nil, fmt.Errorf("%s", errSitesNotFound)
+	}
+
+	result, err := decodeSiteCatalog(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("从%s加载站点数据成功", m.name)
+	return result, nil
+}
+
+// SaveSitesData 保存站点目录
+func (m *MemoryStore) SaveSitesData(ctx context.Context, data model.SiteCatalog) error {
+	if err := data.Validate(); err != nil {
+		log.Printf("站点数据验证失败，拒绝保存: %v", err)
+		return fmt.Errorf("%s: %w", errDataInvalid, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+	}
+
+	// 与 Redis 保持一致：24小时后过期
+	ttl := config.DefaultSitesExpire
+	err = m.update(func(s *memoryState) {
+		s.Sites = jsonData
+		s.SitesExpireAt = m.now().Add(ttl)
+	})
+	if err != nil {
+		log.Printf("保存站点数据失败: %v", err)
+		return fmt.Errorf("%s: %w", errStoreSaveFailed, err)
+	}
+
+	log.Printf("站点数据已保存到%s（TTL: %v）", m.name, ttl)
+	return nil
+}
+
+// SitesDataExists 检查站点数据是否存在
+func (m *MemoryStore) SitesDataExists(ctx context.Context) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, fmt.Errorf("%s: %w", errCheckExistsFailed, err)
+	}
+
+	_, ok := m.loadSites()
+	return ok, nil
+}
+
+// SitesDataTTL 站点数据剩余有效期
+func (m *MemoryStore) SitesDataTTL(ctx context.Context) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("%s: %w", errCheckExistsFailed, err)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.state.Sites == nil {
+		return 0, nil
+	}
+	return max(m.state.SitesExpireAt.Sub(m.now()), 0), nil
+}
+
+// loadSites 读取未过期的站点数据
+func (m *MemoryStore) loadSites() ([]byte, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.state.Sites == nil || !m.now().Before(m.state.SitesExpireAt) {
+		return nil, false
+	}
+	return m.state.Sites, true
+}
+
+// update 在写锁内修改状态副本，持久化成功后才替换当前状态
+// fn 只能整体替换字段，不能原地修改共享的切片或 map
+func (m *MemoryStore) update(fn func(*memoryState)) error {
+	return m.tryUpdate(func(s *memoryState) error {
+		fn(s)
+		return nil
+	})
+}
+
+// tryUpdate 同 update，fn 返回错误时不修改状态，原样返回该错误
+func (m *MemoryStore) tryUpdate(fn func(*memoryState) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next := m.state
+	if err := fn(&next); err != nil {
+		return err
+	}
+
+	if m.persist != nil {
+		if err := m.persist(&m.state, &next); err != nil {
+			return err
+		}
+	}
+
+	m.state = next
+	return nil
+}
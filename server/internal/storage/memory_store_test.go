@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"top1000/internal/config"
+)
+
+func TestMemoryStoreSitesTTL(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 1, 19, 8, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := store.SaveSitesData(ctx, testSites); err != nil {
+		t.Fatalf("SaveSitesData() error = %v", err)
+	}
+
+	t.Run("TTL内存在", func(t *testing.T) {
+		now = now.Add(config.DefaultSitesExpire - time.Second)
+		if exists, _ := store.SitesDataExists(ctx); !exists {
+			t.Error("SitesDataExists() = false, want true")
+		}
+	})
+
+	t.Run("TTL到期后不存在", func(t *testing.T) {
+		now = now.Add(time.Second)
+		if exists, _ := store.SitesDataExists(ctx); exists {
+			t.Error("SitesDataExists() = true, want false")
+		}
+		if _, err := store.LoadSitesData(ctx); err == nil {
+			t.Error("LoadSitesData() 期望返回错误")
+		}
+	})
+}
+
+func TestMemoryStoreCancelledContext(t *testing.T) {
+	store := NewMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := store.LoadData(ctx); err == nil {
+		t.Error("LoadData() 期望返回 context 错误")
+	}
+	if _, err := store.DataExists(ctx); err == nil {
+		t.Error("DataExists() 期望返回 context 错误")
+	}
+}
+
+func TestInitMemory(t *testing.T) {
+	InitMemory()
+
+	if GetDefaultStore() == nil || GetDefaultSitesStore() == nil || GetDefaultLock() == nil {
+		t.Fatal("InitMemory() 未初始化默认存储")
+	}
+	if err := Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
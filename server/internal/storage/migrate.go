@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"top1000/internal/config"
+)
+
+// 迁移结果状态
+const (
+	MigrationMoved    = "moved"    // 已迁移
+	MigrationPending  = "pending"  // 待迁移（dry run）
+	MigrationMissing  = "missing"  // 旧 key 不存在
+	MigrationConflict = "conflict" // 新 key 已存在，未覆盖
+)
+
+// KeyMigration 单个 key 的迁移结果
+type KeyMigration struct {
+	From   string
+	To     string
+	Status string
+}
+
+// MigrateLegacyKeys 将旧版本固定 key（top1000:*）迁移到 REDIS_KEY_PREFIX 命名空间
+// 需要先调用 InitRedis；命名空间与旧版本相同时无需迁移，返回空结果
+// dryRun 为 true 时只检查不修改
+func MigrateLegacyKeys(ctx context.Context, dryRun bool) ([]KeyMigration, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("Redis未初始化")
+	}
+	to := redisKeysFor(redisClient, config.Get().RedisKeyPrefix)
+	return migrateKeys(ctx, redisClient, legacyRedisKeys, to, dryRun)
+}
+
+// migrateKeys 将 from 布局的持久化 key 移动到 to 布局
+// 目标 key 已存在时不覆盖（可重复执行）；逐个 key 读出再写入，不依赖 RENAME，
+// 因此新旧 key 位于集群的不同哈希槽时也能迁移（见 migrateKey）
+func migrateKeys(ctx context.Context, client redis.UniversalClient, from, to redisKeys, dryRun bool) ([]KeyMigration, error) {
+	if from == to {
+		return nil, nil
+	}
+
+	sources, targets := from.persistent(), to.persistent()
+	results := make([]KeyMigration, 0, len(sources))
+	for i, source := range sources {
+		result := KeyMigration{From: source, To: targets[i]}
+
+		status, err := migrateKey(ctx, client, source, targets[i], dryRun)
+		if err != nil {
+			return results, fmt.Errorf("迁移 %s 失败: %w", source, err)
+		}
+		result.Status = status
+		results = append(results, result)
+
+		if status == MigrationMoved {
+			log.Printf("已迁移: %s -> %s", source, targets[i])
+		}
+	}
+	return results, nil
+}
+
+// migrateKey 移动单个 key（保留剩余 TTL）
+// 新旧 key 可能位于集群的不同哈希槽，写入新 key 和删除旧 key 不能放在同一个事务中：
+// 先写入新 key 并读回确认内容一致，之后才单独删除旧 key，任何一步失败都保留旧 key
+func migrateKey(ctx context.Context, client redis.UniversalClient, source, target string, dryRun bool) (string, error) {
+	keyType, err := client.Type(ctx, source).Result()
+	if err != nil {
+		return "", err
+	}
+	if keyType == "none" {
+		return MigrationMissing, nil
+	}
+
+	exists, err := client.Exists(ctx, target).Result()
+	if err != nil {
+		return "", err
+	}
+	if exists > 0 {
+		return MigrationConflict, nil
+	}
+	if dryRun {
+		return MigrationPending, nil
+	}
+
+	// PTTL 返回负数表示没有过期时间
+	ttl, err := client.PTTL(ctx, source).Result()
+	if err != nil {
+		return "", err
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	content, err := readKey(ctx, client, keyType, source)
+	if err != nil {
+		return "", err
+	}
+	if err := writeKey(ctx, client, target, content, ttl); err != nil {
+		return "", fmt.Errorf("写入 %s 失败（旧 key 已保留）: %w", target, err)
+	}
+
+	written, err := readKey(ctx, client, keyType, target)
+	if err != nil {
+		return "", fmt.Errorf("读回 %s 失败（旧 key 已保留）: %w", target, err)
+	}
+	if !written.equal(content) {
+		return "", fmt.Errorf("%s 写入后内容不一致（旧 key 已保留）", target)
+	}
+
+	if err := client.Del(ctx, source).Err(); err != nil {
+		return "", fmt.Errorf("%s 已写入，删除旧 key 失败: %w", target, err)
+	}
+	return MigrationMoved, nil
+}
+
+// keyContent 迁移中的 key 内容（按类型只使用一个字段）
+type keyContent struct {
+	keyType string
+	value   string
+	fields  map[string]string
+	items   []string
+}
+
+// equal 内容是否一致
+func (c keyContent) equal(other keyContent) bool {
+	return c.keyType == other.keyType && c.value == other.value &&
+		maps.Equal(c.fields, other.fields) && slices.Equal(c.items, other.items)
+}
+
+// readKey 读取 key 的完整内容
+func readKey(ctx context.Context, client redis.UniversalClient, keyType, key string) (keyContent, error) {
+	content := keyContent{keyType: keyType}
+	var err error
+	switch keyType {
+	case "string":
+		content.value, err = client.Get(ctx, key).Result()
+	case "hash":
+		content.fields, err = client.HGetAll(ctx, key).Result()
+	case "list":
+		content.items, err = client.LRange(ctx, key, 0, -1).Result()
+	default:
+		err = fmt.Errorf("不支持的 key 类型: %s", keyType)
+	}
+	return content, err
+}
+
+// writeKey 写入 key 的完整内容（单个 key 的写入和过期时间在同一个事务中）
+func writeKey(ctx context.Context, client redis.UniversalClient, key string, content keyContent, ttl time.Duration) error {
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		switch content.keyType {
+		case "string":
+			pipe.Set(ctx, key, content.value, ttl)
+			return nil
+		case "hash":
+			pipe.HSet(ctx, key, content.fields)
+		case "list":
+			items := make([]any, len(content.items))
+			for i, item := range content.items {
+				items[i] = item
+			}
+			pipe.RPush(ctx, key, items...)
+		}
+		if ttl > 0 {
+			pipe.PExpire(ctx, key, ttl)
+		}
+		return nil
+	})
+	return err
+}
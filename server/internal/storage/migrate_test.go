@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+func TestMigrateKeys(t *testing.T) {
+	ctx := context.Background()
+	data := model.ProcessedData{
+		Time:   "2026-01-19 07:50:56",
+		Items:  []model.SiteItem{{SiteName: "测试站点", SiteID: "1", ID: 1}},
+		Report: &model.ParseReport{Format: model.ReportFormatText, Items: 1},
+	}
+
+	// setup 使用旧版本固定 key 写入数据
+	setup := func(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+		mr := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { client.Close() })
+
+		legacy := NewRedisStore(client)
+		legacy.keys = legacyRedisKeys
+		if err := legacy.SaveData(ctx, data); err != nil {
+			t.Fatalf("SaveData() error = %v", err)
+		}
+		if err := legacy.SaveSitesData(ctx, testSites); err != nil {
+			t.Fatalf("SaveSitesData() error = %v", err)
+		}
+		if err := legacy.TouchData(ctx, time.Now()); err != nil {
+			t.Fatalf("TouchData() error = %v", err)
+		}
+		if err := legacy.SaveCrawlReport(ctx, *data.Report); err != nil {
+			t.Fatalf("SaveCrawlReport() error = %v", err)
+		}
+		if err := legacy.Quarantine(ctx, model.QuarantineEntry{ID: "1", Time: data.Time}); err != nil {
+			t.Fatalf("Quarantine() error = %v", err)
+		}
+		if err := legacy.SaveRaw(ctx, *model.NewRawPayload("test", []byte("原始内容"), time.Now())); err != nil {
+			t.Fatalf("SaveRaw() error = %v", err)
+		}
+		if err := legacy.SaveCredentials(ctx, "alice", []byte("密文")); err != nil {
+			t.Fatalf("SaveCredentials() error = %v", err)
+		}
+		return mr, client
+	}
+	staging := newRedisKeys("staging")
+
+	t.Run("迁移到新命名空间", func(t *testing.T) {
+		mr, client := setup(t)
+
+		results, err := migrateKeys(ctx, client, legacyRedisKeys, staging, false)
+		if err != nil {
+			t.Fatalf("migrateKeys() error = %v", err)
+		}
+		for _, result := range results {
+			if result.Status != MigrationMoved {
+				t.Errorf("%s 状态 = %v, want %v", result.From, result.Status, MigrationMoved)
+			}
+		}
+
+		store := NewRedisStore(client)
+		store.keys = staging
+		loaded, err := store.LoadData(ctx)
+		if err != nil || loaded.Time != data.Time {
+			t.Errorf("迁移后 LoadData() = %v, %v", loaded, err)
+		}
+		if _, err := store.LoadSnapshot(ctx, data.Time); err != nil {
+			t.Errorf("迁移后 LoadSnapshot() error = %v", err)
+		}
+		if ttl := mr.TTL(staging.sites()); ttl <= 0 || ttl > config.DefaultSitesExpire {
+			t.Errorf("站点数据 TTL = %v，应保留剩余有效期", ttl)
+		}
+		for _, key := range legacyRedisKeys.persistent() {
+			if mr.Exists(key) {
+				t.Errorf("旧 key %s 应已删除", key)
+			}
+		}
+
+		// 重复执行是安全的
+		again, err := migrateKeys(ctx, client, legacyRedisKeys, staging, false)
+		if err != nil || again[0].Status != MigrationMissing {
+			t.Errorf("重复迁移 = %v, %v, want %v", again, err, MigrationMissing)
+		}
+	})
+
+	t.Run("dry run不修改数据", func(t *testing.T) {
+		mr, client := setup(t)
+
+		results, err := migrateKeys(ctx, client, legacyRedisKeys, staging, true)
+		if err != nil {
+			t.Fatalf("migrateKeys() error = %v", err)
+		}
+		if results[0].Status != MigrationPending {
+			t.Errorf("状态 = %v, want %v", results[0].Status, MigrationPending)
+		}
+		if !mr.Exists(legacyRedisKeys.data()) || mr.Exists(staging.data()) {
+			t.Error("dry run 不应移动数据")
+		}
+	})
+
+	t.Run("目标已存在时不覆盖", func(t *testing.T) {
+		mr, client := setup(t)
+		_ = mr.Set(staging.data(), "newer")
+
+		results, err := migrateKeys(ctx, client, legacyRedisKeys, staging, false)
+		if err != nil {
+			t.Fatalf("migrateKeys() error = %v", err)
+		}
+		if results[0].Status != MigrationConflict {
+			t.Errorf("状态 = %v, want %v", results[0].Status, MigrationConflict)
+		}
+		if got, _ := mr.Get(staging.data()); got != "newer" {
+			t.Errorf("目标 key 被覆盖: %q", got)
+		}
+		if !mr.Exists(legacyRedisKeys.data()) {
+			t.Error("冲突时应保留旧 key")
+		}
+	})
+
+	t.Run("写入新 key 失败时保留旧 key", func(t *testing.T) {
+		mr, client := setup(t)
+		client.AddHook(failWritesTo{key: staging.data()})
+
+		if _, err := migrateKeys(ctx, client, legacyRedisKeys, staging, false); err == nil {
+			t.Fatal("写入失败时 migrateKeys() 应返回错误")
+		}
+		if !mr.Exists(legacyRedisKeys.data()) {
+			t.Error("写入新 key 失败时不应删除旧 key")
+		}
+	})
+
+	t.Run("集群中迁移到带哈希标签的命名空间", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		cfg := &config.Config{RedisClusterAddrs: []string{mr.Addr()}}
+		opts, err := redisOptions(cfg)
+		if err != nil {
+			t.Fatalf("redisOptions() error = %v", err)
+		}
+		client := newRedisClient(cfg.RedisMode(), opts).(*redis.ClusterClient)
+		t.Cleanup(func() { client.Close() })
+
+		// 旧 key 和新 key 不在同一个哈希槽，迁移不能依赖跨槽事务
+		var issued, executed atomic.Int32
+		client.AddHook(txCounter{&issued})
+		client.OnNewNode(func(node *redis.Client) { node.AddHook(txCounter{&executed}) })
+
+		legacy := NewRedisStore(client)
+		legacy.keys = legacyRedisKeys
+		if err := legacy.SaveSitesData(ctx, testSites); err != nil {
+			t.Fatalf("SaveSitesData() error = %v", err)
+		}
+
+		tagged := redisKeysFor(client, config.DefaultRedisKeyPrefix)
+		results, err := migrateKeys(ctx, client, legacyRedisKeys, tagged, false)
+		if err != nil {
+			t.Fatalf("migrateKeys() error = %v", err)
+		}
+		if i := slices.IndexFunc(results, func(r KeyMigration) bool { return r.From == legacyRedisKeys.sites() }); i < 0 || results[i].Status != MigrationMoved {
+			t.Errorf("站点数据迁移结果 = %v", results)
+		}
+		if mr.Exists(legacyRedisKeys.sites()) || !mr.Exists(tagged.sites()) {
+			t.Error("站点数据应迁移到 " + tagged.sites())
+		}
+		if issued.Load() != executed.Load() {
+			t.Errorf("发起 %d 个事务，节点执行 %d 个，迁移中存在跨哈希槽的事务", issued.Load(), executed.Load())
+		}
+	})
+
+	t.Run("命名空间相同时无需迁移", func(t *testing.T) {
+		_, client := setup(t)
+
+		results, err := migrateKeys(ctx, client, legacyRedisKeys, newRedisKeys(config.DefaultRedisKeyPrefix), false)
+		if err != nil || results != nil {
+			t.Errorf("migrateKeys() = %v, %v, want nil, nil", results, err)
+		}
+	})
+}
+
+// failWritesTo 写入指定 key 的管道返回错误（模拟集群中目标节点写入失败）
+type failWritesTo struct {
+	key string
+}
+
+func (h failWritesTo) DialHook(next redis.DialHook) redis.DialHook          { return next }
+func (h failWritesTo) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+func (h failWritesTo) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if args := cmd.Args(); len(args) > 1 && args[1] == h.key {
+				return errors.New("写入失败")
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+// trimQuarantine 只保留最近的隔离记录（records 需已按时间倒序）
+func trimQuarantine(records []json.RawMessage) []json.RawMessage {
+	return records[:min(len(records), config.DefaultQuarantineMax)]
+}
+
+// decodeQuarantine 解析隔离记录
+func decodeQuarantine(records []json.RawMessage) ([]model.QuarantineEntry, error) {
+	entries := make([]model.QuarantineEntry, 0, len(records))
+	for _, record := range records {
+		var entry model.QuarantineEntry
+		if err := json.Unmarshal(record, &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", errJSONUnmarshalFailed, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"slices"
+	"time"
+
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+// rawRecord 上游原始内容存储格式（内存/文件后端，内容始终以 zstd 压缩）
+type rawRecord struct {
+	Meta model.RawMeta `json:"meta"`
+	Body []byte        `json:"body"`
+}
+
+// encodeRaw 压缩原始内容（上游文本压缩率很高，不受 REDIS_COMPRESSION 影响）
+func encodeRaw(body []byte) ([]byte, error) {
+	return encodePayload(config.CompressionZstd, body)
+}
+
+// sortRaw 按获取时间倒序排列（最新在前）
+func sortRaw(metas []model.RawMeta) {
+	slices.SortFunc(metas, func(a, b model.RawMeta) int {
+		return b.FetchedAt.Compare(a.FetchedAt)
+	})
+}
+
+// expiredRaw 按保留策略找出需要清理的原始内容（metas 需已倒序）
+// maxCount 为 0 表示不限数量，maxAge 为 0 表示不限时长
+func expiredRaw(metas []model.RawMeta, maxCount int, maxAge time.Duration, now time.Time) []string {
+	var expired []string
+	for i, meta := range metas {
+		if (maxCount > 0 && i >= maxCount) || (maxAge > 0 && now.Sub(meta.FetchedAt) > maxAge) {
+			expired = append(expired, meta.ID)
+		}
+	}
+	return expired
+}
+
+// rawRetention 读取原始内容保留策略
+func rawRetention() (int, time.Duration) {
+	cfg := config.Get()
+	return cfg.RawMaxCount, cfg.RawMaxAge
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 租约续期与释放脚本：只有 token 匹配（仍是自己持有）时才操作，
+// 避免租约过期后被其他实例获取时误删或误续期
+var (
+	renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// redisLease Redis 租约（SET NX PX + 定时续期 + 比较后删除）
+type redisLease struct {
+	client redis.Cmdable
+	key    string
+	token  string
+	ttl    time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+	lost chan struct{}
+	once sync.Once
+}
+
+// AcquireLease 获取集群级刷新租约
+// 多个实例共享同一个 Redis 时，同一时间只有一个实例能刷新同类数据
+func (r *RedisStore) AcquireLease(ctx context.Context, name string) (Lease, error) {
+	token, err := newLeaseToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := r.leaseTTL
+	key := r.keys.lock(name)
+
+	ok, err := r.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取刷新租约失败: %w", err)
+	}
+	if !ok {
+		return nil, ErrLeaseHeld
+	}
+
+	lease := &redisLease{
+		client: r.client,
+		key:    key,
+		token:  token,
+		ttl:    ttl,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		lost:   make(chan struct{}),
+	}
+	go lease.keepAlive()
+
+	log.Printf("已获取刷新租约: %s（TTL: %v）", key, ttl)
+	return lease, nil
+}
+
+// keepAlive 每 1/3 TTL 续期一次，直到释放或租约丢失
+// 租约被其他实例获取、或连续续期失败超过 TTL（key 已过期）时关闭 lost 通知持有者
+func (l *redisLease) keepAlive() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+			renewed, err := renewLeaseScript.Run(ctx, l.client, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+			cancel()
+
+			if err != nil {
+				// 网络抖动时继续尝试，租约在 TTL 内仍然有效
+				log.Printf("刷新租约续期失败: %s: %v", l.key, err)
+				if time.Since(renewedAt) < l.ttl {
+					continue
+				}
+			}
+			if err != nil || renewed == 0 {
+				log.Printf("刷新租约已丢失（已过期或被其他实例获取）: %s", l.key)
+				close(l.lost)
+				return
+			}
+			renewedAt = time.Now()
+		}
+	}
+}
+
+// Done 租约丢失时关闭
+func (l *redisLease) Done() <-chan struct{} {
+	return l.lost
+}
+
+// Release 停止续期并释放租约（重复调用安全）
+func (l *redisLease) Release(ctx context.Context) error {
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+
+		if err = releaseLeaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err(); err != nil {
+			err = fmt.Errorf("释放刷新租约失败: %w", err)
+			return
+		}
+		log.Printf("已释放刷新租约: %s", l.key)
+	})
+	return err
+}
+
+// newLeaseToken 生成随机租约令牌（标识持有者）
+func newLeaseToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成租约令牌失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
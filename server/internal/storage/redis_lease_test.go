@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"top1000/internal/config"
+)
+
+// newInstance 模拟共享同一个 Redis 的另一个服务实例
+func newInstance(mr *miniredis.Miniredis, ttl time.Duration) *RedisStore {
+	store := NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	store.leaseTTL = ttl
+	return store
+}
+
+func TestRedisLeaseAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	key := legacyRedisKeys.lock(LeaseTop1000)
+
+	first := newInstance(mr, config.DefaultLockTTL)
+	second := newInstance(mr, config.DefaultLockTTL)
+
+	lease, err := first.AcquireLease(ctx, LeaseTop1000)
+	if err != nil {
+		t.Fatalf("AcquireLease() error = %v", err)
+	}
+
+	t.Run("其他实例无法获取", func(t *testing.T) {
+		if _, err := second.AcquireLease(ctx, LeaseTop1000); !errors.Is(err, ErrLeaseHeld) {
+			t.Errorf("AcquireLease() error = %v, want ErrLeaseHeld", err)
+		}
+	})
+
+	t.Run("租约带有过期时间", func(t *testing.T) {
+		if ttl := mr.TTL(key); ttl <= 0 || ttl > config.DefaultLockTTL {
+			t.Errorf("租约 TTL = %v，期望 (0, %v]", ttl, config.DefaultLockTTL)
+		}
+	})
+
+	t.Run("持有者崩溃后租约过期", func(t *testing.T) {
+		mr.FastForward(config.DefaultLockTTL + time.Second)
+
+		takeover, err := second.AcquireLease(ctx, LeaseTop1000)
+		if err != nil {
+			t.Fatalf("租约过期后 AcquireLease() error = %v", err)
+		}
+
+		// 旧持有者释放时不能删除新持有者的租约
+		if err := lease.Release(ctx); err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+		if !mr.Exists(key) {
+			t.Fatal("旧持有者释放了新持有者的租约")
+		}
+
+		if err := takeover.Release(ctx); err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+		if mr.Exists(key) {
+			t.Error("释放后租约 key 仍然存在")
+		}
+	})
+}
+
+func TestRedisLeaseRenewal(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	key := legacyRedisKeys.lock(LeaseSites)
+
+	ttl := 300 * time.Millisecond
+	store := newInstance(mr, ttl)
+
+	lease, err := store.AcquireLease(ctx, LeaseSites)
+	if err != nil {
+		t.Fatalf("AcquireLease() error = %v", err)
+	}
+	defer lease.Release(ctx)
+
+	// 模拟时间流逝，续期后 TTL 应重新回到完整有效期
+	mr.FastForward(200 * time.Millisecond)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if mr.TTL(key) > 200*time.Millisecond {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Errorf("租约未续期，当前 TTL = %v", mr.TTL(key))
+}
+
+func TestRedisLeaseLost(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	key := legacyRedisKeys.lock(LeaseTop1000)
+
+	ttl := 300 * time.Millisecond
+	store := newInstance(mr, ttl)
+
+	lease, err := store.AcquireLease(ctx, LeaseTop1000)
+	if err != nil {
+		t.Fatalf("AcquireLease() error = %v", err)
+	}
+	defer lease.Release(ctx)
+
+	leaseCtx, cancel := WithLease(ctx, lease)
+	defer cancel()
+
+	// 租约过期后被其他实例获取，续期失败时通知持有者
+	mr.Set(key, "other-instance")
+	select {
+	case <-lease.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("租约丢失后 Done() 未关闭")
+	}
+	<-leaseCtx.Done()
+	if cause := context.Cause(leaseCtx); !errors.Is(cause, ErrLeaseLost) {
+		t.Errorf("context.Cause() = %v, want ErrLeaseLost", cause)
+	}
+
+	// 释放时不能删除其他实例的租约
+	if err := lease.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if got, _ := mr.Get(key); got != "other-instance" {
+		t.Errorf("租约 key = %q，不应被旧持有者删除", got)
+	}
+}
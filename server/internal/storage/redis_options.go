@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/redis/go-redis/v9"
+	"top1000/internal/config"
+)
+
+// newRedisClient 按部署模式创建 Redis 客户端（单机/哨兵/集群）
+// 三种客户端都实现 redis.UniversalClient，RedisStore 无需关心部署模式
+// 不使用 redis.NewUniversalClient：它按地址数量推断集群模式，单个种子节点的集群会被当成单机
+func newRedisClient(mode string, opts *redis.UniversalOptions) redis.UniversalClient {
+	switch mode {
+	case config.RedisModeSentinel:
+		return redis.NewFailoverClient(opts.Failover())
+	case config.RedisModeCluster:
+		return redis.NewClusterClient(opts.Cluster())
+	default:
+		return redis.NewClient(opts.Simple())
+	}
+}
+
+// redisOptions 将配置转换为 go-redis 连接参数
+// REDIS_URL 提供的地址、用户名、密码、库号和 TLS 设置优先于单独的环境变量
+func redisOptions(cfg *config.Config) (*redis.UniversalOptions, error) {
+	opts := &redis.UniversalOptions{
+		Addrs:           []string{cfg.RedisAddr},
+		Username:        cfg.RedisUsername,
+		Password:        cfg.RedisPassword,
+		DB:              cfg.RedisDB,
+		DialTimeout:     dialTimeout,
+		ReadTimeout:     readTimeout,
+		WriteTimeout:    writeTimeout,
+		PoolSize:        cfg.RedisPoolSize,
+		MinIdleConns:    cfg.RedisMinIdleConns,
+		PoolTimeout:     cfg.RedisPoolTimeout,
+		ConnMaxIdleTime: cfg.RedisConnMaxIdleTime,
+	}
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = config.DefaultRedisPoolSize
+	}
+
+	if cfg.RedisURL != "" {
+		parsed, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errRedisURLInvalid, err)
+		}
+		opts.Addrs = []string{parsed.Addr}
+		opts.DB = parsed.DB
+		opts.TLSConfig = parsed.TLSConfig
+		if parsed.Username != "" {
+			opts.Username = parsed.Username
+		}
+		if parsed.Password != "" {
+			opts.Password = parsed.Password
+		}
+	}
+
+	switch cfg.RedisMode() {
+	case config.RedisModeSentinel:
+		opts.MasterName = cfg.RedisSentinelMaster
+		opts.Addrs = cfg.RedisSentinelAddrs
+		opts.SentinelPassword = cfg.RedisSentinelPassword
+	case config.RedisModeCluster:
+		opts.Addrs = cfg.RedisClusterAddrs
+	}
+
+	tlsConfig, err := redisTLSConfig(cfg, opts.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	opts.TLSConfig = tlsConfig
+
+	return opts, nil
+}
+
+// redisTLSConfig 构建 TLS 配置（未启用 TLS 时返回 nil）
+// base 为 rediss:// URL 解析出的配置，在其基础上加载自定义 CA 和客户端证书
+func redisTLSConfig(cfg *config.Config, base *tls.Config) (*tls.Config, error) {
+	enabled := base != nil || cfg.RedisTLS || cfg.RedisTLSCAFile != "" || cfg.RedisTLSCertFile != ""
+	if !enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if base != nil {
+		tlsConfig = base.Clone()
+	}
+
+	if cfg.RedisTLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.RedisTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errRedisTLSInvalid, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: CA证书文件中没有有效证书: %s", errRedisTLSInvalid, cfg.RedisTLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.RedisTLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.RedisTLSCertFile, cfg.RedisTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errRedisTLSInvalid, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
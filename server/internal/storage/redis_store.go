@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -17,35 +16,48 @@ const (
 	dialTimeout  = 10 * time.Second
 	readTimeout  = 5 * time.Second
 	writeTimeout = 5 * time.Second
-	poolSize     = 3
-	minIdleConns = 1
 
-	// Redis TTL 特殊返回值
-	ttlKeyNotExist = -2 * time.Second // key 不存在（已过期删除）
-	ttlKeyNoExpire = -1 * time.Second // key 存在但没有过期时间
+	// Redis TTL 特殊返回值（go-redis 原样返回 -2/-1，不乘以精度）
+	ttlKeyNotExist = time.Duration(-2) // key 不存在（已过期删除）
+	ttlKeyNoExpire = time.Duration(-1) // key 存在但没有过期时间
 
-	// 时间格式常量
-	timeFormat = "2006-01-02 15:04:05" // 数据时间字段格式
+	// credentialsUpdateRetries 修改凭据时与同一用户的其他修改冲突的最大重试次数
+	credentialsUpdateRetries = 10
 )
 
-// RedisStore Redis 实现（DataStore + SitesStore + UpdateLock）
+// RedisStore Redis 实现（DataStore + SitesStore + HistoryStore + QuarantineStore + RawStore + CredentialStore + UpdateLock）
 // 组合多个接口，一个实现完成所有功能
 type RedisStore struct {
-	client *redis.Client
+	client redis.UniversalClient
 
-	// Top1000 数据更新锁
-	isUpdating   bool
-	updateMutex sync.Mutex
+	// key 布局（见 keys.go，集群模式下所有 key 共享一个哈希标签，多 key 事务不会跨槽）
+	keys redisKeys
 
-	// 站点数据更新锁
-	isSitesUpdating   bool
-	sitesUpdateMutex sync.Mutex
+	// 刷新租约有效期（见 redis_lease.go）
+	leaseTTL time.Duration
+
+	// 数据压缩算法（见 codec.go）
+	compression string
+
+	// 更新锁（进程内）
+	localLock
 }
 
 // NewRedisStore 创建 Redis 存储实例
-// 返回的实例同时实现 DataStore、SitesStore、UpdateLock 三个接口
-func NewRedisStore(client *redis.Client) *RedisStore {
-	return &RedisStore{client: client}
+// client 可以是单机、哨兵或集群客户端
+// 返回的实例同时实现 DataStore、SitesStore、HistoryStore、QuarantineStore、RawStore、CredentialStore、UpdateLock 七个接口
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	cfg := config.Get()
+	leaseTTL := cfg.LockTTL
+	if leaseTTL <= 0 {
+		leaseTTL = config.DefaultLockTTL
+	}
+	return &RedisStore{
+		client:      client,
+		keys:        redisKeysFor(client, cfg.RedisKeyPrefix),
+		leaseTTL:    leaseTTL,
+		compression: cfg.RedisCompression,
+	}
 }
 
 // AsDataStore 将 RedisStore 转换为 DataStore 接口
@@ -58,6 +70,26 @@ func (r *RedisStore) AsSitesStore() SitesStore {
 	return r
 }
 
+// AsHistoryStore 将 RedisStore 转换为 HistoryStore 接口
+func (r *RedisStore) AsHistoryStore() HistoryStore {
+	return r
+}
+
+// AsQuarantineStore 将 RedisStore 转换为 QuarantineStore 接口
+func (r *RedisStore) AsQuarantineStore() QuarantineStore {
+	return r
+}
+
+// AsRawStore 将 RedisStore 转换为 RawStore 接口
+func (r *RedisStore) AsRawStore() RawStore {
+	return r
+}
+
+// AsCredentialStore 将 RedisStore 转换为 CredentialStore 接口
+func (r *RedisStore) AsCredentialStore() CredentialStore {
+	return r
+}
+
 // AsUpdateLock 将 RedisStore 转换为 UpdateLock 接口
 func (r *RedisStore) AsUpdateLock() UpdateLock {
 	return r
@@ -67,9 +99,9 @@ func (r *RedisStore) AsUpdateLock() UpdateLock {
 
 // LoadData 加载数据
 func (r *RedisStore) LoadData(ctx context.Context) (*model.ProcessedData, error) {
-	key := config.DefaultRedisKey
+	key := r.keys.data()
 
-	jsonData, err := r.client.Get(ctx, key).Bytes()
+	jsonData, err := r.get(ctx, key)
 	if err != nil {
 		if err == redis.Nil {
 			return nil, fmt.Errorf("%s", errDataNotFound)
@@ -82,6 +114,11 @@ func (r *RedisStore) LoadData(ctx context.Context) (*model.ProcessedData, error)
 		return nil, fmt.Errorf("%s: %w", errJSONUnmarshalFailed, err)
 	}
 
+	// 旧版本没有检查时间，读取失败时只影响过期判断
+	if checkedAt, err := r.client.Get(ctx, r.keys.dataCheckedAt()).Int64(); err == nil {
+		data.LastCheckedAt = time.UnixMilli(checkedAt)
+	}
+
 	log.Printf("从Redis加载数据成功（共 %d 条记录）", len(data.Items))
 	return &data, nil
 }
@@ -93,25 +130,58 @@ func (r *RedisStore) SaveData(ctx context.Context, data model.ProcessedData) err
 		return fmt.Errorf("%s: %w", errDataInvalid, err)
 	}
 
-	jsonData, err := json.Marshal(data)
+	// LastCheckedAt 单独保存，不写入数据和历史快照
+	data.LastCheckedAt = time.Time{}
+	jsonData, err := r.marshal(data)
+	if err != nil {
+		return err
+	}
+
+	meta, err := json.Marshal(newSnapshotMeta(data, time.Now()))
 	if err != nil {
 		return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
 	}
 
-	key := config.DefaultRedisKey
-	// 不设置TTL，数据永久存储
-	if err := r.client.Set(ctx, key, jsonData, 0).Err(); err != nil {
+	var report []byte
+	if data.Report != nil {
+		if report, err = r.marshal(data.Report); err != nil {
+			return err
+		}
+	}
+
+	// 当前数据和历史快照在同一个事务中写入
+	key := r.keys.data()
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// 不设置TTL，数据永久存储
+		pipe.Set(ctx, key, jsonData, 0)
+		pipe.Del(ctx, r.keys.dataCheckedAt()) // 新数据重新开始计算
+
+		pipe.HSet(ctx, r.keys.historyMeta(), data.Time, meta)
+		pipe.HSet(ctx, r.keys.historyData(), data.Time, jsonData)
+		if report != nil {
+			pipe.HSet(ctx, r.keys.historyReport(), data.Time, report)
+		} else {
+			pipe.HDel(ctx, r.keys.historyReport(), data.Time) // 不保留同一时间旧快照的报告
+		}
+		return nil
+	})
+	if err != nil {
 		log.Printf("保存数据到Redis失败: %v", err)
 		return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
 	}
 
-	log.Printf("数据已保存到Redis（永久存储，过期判断基于数据time字段）")
+	log.Printf("数据已保存到Redis（永久存储，过期判断基于数据time字段），已归档快照 %s", data.Time)
+
+	// 清理失败不影响本次保存，下次保存时会再次清理
+	if err := r.pruneHistory(ctx); err != nil {
+		log.Printf("清理历史快照失败: %v", err)
+	}
 	return nil
 }
 
 // DataExists 检查数据是否存在
 func (r *RedisStore) DataExists(ctx context.Context) (bool, error) {
-	key := config.DefaultRedisKey
+	key := r.keys.data()
 
 	exists, err := r.client.Exists(ctx, key).Result()
 	if err != nil {
@@ -129,32 +199,435 @@ func (r *RedisStore) IsDataExpired(ctx context.Context) (bool, error) {
 		return true, nil // 数据不存在或读取失败，认为过期
 	}
 
-	// 解析时间字段（API返回的是北京时间UTC+8，需要转换为UTC）
-	dataTime, err := time.Parse(timeFormat, data.Time)
+	return isDataExpired(data), nil
+}
+
+// TouchData 记录上游数据未变化
+func (r *RedisStore) TouchData(ctx context.Context, checkedAt time.Time) error {
+	exists, err := r.DataExists(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%s", errDataNotFound)
+	}
+
+	if err := r.client.Set(ctx, r.keys.dataCheckedAt(), checkedAt.UnixMilli(), 0).Err(); err != nil {
+		return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
+	}
+	return nil
+}
+
+// ===== QuarantineStore 接口实现 =====
+
+// Quarantine 保存一条被拒绝的数据
+func (r *RedisStore) Quarantine(ctx context.Context, entry model.QuarantineEntry) error {
+	payload, err := r.marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	key := r.keys.quarantine()
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, key, payload)
+		pipe.LTrim(ctx, key, 0, int64(config.DefaultQuarantineMax-1))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
+	}
+	return nil
+}
+
+// ListQuarantine 列出隔离的数据
+func (r *RedisStore) ListQuarantine(ctx context.Context) ([]model.QuarantineEntry, error) {
+	values, err := r.client.LRange(ctx, r.keys.quarantine(), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errRedisReadFailed, err)
+	}
+
+	records := make([]json.RawMessage, 0, len(values))
+	for _, value := range values {
+		record, err := decodePayload([]byte(value))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return decodeQuarantine(records)
+}
+
+// ===== HistoryStore 接口实现 =====
+
+// ListSnapshots 列出所有历史快照
+func (r *RedisStore) ListSnapshots(ctx context.Context) ([]model.SnapshotMeta, error) {
+	values, err := r.client.HGetAll(ctx, r.keys.historyMeta()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errRedisReadFailed, err)
+	}
+
+	metas := make([]model.SnapshotMeta, 0, len(values))
+	for field, value := range values {
+		var meta model.SnapshotMeta
+		if err := json.Unmarshal([]byte(value), &meta); err != nil {
+			log.Printf("跳过无法解析的快照元信息 %s: %v", field, err)
+			continue
+		}
+		metas = append(metas, meta)
+	}
+
+	sortSnapshots(metas)
+	return metas, nil
+}
+
+// LoadSnapshot 加载指定时间的快照
+func (r *RedisStore) LoadSnapshot(ctx context.Context, at string) (*model.ProcessedData, error) {
+	metas, err := r.ListSnapshots(ctx)
 	if err != nil {
-		log.Printf("解析数据时间失败: %v", err)
-		return true, nil // 解析失败，认为过期，强制更新
+		return nil, err
+	}
+
+	snapshotTime, err := resolveSnapshot(metas, at)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := r.client.HGet(ctx, r.keys.historyData(), snapshotTime).Bytes()
+	if err == nil {
+		jsonData, err = decodePayload(jsonData)
+	}
+	if err != nil {
+		if err == redis.Nil {
+			return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, snapshotTime)
+		}
+		return nil, fmt.Errorf("%s: %w", errRedisReadFailed, err)
 	}
 
-	// 北京时间是UTC+8，需要减8小时转换为UTC
-	dataTime = dataTime.Add(-8 * time.Hour)
+	var data model.ProcessedData
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		return nil, fmt.Errorf("%s: %w", errJSONUnmarshalFailed, err)
+	}
 
-	// 计算时间差并判断
-	age := time.Since(dataTime)
-	isExpired := age > config.DefaultDataExpire
+	// 旧快照没有解析报告，读取失败也不影响快照本身
+	if raw, err := r.client.HGet(ctx, r.keys.historyReport(), snapshotTime).Bytes(); err == nil {
+		if raw, err = decodePayload(raw); err == nil {
+			data.Report, _ = decodeReport(raw)
+		}
+	}
+	return &data, nil
+}
+
+// SaveCrawlReport 保存最近一次爬取的解析报告
+func (r *RedisStore) SaveCrawlReport(ctx context.Context, report model.ParseReport) error {
+	payload, err := r.marshal(report)
+	if err != nil {
+		return err
+	}
+
+	if err := r.client.Set(ctx, r.keys.crawlReport(), payload, 0).Err(); err != nil {
+		return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
+	}
+	return nil
+}
 
-	// 统一日志输出
-	logDataStatus(data.Time, age.Round(time.Minute), isExpired, config.DefaultDataExpire)
-	return isExpired, nil
+// LoadCrawlReport 加载最近一次爬取的解析报告
+func (r *RedisStore) LoadCrawlReport(ctx context.Context) (*model.ParseReport, error) {
+	jsonData, err := r.get(ctx, r.keys.crawlReport())
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", errRedisReadFailed, err)
+	}
+	return decodeReport(jsonData)
+}
+
+// RewriteSnapshot 用重新解析的数据覆盖同一时间的已有快照
+func (r *RedisStore) RewriteSnapshot(ctx context.Context, data model.ProcessedData) error {
+	if err := data.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", errDataInvalid, err)
+	}
+
+	rawMeta, err := r.client.HGet(ctx, r.keys.historyMeta(), data.Time).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return fmt.Errorf("%w: %s", ErrSnapshotNotFound, data.Time)
+		}
+		return fmt.Errorf("%s: %w", errRedisReadFailed, err)
+	}
+	var old model.SnapshotMeta
+	if err := json.Unmarshal(rawMeta, &old); err != nil {
+		return fmt.Errorf("%s: %w", errJSONUnmarshalFailed, err)
+	}
+
+	data.LastCheckedAt = time.Time{}
+	jsonData, err := r.marshal(data)
+	if err != nil {
+		return err
+	}
+	meta, err := json.Marshal(newSnapshotMeta(data, old.SavedAt))
+	if err != nil {
+		return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+	}
+	var report []byte
+	if data.Report != nil {
+		if report, err = r.marshal(data.Report); err != nil {
+			return err
+		}
+	}
+
+	// 当前数据是同一时间时一并覆盖（不改变检查时间）
+	current, err := r.LoadData(ctx)
+	rewriteCurrent := "0"
+	if err == nil && current.Time == data.Time {
+		rewriteCurrent = "1"
+	}
+
+	// 在脚本中确认快照仍存在后再写入，避免与保留策略清理或并发保存交错时把已删除的快照写回
+	keys := []string{r.keys.historyMeta(), r.keys.historyData(), r.keys.historyReport(), r.keys.data()}
+	rewritten, err := rewriteSnapshotScript.Run(ctx, r.client, keys, data.Time, meta, jsonData, report, rewriteCurrent).Int()
+	if err != nil {
+		return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
+	}
+	if rewritten == 0 {
+		return fmt.Errorf("%w: %s", ErrSnapshotNotFound, data.Time)
+	}
+	return nil
+}
+
+// rewriteSnapshotScript 快照仍存在时才改写（KEYS 依次为快照元信息、内容、解析报告和当前数据；
+// ARGV 依次为数据时间、元信息、内容、解析报告（空字符串表示删除）和是否改写当前数据）
+var rewriteSnapshotScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+redis.call("HSET", KEYS[2], ARGV[1], ARGV[3])
+if ARGV[4] == "" then
+	redis.call("HDEL", KEYS[3], ARGV[1])
+else
+	redis.call("HSET", KEYS[3], ARGV[1], ARGV[4])
+end
+if ARGV[5] == "1" then
+	redis.call("SET", KEYS[4], ARGV[3])
+end
+return 1`)
+
+// pruneHistory 按保留策略清理历史快照
+func (r *RedisStore) pruneHistory(ctx context.Context) error {
+	maxCount, maxAge := historyRetention()
+	if maxCount == 0 && maxAge == 0 {
+		return nil
+	}
+
+	metas, err := r.ListSnapshots(ctx)
+	if err != nil {
+		return err
+	}
+
+	expired := expiredSnapshots(metas, maxCount, maxAge, time.Now())
+	if len(expired) == 0 {
+		return nil
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, r.keys.historyMeta(), expired...)
+		pipe.HDel(ctx, r.keys.historyData(), expired...)
+		pipe.HDel(ctx, r.keys.historyReport(), expired...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
+	}
+
+	log.Printf("已清理 %d 个过期历史快照", len(expired))
+	return nil
+}
+
+// ===== RawStore 接口实现 =====
+
+// SaveRaw 保存一次爬取的原始内容
+func (r *RedisStore) SaveRaw(ctx context.Context, raw model.RawPayload) error {
+	body, err := encodeRaw(raw.Body)
+	if err != nil {
+		return err
+	}
+	meta, err := json.Marshal(raw.RawMeta)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, r.keys.rawMeta(), raw.ID, meta)
+		pipe.HSet(ctx, r.keys.rawBody(), raw.ID, body)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
+	}
+
+	// 清理失败不影响本次保存，下次保存时会再次清理
+	if err := r.pruneRaw(ctx); err != nil {
+		log.Printf("清理原始内容失败: %v", err)
+	}
+	return nil
+}
+
+// ListRaw 列出保存的原始内容
+func (r *RedisStore) ListRaw(ctx context.Context) ([]model.RawMeta, error) {
+	values, err := r.client.HGetAll(ctx, r.keys.rawMeta()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errRedisReadFailed, err)
+	}
+
+	metas := make([]model.RawMeta, 0, len(values))
+	for field, value := range values {
+		var meta model.RawMeta
+		if err := json.Unmarshal([]byte(value), &meta); err != nil {
+			log.Printf("跳过无法解析的原始内容元信息 %s: %v", field, err)
+			continue
+		}
+		metas = append(metas, meta)
+	}
+
+	sortRaw(metas)
+	return metas, nil
+}
+
+// LoadRaw 加载指定的原始内容
+func (r *RedisStore) LoadRaw(ctx context.Context, id string) (*model.RawPayload, error) {
+	meta, err := r.client.HGet(ctx, r.keys.rawMeta(), id).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, fmt.Errorf("%w: %s", ErrRawNotFound, id)
+		}
+		return nil, fmt.Errorf("%s: %w", errRedisReadFailed, err)
+	}
+
+	var raw model.RawPayload
+	if err := json.Unmarshal(meta, &raw.RawMeta); err != nil {
+		return nil, fmt.Errorf("%s: %w", errJSONUnmarshalFailed, err)
+	}
+
+	body, err := r.client.HGet(ctx, r.keys.rawBody(), id).Bytes()
+	if err == nil {
+		raw.Body, err = decodePayload(body)
+	}
+	if err != nil {
+		if err == redis.Nil {
+			return nil, fmt.Errorf("%w: %s", ErrRawNotFound, id)
+		}
+		return nil, fmt.Errorf("%s: %w", errRedisReadFailed, err)
+	}
+	return &raw, nil
+}
+
+// ===== CredentialStore 接口实现 =====
+
+// SaveCredentials 保存用户的凭据密文
+func (r *RedisStore) SaveCredentials(ctx context.Context, user string, sealed []byte) error {
+	if err := r.client.HSet(ctx, r.keys.credentials(), user, sealed).Err(); err != nil {
+		return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
+	}
+	return nil
+}
+
+// LoadCredentials 加载用户的凭据密文
+func (r *RedisStore) LoadCredentials(ctx context.Context, user string) ([]byte, error) {
+	sealed, err := r.client.HGet(ctx, r.keys.credentials(), user).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrCredentialsNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", errRedisReadFailed, err)
+	}
+	return sealed, nil
+}
+
+// DeleteCredentials 删除用户的凭据
+func (r *RedisStore) DeleteCredentials(ctx context.Context, user string) error {
+	if err := r.client.HDel(ctx, r.keys.credentials(), user).Err(); err != nil {
+		return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
+	}
+	return nil
+}
+
+// swapCredentialsScript 用户的凭据密文仍是读取时的内容才替换（KEYS[1] 凭据 hash，ARGV[1] 用户名，
+// ARGV[2] 读取时的密文，ARGV[3] 新密文；空字符串表示不存在或删除）
+var swapCredentialsScript = redis.NewScript(`
+local current = redis.call("HGET", KEYS[1], ARGV[1]) or ""
+if current ~= ARGV[2] then
+	return 0
+end
+if ARGV[3] == "" then
+	redis.call("HDEL", KEYS[1], ARGV[1])
+else
+	redis.call("HSET", KEYS[1], ARGV[1], ARGV[3])
+end
+return 1`)
+
+// UpdateCredentials 读取后比较并替换（compare-and-swap），期间被其他修改改动时重新读取后重试
+func (r *RedisStore) UpdateCredentials(ctx context.Context, user string, fn func(sealed []byte) ([]byte, error)) error {
+	key := r.keys.credentials()
+	for range credentialsUpdateRetries {
+		current, err := r.client.HGet(ctx, key, user).Bytes()
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("%s: %w", errRedisReadFailed, err)
+		}
+
+		sealed, err := fn(current)
+		if err != nil {
+			return err
+		}
+
+		swapped, err := swapCredentialsScript.Run(ctx, r.client, []string{key}, user, current, sealed).Int()
+		if err != nil {
+			return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
+		}
+		if swapped == 1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: 用户 %s 的凭据被并发修改，重试 %d 次仍冲突", errRedisSaveFailed, user, credentialsUpdateRetries)
+}
+
+// pruneRaw 按保留策略清理原始内容
+func (r *RedisStore) pruneRaw(ctx context.Context) error {
+	maxCount, maxAge := rawRetention()
+	if maxCount == 0 && maxAge == 0 {
+		return nil
+	}
+
+	metas, err := r.ListRaw(ctx)
+	if err != nil {
+		return err
+	}
+
+	expired := expiredRaw(metas, maxCount, maxAge, time.Now())
+	if len(expired) == 0 {
+		return nil
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, r.keys.rawMeta(), expired...)
+		pipe.HDel(ctx, r.keys.rawBody(), expired...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", errRedisSaveFailed, err)
+	}
+
+	log.Printf("已清理 %d 份过期原始内容", len(expired))
+	return nil
 }
 
 // ===== SitesStore 接口实现 =====
 
-// LoadSitesData 加载站点数据
-func (r *RedisStore) LoadSitesData(ctx context.Context) (any, error) {
-	key := config.DefaultSitesKey
+// LoadSitesData 加载站点目录
+func (r *RedisStore) LoadSitesData(ctx context.Context) (*model.SiteCatalog, error) {
+	key := r.keys.sites()
 
-	jsonData, err := r.client.Get(ctx, key).Bytes()
+	jsonData, err := r.get(ctx, key)
 	if err != nil {
 		if err == redis.Nil {
 			return nil, fmt.Errorf("%s", errSitesNotFound)
@@ -162,23 +635,28 @@ func (r *RedisStore) LoadSitesData(ctx context.Context) (any, error) {
 		return nil, fmt.Errorf("%s: %w", errRedisReadFailed, err)
 	}
 
-	var result any
-	if err := json.Unmarshal(jsonData, &result); err != nil {
-		return nil, fmt.Errorf("%s: %w", errJSONUnmarshalFailed, err)
+	result, err := decodeSiteCatalog(jsonData)
+	if err != nil {
+		return nil, err
 	}
 
 	log.Printf("从Redis加载站点数据成功")
 	return result, nil
 }
 
-// SaveSitesData 保存站点数据
-func (r *RedisStore) SaveSitesData(ctx context.Context, data any) error {
-	jsonData, err := json.Marshal(data)
+// SaveSitesData 保存站点目录
+func (r *RedisStore) SaveSitesData(ctx context.Context, data model.SiteCatalog) error {
+	if err := data.Validate(); err != nil {
+		log.Printf("站点数据验证失败，拒绝保存: %v", err)
+		return fmt.Errorf("%s: %w", errDataInvalid, err)
+	}
+
+	jsonData, err := r.marshal(data)
 	if err != nil {
-		return fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
+		return err
 	}
 
-	key := config.DefaultSitesKey
+	key := r.keys.sites()
 	// 设置24小时TTL
 	ttl := config.DefaultSitesExpire
 	if err := r.client.Set(ctx, key, jsonData, ttl).Err(); err != nil {
@@ -192,7 +670,7 @@ func (r *RedisStore) SaveSitesData(ctx context.Context, data any) error {
 
 // SitesDataExists 检查站点数据是否存在
 func (r *RedisStore) SitesDataExists(ctx context.Context) (bool, error) {
-	key := config.DefaultSitesKey
+	key := r.keys.sites()
 
 	exists, err := r.client.Exists(ctx, key).Result()
 	if err != nil {
@@ -202,43 +680,38 @@ func (r *RedisStore) SitesDataExists(ctx context.Context) (bool, error) {
 	return exists > 0, nil
 }
 
-// ===== UpdateLock 接口实现 =====
-
-// IsUpdating 检查是否正在更新
-func (r *RedisStore) IsUpdating() bool {
-	r.updateMutex.Lock()
-	defer r.updateMutex.Unlock()
-	return r.isUpdating
-}
-
-// SetUpdating 设置更新标记
-func (r *RedisStore) SetUpdating(updating bool) {
-	r.updateMutex.Lock()
-	defer r.updateMutex.Unlock()
-	r.isUpdating = updating
-}
+// SitesDataTTL 站点数据剩余有效期
+func (r *RedisStore) SitesDataTTL(ctx context.Context) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, r.keys.sites()).Result()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", errCheckExistsFailed, err)
+	}
 
-// IsSitesUpdating 检查是否正在更新站点数据
-func (r *RedisStore) IsSitesUpdating() bool {
-	r.sitesUpdateMutex.Lock()
-	defer r.sitesUpdateMutex.Unlock()
-	return r.isSitesUpdating
+	switch ttl {
+	case ttlKeyNotExist:
+		return 0, nil
+	case ttlKeyNoExpire:
+		// 旧版本或手动写入的数据没有TTL，视为完整有效期
+		return config.DefaultSitesExpire, nil
+	default:
+		return ttl, nil
+	}
 }
 
-// SetSitesUpdating 设置站点数据更新标记
-func (r *RedisStore) SetSitesUpdating(updating bool) {
-	r.sitesUpdateMutex.Lock()
-	defer r.sitesUpdateMutex.Unlock()
-	r.isSitesUpdating = updating
+// get 读取并解码数据（兼容未压缩的旧数据）
+func (r *RedisStore) get(ctx context.Context, key string) ([]byte, error) {
+	raw, err := r.client.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return decodePayload(raw)
 }
 
-// ===== 辅助函数 =====
-
-// logDataStatus 记录数据状态日志
-func logDataStatus(dataTime string, age time.Duration, isExpired bool, threshold time.Duration) {
-	if isExpired {
-		log.Printf("数据过期了（数据时间: %v, 距今: %v，阈值: %v）", dataTime, age, threshold)
-	} else {
-		log.Printf("数据还新鲜（数据时间: %v, 距今: %v）", dataTime, age)
+// marshal 序列化并按配置压缩数据
+func (r *RedisStore) marshal(v any) ([]byte, error) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errJSONMarshalFailed, err)
 	}
+	return encodePayload(r.compression, jsonData)
 }
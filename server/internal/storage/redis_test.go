@@ -121,7 +121,7 @@ func TestLoadData(t *testing.T) {
 			name: "数据不存在",
 			setup: func() {
 				client := redisClient
-				client.Del(ctx, config.DefaultRedisKey)
+				client.Del(ctx, legacyRedisKeys.data())
 			},
 			wantErr: true,
 		},
@@ -168,7 +168,7 @@ func TestDataExists(t *testing.T) {
 	})
 
 	t.Run("数据不存在", func(t *testing.T) {
-		redisClient.Del(ctx, config.DefaultRedisKey)
+		redisClient.Del(ctx, legacyRedisKeys.data())
 		exists, err := store.DataExists(ctx)
 		if err != nil {
 			t.Errorf("DataExists() error = %v", err)
@@ -204,7 +204,7 @@ func TestIsDataExpired(t *testing.T) {
 
 	t.Run("数据新鲜", func(t *testing.T) {
 		freshData := model.ProcessedData{
-			Time:  time.Now().Format("2006-01-02 15:04:05"),
+			Time:  time.Now().In(config.Get().UpstreamLocation()).Format(model.DataTimeLayout),
 			Items: []model.SiteItem{{SiteName: "测试", SiteID: "1", ID: 1}},
 		}
 		_ = store.SaveData(ctx, freshData)
@@ -219,7 +219,7 @@ func TestIsDataExpired(t *testing.T) {
 	})
 
 	t.Run("数据不存在", func(t *testing.T) {
-		redisClient.Del(ctx, config.DefaultRedisKey)
+		redisClient.Del(ctx, legacyRedisKeys.data())
 		isExpired, err := store.IsDataExpired(ctx)
 		if err != nil {
 			t.Errorf("IsDataExpired() error = %v", err)
@@ -237,12 +237,7 @@ func TestSaveSitesData(t *testing.T) {
 	sitesStore := setupTestStore(t, mr)
 	ctx := context.Background()
 
-	testData := map[string]interface{}{
-		"site1": map[string]string{"name": "站点1"},
-		"site2": map[string]string{"name": "站点2"},
-	}
-
-	err := sitesStore.SaveSitesData(ctx, testData)
+	err := sitesStore.SaveSitesData(ctx, testSites)
 	if err != nil {
 		t.Errorf("SaveSitesData() error = %v", err)
 	}
@@ -257,59 +252,37 @@ func TestSaveSitesData(t *testing.T) {
 
 	loadedData, err := sitesStore.LoadSitesData(ctx)
 	if err != nil {
-		t.Errorf("LoadSitesData() error = %v", err)
-	}
-
-	loadedMap, ok := loadedData.(map[string]interface{})
-	if !ok {
-		t.Fatal("LoadSitesData() 返回类型错误")
+		t.Fatalf("LoadSitesData() error = %v", err)
 	}
 
-	if len(loadedMap) != 2 {
-		t.Errorf("LoadSitesData() 返回 %d 条数据，期望 2 条", len(loadedMap))
+	if len(loadedData.Sites) != len(testSites.Sites) {
+		t.Errorf("LoadSitesData() 返回 %d 个站点，期望 %d 个", len(loadedData.Sites), len(testSites.Sites))
 	}
 }
 
-func TestUpdateLock(t *testing.T) {
-	mr := miniredis.RunT(t)
-	defer mr.Close()
-
-	lock := setupTestStore(t, mr)
-
-	t.Run("默认不更新", func(t *testing.T) {
-		if lock.IsUpdating() {
-			t.Error("IsUpdating() = true, want false")
-		}
-	})
-
-	t.Run("设置更新标记", func(t *testing.T) {
-		lock.SetUpdating(true)
-		if !lock.IsUpdating() {
-			t.Error("IsUpdating() = false, want true")
-		}
-		lock.SetUpdating(false)
-	})
-}
-
-func TestSitesUpdateLock(t *testing.T) {
+func TestLoadLegacySitesData(t *testing.T) {
 	mr := miniredis.RunT(t)
 	defer mr.Close()
 
-	lock := setupTestStore(t, mr)
+	sitesStore := setupTestStore(t, mr)
 
-	t.Run("默认不更新", func(t *testing.T) {
-		if lock.IsSitesUpdating() {
-			t.Error("IsSitesUpdating() = true, want false")
-		}
-	})
+	// 旧版本直接缓存 IYUU 原始响应
+	legacy := `{"ret":200,"data":{"sites":[{"id":1,"site":"site1","base_url":"site1.example"}]},"msg":""}`
+	if err := mr.Set(legacyRedisKeys.sites(), legacy); err != nil {
+		t.Fatalf("写入旧格式数据失败: %v", err)
+	}
 
-	t.Run("设置更新标记", func(t *testing.T) {
-		lock.SetSitesUpdating(true)
-		if !lock.IsSitesUpdating() {
-			t.Error("IsSitesUpdating() = false, want true")
-		}
-		lock.SetSitesUpdating(false)
-	})
+	loaded, err := sitesStore.LoadSitesData(context.Background())
+	if err != nil {
+		t.Fatalf("LoadSitesData() error = %v", err)
+	}
+	if len(loaded.Sites) != 1 || loaded.Sites[0].Site != "site1" {
+		t.Errorf("LoadSitesData() = %+v, want [site1]", loaded.Sites)
+	}
+	// 没有 TTL 的旧数据视为完整有效期
+	if ttl, err := sitesStore.SitesDataTTL(context.Background()); err != nil || ttl != config.DefaultSitesExpire {
+		t.Errorf("SitesDataTTL() = %v, %v, want %v", ttl, err, config.DefaultSitesExpire)
+	}
 }
 
 func TestPing(t *testing.T) {
@@ -338,6 +311,12 @@ func TestPing(t *testing.T) {
 	})
 }
 
+// testSites 测试用站点目录
+var testSites = model.SiteCatalog{Sites: []model.Site{
+	{ID: 1, Site: "site1", Nickname: "站点1", BaseURL: "site1.example"},
+	{ID: 2, Site: "site2", Nickname: "站点2", BaseURL: "site2.example"},
+}}
+
 func setupTestStore(t *testing.T, mr *miniredis.Miniredis) *RedisStore {
 	t.Helper()
 
@@ -389,3 +368,36 @@ func TestSaveLoadDataRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestRedisStorePruneHistory(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	store := setupTestStore(t, mr)
+	ctx := context.Background()
+
+	// 超过默认保留数量后只保留最新的快照
+	for day := 1; day <= config.DefaultHistoryMaxCount+2; day++ {
+		data := model.ProcessedData{
+			Time:  time.Date(2026, 1, day, 7, 50, 56, 0, time.UTC).Format("2006-01-02 15:04:05"),
+			Items: []model.SiteItem{{SiteName: "测试", SiteID: "1", ID: 1}},
+		}
+		if err := store.SaveData(ctx, data); err != nil {
+			t.Fatalf("SaveData() error = %v", err)
+		}
+	}
+
+	metas, err := store.ListSnapshots(ctx)
+	if err != nil {
+		t.Fatalf("ListSnapshots() error = %v", err)
+	}
+	if len(metas) != config.DefaultHistoryMaxCount {
+		t.Fatalf("ListSnapshots() 返回 %d 个快照，期望 %d 个", len(metas), config.DefaultHistoryMaxCount)
+	}
+	if metas[len(metas)-1].Time != "2026-01-03 07:50:56" {
+		t.Errorf("最旧快照 = %v，期望 2026-01-03 07:50:56", metas[len(metas)-1].Time)
+	}
+	if n, _ := redisClient.HLen(ctx, legacyRedisKeys.historyData()).Result(); n != int64(config.DefaultHistoryMaxCount) {
+		t.Errorf("快照内容数量 = %d，期望 %d", n, config.DefaultHistoryMaxCount)
+	}
+}
@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"top1000/internal/model"
+)
+
+// decodeSiteCatalog 解析已保存的站点目录
+// 兼容旧版本直接保存的 IYUU 原始响应（{"ret":200,"data":{"sites":[...]}}）
+func decodeSiteCatalog(jsonData []byte) (*model.SiteCatalog, error) {
+	var stored struct {
+		Sites []model.Site      `json:"sites"`
+		Data  model.SiteCatalog `json:"data"`
+	}
+	if err := json.Unmarshal(jsonData, &stored); err != nil {
+		return nil, fmt.Errorf("%s: %w", errJSONUnmarshalFailed, err)
+	}
+
+	catalog := &model.SiteCatalog{Sites: stored.Sites}
+	if catalog.Sites == nil {
+		catalog.Sites = stored.Data.Sites
+	}
+	return catalog, nil
+}
@@ -0,0 +1,590 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"top1000/internal/config"
+	"top1000/internal/model"
+)
+
+// suiteStore 行为测试套件要求的存储能力
+type suiteStore interface {
+	DataStore
+	SitesStore
+	HistoryStore
+	QuarantineStore
+	RawStore
+	CredentialStore
+	UpdateLock
+}
+
+// runStoreSuite 对任意存储后端执行同一套行为测试
+// newStore 每次调用都应返回一个空的存储实例
+func runStoreSuite(t *testing.T, newStore func(t *testing.T) suiteStore) {
+	ctx := context.Background()
+	validData := model.ProcessedData{
+		Time: "2026-01-19 07:50:56",
+		Items: []model.SiteItem{
+			{SiteName: "测试站点", SiteID: "123", Duplication: "85.5", Size: "1.2TB", ID: 1},
+			{SiteName: "站点2", SiteID: "2", ID: 2},
+		},
+	}
+
+	t.Run("空存储", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.LoadData(ctx); err == nil {
+			t.Error("LoadData() 期望返回错误")
+		}
+		if exists, err := store.DataExists(ctx); err != nil || exists {
+			t.Errorf("DataExists() = %v, %v, want false, nil", exists, err)
+		}
+		if expired, err := store.IsDataExpired(ctx); err != nil || !expired {
+			t.Errorf("IsDataExpired() = %v, %v, want true, nil", expired, err)
+		}
+		if _, err := store.LoadSitesData(ctx); err == nil {
+			t.Error("LoadSitesData() 期望返回错误")
+		}
+		if exists, err := store.SitesDataExists(ctx); err != nil || exists {
+			t.Errorf("SitesDataExists() = %v, %v, want false, nil", exists, err)
+		}
+		if ttl, err := store.SitesDataTTL(ctx); err != nil || ttl != 0 {
+			t.Errorf("SitesDataTTL() = %v, %v, want 0, nil", ttl, err)
+		}
+	})
+
+	t.Run("保存并加载数据", func(t *testing.T) {
+		store := newStore(t)
+
+		if err := store.SaveData(ctx, validData); err != nil {
+			t.Fatalf("SaveData() error = %v", err)
+		}
+		if exists, err := store.DataExists(ctx); err != nil || !exists {
+			t.Errorf("DataExists() = %v, %v, want true, nil", exists, err)
+		}
+
+		loaded, err := store.LoadData(ctx)
+		if err != nil {
+			t.Fatalf("LoadData() error = %v", err)
+		}
+		if loaded.Time != validData.Time {
+			t.Errorf("Time = %v, want %v", loaded.Time, validData.Time)
+		}
+		if len(loaded.Items) != len(validData.Items) {
+			t.Fatalf("Items length = %v, want %v", len(loaded.Items), len(validData.Items))
+		}
+		for i := range validData.Items {
+			if loaded.Items[i] != validData.Items[i] {
+				t.Errorf("Items[%d] = %+v, want %+v", i, loaded.Items[i], validData.Items[i])
+			}
+		}
+	})
+
+	t.Run("加载结果互相独立", func(t *testing.T) {
+		store := newStore(t)
+		_ = store.SaveData(ctx, validData)
+
+		first, _ := store.LoadData(ctx)
+		first.Items[0].SiteName = "被修改"
+
+		second, err := store.LoadData(ctx)
+		if err != nil {
+			t.Fatalf("LoadData() error = %v", err)
+		}
+		if second.Items[0].SiteName != validData.Items[0].SiteName {
+			t.Errorf("修改返回值影响了存储内容: %v", second.Items[0].SiteName)
+		}
+	})
+
+	t.Run("拒绝无效数据", func(t *testing.T) {
+		store := newStore(t)
+		_ = store.SaveData(ctx, validData)
+
+		invalid := model.ProcessedData{Time: "", Items: []model.SiteItem{{SiteName: "测试", SiteID: "1", ID: 1}}}
+		if err := store.SaveData(ctx, invalid); err == nil {
+			t.Error("SaveData() 期望返回错误")
+		}
+
+		loaded, err := store.LoadData(ctx)
+		if err != nil || loaded.Time != validData.Time {
+			t.Errorf("无效数据不应覆盖已有数据: %v, %v", loaded, err)
+		}
+	})
+
+	t.Run("数据过期判断", func(t *testing.T) {
+		store := newStore(t)
+
+		oldData := model.ProcessedData{
+			Time:  "2020-01-01 00:00:00",
+			Items: []model.SiteItem{{SiteName: "测试", SiteID: "1", ID: 1}},
+		}
+		_ = store.SaveData(ctx, oldData)
+		if expired, err := store.IsDataExpired(ctx); err != nil || !expired {
+			t.Errorf("IsDataExpired() = %v, %v, want true (数据应该过期)", expired, err)
+		}
+
+		freshData := model.ProcessedData{
+			Time:  time.Now().In(config.Get().UpstreamLocation()).Format(model.DataTimeLayout),
+			Items: []model.SiteItem{{SiteName: "测试", SiteID: "1", ID: 1}},
+		}
+		_ = store.SaveData(ctx, freshData)
+		if expired, err := store.IsDataExpired(ctx); err != nil || expired {
+			t.Errorf("IsDataExpired() = %v, %v, want false (数据应该新鲜)", expired, err)
+		}
+	})
+
+	t.Run("记录检查时间", func(t *testing.T) {
+		store := newStore(t)
+
+		if err := store.TouchData(ctx, time.Now()); err == nil {
+			t.Error("数据不存在时 TouchData() 期望返回错误")
+		}
+
+		oldData := model.ProcessedData{
+			Time:  "2020-01-01 00:00:00",
+			Items: []model.SiteItem{{SiteName: "测试", SiteID: "1", ID: 1}},
+		}
+		_ = store.SaveData(ctx, oldData)
+
+		checkedAt := time.Now().Truncate(time.Millisecond)
+		if err := store.TouchData(ctx, checkedAt); err != nil {
+			t.Fatalf("TouchData() error = %v", err)
+		}
+		loaded, err := store.LoadData(ctx)
+		if err != nil || !loaded.LastCheckedAt.Equal(checkedAt) {
+			t.Errorf("LoadData() LastCheckedAt = %v, %v, want %v", loaded.LastCheckedAt, err, checkedAt)
+		}
+		if expired, err := store.IsDataExpired(ctx); err != nil || expired {
+			t.Errorf("IsDataExpired() = %v, %v，刚确认过上游未变化时不应过期", expired, err)
+		}
+
+		// 检查时间不写入历史快照，保存新数据后重新计算
+		if snapshot, err := store.LoadSnapshot(ctx, oldData.Time); err != nil || !snapshot.LastCheckedAt.IsZero() {
+			t.Errorf("LoadSnapshot() = %+v, %v，快照不应包含检查时间", snapshot, err)
+		}
+		_ = store.SaveData(ctx, oldData)
+		if loaded, err := store.LoadData(ctx); err != nil || !loaded.LastCheckedAt.IsZero() {
+			t.Errorf("保存后 LastCheckedAt = %v, %v, want zero", loaded.LastCheckedAt, err)
+		}
+	})
+
+	t.Run("保存并加载站点数据", func(t *testing.T) {
+		store := newStore(t)
+
+		sites := model.SiteCatalog{Sites: []model.Site{
+			{ID: 1, Site: "site1", Nickname: "站点1", BaseURL: "site1.example"},
+			{ID: 2, Site: "site2", Nickname: "站点2", BaseURL: "site2.example"},
+		}}
+		if err := store.SaveSitesData(ctx, sites); err != nil {
+			t.Fatalf("SaveSitesData() error = %v", err)
+		}
+		if exists, err := store.SitesDataExists(ctx); err != nil || !exists {
+			t.Errorf("SitesDataExists() = %v, %v, want true, nil", exists, err)
+		}
+		if ttl, err := store.SitesDataTTL(ctx); err != nil || ttl <= 0 || ttl > config.DefaultSitesExpire {
+			t.Errorf("SitesDataTTL() = %v, %v, want (0, %v]", ttl, err, config.DefaultSitesExpire)
+		}
+
+		loaded, err := store.LoadSitesData(ctx)
+		if err != nil {
+			t.Fatalf("LoadSitesData() error = %v", err)
+		}
+		if len(loaded.Sites) != 2 || loaded.Sites[1] != sites.Sites[1] {
+			t.Errorf("LoadSitesData() = %+v, want %+v", loaded, sites)
+		}
+
+		// 无效站点目录不应覆盖已有数据
+		if err := store.SaveSitesData(ctx, model.SiteCatalog{}); err == nil {
+			t.Error("SaveSitesData() 期望返回错误")
+		}
+		if loaded, err := store.LoadSitesData(ctx); err != nil || len(loaded.Sites) != 2 {
+			t.Errorf("无效站点目录覆盖了已有数据: %v, %v", loaded, err)
+		}
+	})
+
+	t.Run("历史快照", func(t *testing.T) {
+		store := newStore(t)
+
+		if metas, err := store.ListSnapshots(ctx); err != nil || len(metas) != 0 {
+			t.Fatalf("ListSnapshots() = %v, %v, want empty", metas, err)
+		}
+		if _, err := store.LoadSnapshot(ctx, "2026-01-19"); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Errorf("LoadSnapshot() error = %v, want ErrSnapshotNotFound", err)
+		}
+
+		older := model.ProcessedData{
+			Time:  "2026-01-18 07:50:56",
+			Items: []model.SiteItem{{SiteName: "旧站点", SiteID: "1", ID: 1}},
+		}
+		_ = store.SaveData(ctx, older)
+		_ = store.SaveData(ctx, validData)
+		// 相同时间的数据重复保存只保留一个快照
+		_ = store.SaveData(ctx, validData)
+
+		metas, err := store.ListSnapshots(ctx)
+		if err != nil {
+			t.Fatalf("ListSnapshots() error = %v", err)
+		}
+		if len(metas) != 2 {
+			t.Fatalf("ListSnapshots() 返回 %d 个快照，期望 2 个", len(metas))
+		}
+		if metas[0].Time != validData.Time || metas[0].ItemCount != len(validData.Items) {
+			t.Errorf("最新快照 = %+v，期望时间 %v、%d 条", metas[0], validData.Time, len(validData.Items))
+		}
+		if metas[0].SavedAt.IsZero() {
+			t.Error("快照归档时间不应为空")
+		}
+
+		snapshot, err := store.LoadSnapshot(ctx, "2026-01-18")
+		if err != nil {
+			t.Fatalf("LoadSnapshot() error = %v", err)
+		}
+		if snapshot.Time != older.Time || snapshot.Items[0].SiteName != "旧站点" {
+			t.Errorf("LoadSnapshot() = %+v, want %+v", snapshot, older)
+		}
+	})
+
+	t.Run("解析报告", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.LoadCrawlReport(ctx); !errors.Is(err, ErrReportNotFound) {
+			t.Fatalf("LoadCrawlReport() error = %v, want ErrReportNotFound", err)
+		}
+
+		// 最近一次爬取的报告（例如解析失败时）独立于快照保存
+		failed := model.ParseReport{Format: model.ReportFormatText, Groups: 3, SkippedGroups: 3}
+		if err := store.SaveCrawlReport(ctx, failed); err != nil {
+			t.Fatalf("SaveCrawlReport() error = %v", err)
+		}
+		if report, err := store.LoadCrawlReport(ctx); err != nil || report.SkippedGroups != 3 {
+			t.Errorf("LoadCrawlReport() = %+v, %v", report, err)
+		}
+
+		// 报告随快照保存，不写入数据本身
+		withReport := validData
+		withReport.Report = &model.ParseReport{Format: model.ReportFormatText, Time: validData.Time, Items: 2}
+		if err := store.SaveData(ctx, withReport); err != nil {
+			t.Fatalf("SaveData() error = %v", err)
+		}
+		snapshot, err := store.LoadSnapshot(ctx, validData.Time)
+		if err != nil || snapshot.Report == nil || snapshot.Report.Items != 2 {
+			t.Errorf("LoadSnapshot() = %+v, %v，期望包含解析报告", snapshot, err)
+		}
+
+		// 同一时间的数据重新保存时不保留旧报告
+		_ = store.SaveData(ctx, validData)
+		if snapshot, err := store.LoadSnapshot(ctx, validData.Time); err != nil || snapshot.Report != nil {
+			t.Errorf("LoadSnapshot() Report = %+v, %v, want nil", snapshot.Report, err)
+		}
+	})
+
+	t.Run("隔离区", func(t *testing.T) {
+		store := newStore(t)
+
+		if entries, err := store.ListQuarantine(ctx); err != nil || len(entries) != 0 {
+			t.Fatalf("ListQuarantine() = %v, %v, want empty", entries, err)
+		}
+
+		total := config.DefaultQuarantineMax + 2
+		for i := range total {
+			entry := model.QuarantineEntry{
+				ID:        fmt.Sprintf("%d", i),
+				Reasons:   []string{"条目数过少"},
+				Time:      validData.Time,
+				ItemCount: len(validData.Items),
+				Data:      &validData,
+			}
+			if err := store.Quarantine(ctx, entry); err != nil {
+				t.Fatalf("Quarantine() error = %v", err)
+			}
+		}
+
+		// 最新在前，只保留最近的记录
+		entries, err := store.ListQuarantine(ctx)
+		if err != nil {
+			t.Fatalf("ListQuarantine() error = %v", err)
+		}
+		if len(entries) != config.DefaultQuarantineMax {
+			t.Fatalf("ListQuarantine() 返回 %d 条，期望 %d 条", len(entries), config.DefaultQuarantineMax)
+		}
+		if entries[0].ID != fmt.Sprintf("%d", total-1) || entries[0].Data == nil || len(entries[0].Data.Items) != 2 {
+			t.Errorf("最新记录 = %+v", entries[0])
+		}
+		if exists, _ := store.DataExists(ctx); exists {
+			t.Error("隔离的数据不应成为当前数据")
+		}
+	})
+
+	t.Run("原始内容", func(t *testing.T) {
+		store := newStore(t)
+
+		if metas, err := store.ListRaw(ctx); err != nil || len(metas) != 0 {
+			t.Fatalf("ListRaw() = %v, %v, want empty", metas, err)
+		}
+		if _, err := store.LoadRaw(ctx, "1"); !errors.Is(err, ErrRawNotFound) {
+			t.Fatalf("LoadRaw() error = %v, want ErrRawNotFound", err)
+		}
+
+		// 超过保留时长的内容保存后立即清理
+		expired := model.NewRawPayload("test", []byte("过期内容"), time.Now().Add(-config.DefaultRawMaxAge-time.Hour))
+		if err := store.SaveRaw(ctx, *expired); err != nil {
+			t.Fatalf("SaveRaw() error = %v", err)
+		}
+
+		start := time.Now()
+		total := config.DefaultRawMaxCount + 2
+		for i := range total {
+			body := []byte(fmt.Sprintf("create time 2026-01-19 07:50:56 by xxx\n\n站名：站点%d 【ID：1】", i))
+			if err := store.SaveRaw(ctx, *model.NewRawPayload("test", body, start.Add(time.Duration(i)*time.Second))); err != nil {
+				t.Fatalf("SaveRaw() error = %v", err)
+			}
+		}
+
+		// 最新在前，只保留最近的记录
+		metas, err := store.ListRaw(ctx)
+		if err != nil {
+			t.Fatalf("ListRaw() error = %v", err)
+		}
+		if len(metas) != config.DefaultRawMaxCount {
+			t.Fatalf("ListRaw() 返回 %d 条，期望 %d 条", len(metas), config.DefaultRawMaxCount)
+		}
+
+		latest, err := store.LoadRaw(ctx, metas[0].ID)
+		if err != nil {
+			t.Fatalf("LoadRaw() error = %v", err)
+		}
+		if want := fmt.Sprintf("站点%d ", total-1); !strings.Contains(string(latest.Body), want) || latest.Hash != metas[0].Hash {
+			t.Errorf("LoadRaw() = %+v（%s），期望最新内容", latest.RawMeta, latest.Body)
+		}
+	})
+
+	t.Run("用户凭据", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.LoadCredentials(ctx, "alice"); !errors.Is(err, ErrCredentialsNotFound) {
+			t.Fatalf("LoadCredentials() error = %v, want ErrCredentialsNotFound", err)
+		}
+		if err := store.DeleteCredentials(ctx, "alice"); err != nil {
+			t.Fatalf("不存在时 DeleteCredentials() error = %v", err)
+		}
+
+		for _, sealed := range []string{"密文1", "密文2"} {
+			if err := store.SaveCredentials(ctx, "alice", []byte(sealed)); err != nil {
+				t.Fatalf("SaveCredentials() error = %v", err)
+			}
+		}
+		if err := store.SaveCredentials(ctx, "bob", []byte("bob 的密文")); err != nil {
+			t.Fatalf("SaveCredentials() error = %v", err)
+		}
+
+		// 覆盖已有内容，不同用户互不影响
+		if got, err := store.LoadCredentials(ctx, "alice"); err != nil || string(got) != "密文2" {
+			t.Errorf("LoadCredentials(alice) = %q, %v", got, err)
+		}
+		if err := store.DeleteCredentials(ctx, "alice"); err != nil {
+			t.Fatalf("DeleteCredentials() error = %v", err)
+		}
+		if _, err := store.LoadCredentials(ctx, "alice"); !errors.Is(err, ErrCredentialsNotFound) {
+			t.Errorf("删除后 LoadCredentials() error = %v", err)
+		}
+		if got, err := store.LoadCredentials(ctx, "bob"); err != nil || string(got) != "bob 的密文" {
+			t.Errorf("LoadCredentials(bob) = %q, %v", got, err)
+		}
+	})
+
+	t.Run("原子修改用户凭据", func(t *testing.T) {
+		store := newStore(t)
+
+		// 并发修改同一用户时每次修改都基于最新内容，不会互相覆盖
+		// （每个修改最多被其他修改各打断一次，不超过重试次数）
+		const writers = 8
+		var wg sync.WaitGroup
+		for i := range writers {
+			wg.Go(func() {
+				err := store.UpdateCredentials(ctx, "alice", func(sealed []byte) ([]byte, error) {
+					return fmt.Appendf(sealed, "[%d]", i), nil
+				})
+				if err != nil {
+					t.Errorf("UpdateCredentials() error = %v", err)
+				}
+			})
+		}
+		wg.Wait()
+
+		got, err := store.LoadCredentials(ctx, "alice")
+		if err != nil {
+			t.Fatalf("LoadCredentials() error = %v", err)
+		}
+		for i := range writers {
+			if !strings.Contains(string(got), fmt.Sprintf("[%d]", i)) {
+				t.Errorf("LoadCredentials() = %q，丢失了第 %d 次修改", got, i)
+			}
+		}
+
+		// fn 返回错误时不修改
+		errAbort := errors.New("放弃修改")
+		err = store.UpdateCredentials(ctx, "alice", func([]byte) ([]byte, error) { return []byte("不应保存"), errAbort })
+		if !errors.Is(err, errAbort) {
+			t.Errorf("UpdateCredentials() error = %v, want %v", err, errAbort)
+		}
+		if after, _ := store.LoadCredentials(ctx, "alice"); string(after) != string(got) {
+			t.Errorf("fn 返回错误后 LoadCredentials() = %q, want %q", after, got)
+		}
+
+		// 返回 nil 即删除
+		if err := store.UpdateCredentials(ctx, "alice", func([]byte) ([]byte, error) { return nil, nil }); err != nil {
+			t.Fatalf("UpdateCredentials(删除) error = %v", err)
+		}
+		if _, err := store.LoadCredentials(ctx, "alice"); !errors.Is(err, ErrCredentialsNotFound) {
+			t.Errorf("删除后 LoadCredentials() error = %v", err)
+		}
+	})
+
+	t.Run("改写快照", func(t *testing.T) {
+		store := newStore(t)
+
+		if err := store.RewriteSnapshot(ctx, validData); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Fatalf("快照不存在时 RewriteSnapshot() error = %v, want ErrSnapshotNotFound", err)
+		}
+
+		older := validData
+		older.Time = "2026-01-18 07:50:56"
+		_ = store.SaveData(ctx, older)
+		_ = store.SaveData(ctx, validData)
+		before, _ := store.ListSnapshots(ctx)
+
+		// 改写历史快照不影响当前数据
+		fixed := older
+		fixed.Items = append([]model.SiteItem{}, older.Items...)
+		fixed.Items = append(fixed.Items, model.SiteItem{SiteName: "补回的站点", SiteID: "3", ID: 3})
+		fixed.Report = &model.ParseReport{Format: model.ReportFormatText, Items: 3}
+		if err := store.RewriteSnapshot(ctx, fixed); err != nil {
+			t.Fatalf("RewriteSnapshot() error = %v", err)
+		}
+		snapshot, err := store.LoadSnapshot(ctx, older.Time)
+		if err != nil || len(snapshot.Items) != 3 || snapshot.Report == nil || snapshot.Report.Items != 3 {
+			t.Errorf("LoadSnapshot() = %+v, %v，期望改写后的快照", snapshot, err)
+		}
+		if current, _ := store.LoadData(ctx); len(current.Items) != 2 {
+			t.Errorf("改写历史快照后当前数据 = %+v", current)
+		}
+
+		// 当前数据是同一时间时一并改写，保留原归档时间
+		fixed.Time = validData.Time
+		if err := store.RewriteSnapshot(ctx, fixed); err != nil {
+			t.Fatalf("RewriteSnapshot() error = %v", err)
+		}
+		if current, _ := store.LoadData(ctx); len(current.Items) != 3 {
+			t.Errorf("改写后当前数据 = %+v，期望 3 条", current)
+		}
+		after, _ := store.ListSnapshots(ctx)
+		if len(after) != 2 || !after[0].SavedAt.Equal(before[0].SavedAt) || after[0].ItemCount != 3 {
+			t.Errorf("ListSnapshots() = %+v，改写前 %+v", after, before)
+		}
+	})
+
+	t.Run("合并刷新", func(t *testing.T) {
+		store := newStore(t)
+		errFetch := errors.New("爬取失败")
+
+		if store.InFlight(LeaseTop1000) {
+			t.Fatal("默认不应有进行中的刷新")
+		}
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		result := make(chan error, 1)
+		go func() {
+			result <- store.Do(ctx, LeaseTop1000, func() error {
+				close(started)
+				<-release
+				return errFetch
+			})
+		}()
+		<-started
+
+		if !store.InFlight(LeaseTop1000) {
+			t.Error("InFlight() = false, want true")
+		}
+
+		// 并发调用者加入同一次刷新，不会再次执行 fn；等待超时只影响自己
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		err := store.Do(waitCtx, LeaseTop1000, func() error {
+			t.Error("进行中的刷新不应重复执行")
+			return nil
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("等待超时 Do() error = %v, want DeadlineExceeded", err)
+		}
+
+		// 不同名称的刷新互不影响
+		if err := store.Do(ctx, LeaseSites, func() error { return nil }); err != nil {
+			t.Errorf("Do(%s) error = %v", LeaseSites, err)
+		}
+
+		close(release)
+		if err := <-result; !errors.Is(err, errFetch) {
+			t.Errorf("Do() error = %v, want %v", err, errFetch)
+		}
+		if store.InFlight(LeaseTop1000) {
+			t.Error("刷新结束后 InFlight() 应为 false")
+		}
+
+		// 结束后可以发起新的刷新
+		if err := store.Do(ctx, LeaseTop1000, func() error { return nil }); err != nil {
+			t.Errorf("再次 Do() error = %v", err)
+		}
+	})
+
+	t.Run("刷新租约", func(t *testing.T) {
+		store := newStore(t)
+
+		lease, err := store.AcquireLease(ctx, LeaseTop1000)
+		if err != nil {
+			t.Fatalf("AcquireLease() error = %v", err)
+		}
+		if _, err := store.AcquireLease(ctx, LeaseTop1000); !errors.Is(err, ErrLeaseHeld) {
+			t.Errorf("重复获取 AcquireLease() error = %v, want ErrLeaseHeld", err)
+		}
+
+		sitesLease, err := store.AcquireLease(ctx, LeaseSites)
+		if err != nil {
+			t.Fatalf("不同租约应互不影响: %v", err)
+		}
+		_ = sitesLease.Release(ctx)
+
+		if err := lease.Release(ctx); err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+		// 重复释放是安全的
+		if err := lease.Release(ctx); err != nil {
+			t.Errorf("重复 Release() error = %v", err)
+		}
+
+		again, err := store.AcquireLease(ctx, LeaseTop1000)
+		if err != nil {
+			t.Fatalf("释放后 AcquireLease() error = %v", err)
+		}
+		_ = again.Release(ctx)
+	})
+}
+
+func TestRedisStoreSuite(t *testing.T) {
+	runStoreSuite(t, func(t *testing.T) suiteStore {
+		return setupTestStore(t, miniredis.RunT(t))
+	})
+}
+
+func TestMemoryStoreSuite(t *testing.T) {
+	runStoreSuite(t, func(t *testing.T) suiteStore {
+		return NewMemoryStore()
+	})
+}
@@ -0,0 +1,208 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"top1000/internal/config"
+)
+
+// 熔断器状态
+const (
+	StateClosed   = "closed"    // 正常放行
+	StateOpen     = "open"      // 熔断中，直接拒绝请求
+	StateHalfOpen = "half-open" // 冷却结束，放行一个探测请求
+)
+
+// ErrCircuitOpen 熔断器打开，请求未发出
+var ErrCircuitOpen = errors.New("上游连续失败，已熔断")
+
+// Breaker 熔断器
+// 连续失败达到阈值后打开，冷却期内直接拒绝；冷却结束后放行一个探测请求，
+// 探测成功则恢复，失败则重新计时
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// BreakerStatus 熔断器状态快照（用于状态接口）
+type BreakerStatus struct {
+	Name     string     `json:"name"`
+	State    string     `json:"state"`
+	Failures int        `json:"failures"`           // 连续失败次数
+	OpenedAt *time.Time `json:"openedAt,omitempty"` // 最近一次熔断时间
+	RetryAt  *time.Time `json:"retryAt,omitempty"`  // 允许探测的时间（熔断中）
+}
+
+// NewBreaker 创建熔断器（threshold 为 0 时不熔断）
+func NewBreaker(name string, threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     StateClosed,
+	}
+}
+
+// Allow 检查是否允许发出请求，熔断中返回 ErrCircuitOpen
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		log.Printf("[%s] %s 熔断冷却结束，放行探测请求", logPrefix, b.name)
+		return nil
+	case StateHalfOpen:
+		// 探测请求进行中，其他请求继续拒绝
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Call 通过熔断器执行一次请求
+// 调用方取消和不可重试的错误（如签名错误）不计入失败次数，但会结束探测
+func (b *Breaker) Call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+
+	err := fn(ctx)
+	switch {
+	case err == nil:
+		b.success()
+	case ctx.Err() != nil, !Retryable(err):
+		b.release()
+	default:
+		b.failure()
+	}
+	return err
+}
+
+// release 结束探测但不改变状态
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// success 请求成功，关闭熔断器
+func (b *Breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	if b.state != StateClosed {
+		b.state = StateClosed
+		log.Printf("[%s] %s 探测成功，熔断已恢复", logPrefix, b.name)
+	}
+}
+
+// failure 请求失败，达到阈值或探测失败时打开熔断器
+func (b *Breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.threshold <= 0 {
+		return
+	}
+
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.state = StateOpen
+		log.Printf("[%s] %s 连续失败 %d 次，熔断 %v", logPrefix, b.name, b.failures, b.cooldown)
+	}
+}
+
+// Status 返回当前状态
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{Name: b.name, State: b.state, Failures: b.failures}
+	if !b.openedAt.IsZero() {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	if b.state == StateOpen {
+		retryAt := b.openedAt.Add(b.cooldown)
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+// Do 通过熔断器和重试策略调用上游（每次尝试都经过熔断器）
+func Do(ctx context.Context, name string, policy Policy, fn func(ctx context.Context) error) error {
+	breaker := BreakerFor(name)
+	return Retry(ctx, name, policy, func(ctx context.Context) error {
+		return breaker.Call(ctx, fn)
+	})
+}
+
+// ===== 全局熔断器（按上游名称） =====
+
+var (
+	breakers   = make(map[string]*Breaker)
+	breakersMu sync.Mutex
+)
+
+// BreakerFor 返回指定上游的熔断器（首次使用时按配置创建）
+func BreakerFor(name string) *Breaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	if b, ok := breakers[name]; ok {
+		return b
+	}
+
+	cfg := config.Get()
+	b := NewBreaker(name, cfg.BreakerThreshold, cfg.BreakerCooldown)
+	breakers[name] = b
+	return b
+}
+
+// Statuses 返回所有熔断器的状态（按名称排序）
+func Statuses() []BreakerStatus {
+	breakersMu.Lock()
+	list := make([]*Breaker, 0, len(breakers))
+	for _, b := range breakers {
+		list = append(list, b)
+	}
+	breakersMu.Unlock()
+
+	statuses := make([]BreakerStatus, 0, len(list))
+	for _, b := range list {
+		statuses = append(statuses, b.Status())
+	}
+	slices.SortFunc(statuses, func(a, b BreakerStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return statuses
+}
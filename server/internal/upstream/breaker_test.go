@@ -0,0 +1,122 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	ctx := context.Background()
+	errUpstream := &StatusError{StatusCode: http.StatusBadGateway}
+	fail := func(ctx context.Context) error { return errUpstream }
+	ok := func(ctx context.Context) error { return nil }
+
+	newBreaker := func(threshold int) (*Breaker, *time.Time) {
+		now := time.Date(2026, 1, 19, 8, 0, 0, 0, time.UTC)
+		b := NewBreaker("iyuu", threshold, time.Minute)
+		b.now = func() time.Time { return now }
+		return b, &now
+	}
+
+	t.Run("连续失败后熔断，冷却后放行一个探测", func(t *testing.T) {
+		b, now := newBreaker(3)
+
+		for range 3 {
+			_ = b.Call(ctx, fail)
+		}
+		if status := b.Status(); status.State != StateOpen || status.RetryAt == nil {
+			t.Fatalf("Status() = %+v, want open", status)
+		}
+
+		called := false
+		if err := b.Call(ctx, func(ctx context.Context) error { called = true; return nil }); !errors.Is(err, ErrCircuitOpen) || called {
+			t.Errorf("熔断期间 Call() error = %v, called = %v", err, called)
+		}
+
+		// 冷却结束：只放行一个探测请求
+		*now = now.Add(time.Minute)
+		if err := b.Allow(); err != nil {
+			t.Fatalf("冷却结束 Allow() error = %v", err)
+		}
+		if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("探测期间 Allow() error = %v, want ErrCircuitOpen", err)
+		}
+		b.success()
+
+		if status := b.Status(); status.State != StateClosed || status.Failures != 0 {
+			t.Errorf("探测成功后 Status() = %+v, want closed", status)
+		}
+	})
+
+	t.Run("探测失败重新熔断", func(t *testing.T) {
+		b, now := newBreaker(2)
+		_ = b.Call(ctx, fail)
+		_ = b.Call(ctx, fail)
+
+		*now = now.Add(time.Minute)
+		_ = b.Call(ctx, fail)
+
+		status := b.Status()
+		if status.State != StateOpen || !status.OpenedAt.Equal(*now) {
+			t.Errorf("探测失败后 Status() = %+v, want open at %v", status, *now)
+		}
+	})
+
+	t.Run("成功重置失败计数", func(t *testing.T) {
+		b, _ := newBreaker(2)
+		_ = b.Call(ctx, fail)
+		_ = b.Call(ctx, ok)
+		_ = b.Call(ctx, fail)
+
+		if status := b.Status(); status.State != StateClosed || status.Failures != 1 {
+			t.Errorf("Status() = %+v, want closed with 1 failure", status)
+		}
+	})
+
+	t.Run("不可重试的错误不计入失败", func(t *testing.T) {
+		b, _ := newBreaker(1)
+		_ = b.Call(ctx, func(ctx context.Context) error { return Permanent(errors.New("sign错误")) })
+
+		if status := b.Status(); status.State != StateClosed || status.Failures != 0 {
+			t.Errorf("Status() = %+v, want closed", status)
+		}
+	})
+
+	t.Run("阈值为0时不熔断", func(t *testing.T) {
+		b, _ := newBreaker(0)
+		for range 10 {
+			_ = b.Call(ctx, fail)
+		}
+		if err := b.Allow(); err != nil {
+			t.Errorf("Allow() error = %v", err)
+		}
+	})
+}
+
+func TestDo(t *testing.T) {
+	name := "https://do.example/" + t.Name()
+	calls := 0
+	err := Do(context.Background(), name, Policy{Attempts: 3}, func(ctx context.Context) error {
+		if calls++; calls < 2 {
+			return &StatusError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("Do() error = %v, calls = %d", err, calls)
+	}
+
+	// 已请求过的上游出现在状态列表中
+	for _, status := range Statuses() {
+		if status.Name == name {
+			if status.State != StateClosed || status.Failures != 0 {
+				t.Errorf("Statuses() = %+v, want closed", status)
+			}
+			return
+		}
+	}
+	t.Errorf("Statuses() 缺少 %s", name)
+}
@@ -0,0 +1,164 @@
+package upstream
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"top1000/internal/config"
+)
+
+// 代理地址支持的协议（net/http 原生支持 SOCKS5）
+var proxySchemes = map[string]bool{
+	"http":    true,
+	"https":   true,
+	"socks5":  true,
+	"socks5h": true,
+}
+
+// 出站连接参数
+const (
+	dialTimeout         = 10 * time.Second
+	keepAlive           = 30 * time.Second
+	tlsHandshakeTimeout = 10 * time.Second
+	idleConnTimeout     = 90 * time.Second
+	maxIdleConnsPerHost = 4
+)
+
+var (
+	sharedTransport     http.RoundTripper
+	sharedTransportOnce sync.Once
+)
+
+// HTTPClient 返回访问上游的 HTTP 客户端
+// 所有客户端共用同一个 Transport（代理、CA、User-Agent 和连接池），只有超时时间不同
+func HTTPClient(timeout time.Duration) *http.Client {
+	sharedTransportOnce.Do(func() {
+		transport, err := NewTransport(config.Get())
+		if err != nil {
+			// 配置已在启动时验证，这里只会在 CA 文件被删除等情况下出现
+			log.Printf("[%s] 出站连接配置无效，使用默认配置: %v", logPrefix, err)
+			cfg := config.Get()
+			transport, _ = NewTransport(&config.Config{
+				UpstreamUserAgent: cfg.UpstreamUserAgent,
+				UpstreamMode:      cfg.UpstreamMode,
+				FixturesDir:       cfg.FixturesDir,
+			})
+		}
+		sharedTransport = transport
+	})
+	return &http.Client{Timeout: timeout, Transport: sharedTransport}
+}
+
+// NewTransport 根据配置创建出站 Transport
+// 未配置 UPSTREAM_PROXY 时沿用 HTTP_PROXY/HTTPS_PROXY/NO_PROXY 环境变量；
+// UPSTREAM_MODE 为 record/replay 时录制或回放响应（见 fixtures.go）
+func NewTransport(cfg *config.Config) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: dialTimeout, KeepAlive: keepAlive}).DialContext
+	transport.TLSHandshakeTimeout = tlsHandshakeTimeout
+	transport.IdleConnTimeout = idleConnTimeout
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+
+	if cfg.UpstreamProxy != "" {
+		proxyURL, err := ParseProxyURL(cfg.UpstreamProxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.UpstreamCAFile != "" {
+		pool, err := LoadCAFile(cfg.UpstreamCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	var base http.RoundTripper = transport
+	switch cfg.UpstreamMode {
+	case config.UpstreamModeRecord:
+		base = &recordTransport{base: transport, fixtures: NewFixtures(cfg.FixturesDir)}
+	case config.UpstreamModeReplay:
+		base = &replayTransport{fixtures: NewFixtures(cfg.FixturesDir)}
+	}
+
+	return &userAgentTransport{base: base, userAgent: cfg.UpstreamUserAgent}, nil
+}
+
+// ParseProxyURL 解析代理地址（http/https/socks5/socks5h）
+func ParseProxyURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析代理地址失败: %w", err)
+	}
+	if !proxySchemes[u.Scheme] || u.Host == "" {
+		return nil, fmt.Errorf("不支持的代理地址: %s", u.Redacted())
+	}
+	return u, nil
+}
+
+// LoadCAFile 加载自定义 CA 证书（追加到系统证书之后，代理替换证书时使用）
+func LoadCAFile(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取CA证书文件失败: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA证书文件中没有有效证书: %s", path)
+	}
+	return pool, nil
+}
+
+// ProxyName 描述出站代理（用于启动日志，不输出密码）
+func ProxyName(cfg *config.Config) string {
+	if cfg.UpstreamProxy == "" {
+		return "环境变量（HTTP_PROXY/HTTPS_PROXY）"
+	}
+	u, err := ParseProxyURL(cfg.UpstreamProxy)
+	if err != nil {
+		return "无效"
+	}
+	return u.Redacted()
+}
+
+// userAgentTransport 为未设置 User-Agent 的请求补充默认值
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		// RoundTripper 不能修改调用方的请求
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// CloseIdleConnections 关闭空闲连接（转发给底层 Transport）
+func (t *userAgentTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,175 @@
+package upstream
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// 录制文件扩展名（按 Content-Type 区分，方便直接查看或作为 file:// 数据源使用）
+const (
+	fixtureExtJSON = ".json"
+	fixtureExtText = ".txt"
+)
+
+// fixtureSecretParams 不写入录制文件名的查询参数（如 IYUU 签名）
+var fixtureSecretParams = []string{"sign", "token", "key", "secret", "password", "apikey", "access_token"}
+
+// ErrFixtureNotFound 回放模式下没有对应的录制响应
+var ErrFixtureNotFound = errors.New("没有录制的上游响应")
+
+// Fixtures 上游响应录制目录
+// 每个请求地址对应一个文件，文件内容为原始响应体（IYUU 文本或 JSON）
+type Fixtures struct {
+	dir string
+}
+
+// NewFixtures 创建录制目录
+func NewFixtures(dir string) *Fixtures {
+	return &Fixtures{dir: dir}
+}
+
+// Name 返回请求地址对应的文件名（不含扩展名）
+// 由主机、路径和非敏感查询参数组成，例如 api.iyuu.cn_index.php_service=App.Api.Sites_version=2.0.0
+func (f *Fixtures) Name(u *url.URL) string {
+	parts := []string{u.Host + u.Path}
+
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if !slices.Contains(fixtureSecretParams, strings.ToLower(key)) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		parts = append(parts, key+"="+strings.Join(query[key], ","))
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '=', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.Join(parts, "_"))
+}
+
+// Save 保存响应体（先写临时文件再重命名，避免回放读到写了一半的文件）
+func (f *Fixtures) Save(u *url.URL, contentType string, body []byte) (string, error) {
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建录制目录失败: %w", err)
+	}
+
+	ext := fixtureExtText
+	if strings.Contains(contentType, "json") {
+		ext = fixtureExtJSON
+	}
+	path := filepath.Join(f.dir, f.Name(u)+ext)
+
+	tmp, err := os.CreateTemp(f.dir, ".fixture-*")
+	if err != nil {
+		return "", fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("写入录制文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("写入录制文件失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("写入录制文件失败: %w", err)
+	}
+	return path, nil
+}
+
+// Load 读取录制的响应体，返回内容和 Content-Type
+func (f *Fixtures) Load(u *url.URL) ([]byte, string, error) {
+	name := f.Name(u)
+	for _, candidate := range []struct{ ext, contentType string }{
+		{fixtureExtJSON, "application/json; charset=utf-8"},
+		{fixtureExtText, "text/plain; charset=utf-8"},
+	} {
+		body, err := os.ReadFile(filepath.Join(f.dir, name+candidate.ext))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("读取录制文件失败: %w", err)
+		}
+		return body, candidate.contentType, nil
+	}
+	return nil, "", fmt.Errorf("%w: %s（目录 %s）", ErrFixtureNotFound, name, f.dir)
+}
+
+// recordTransport 请求上游并录制成功的响应
+type recordTransport struct {
+	base     http.RoundTripper
+	fixtures *Fixtures
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// 录制失败不影响本次请求
+	if path, err := t.fixtures.Save(req.URL, resp.Header.Get("Content-Type"), body); err != nil {
+		log.Printf("[%s] 录制响应失败: %v", logPrefix, err)
+	} else {
+		log.Printf("[%s] 已录制响应: %s（%d 字节）", logPrefix, path, len(body))
+	}
+	return resp, nil
+}
+
+// replayTransport 只从录制目录返回响应，不访问网络
+type replayTransport struct {
+	fixtures *Fixtures
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	body, contentType, err := t.fixtures.Load(req.URL)
+	if err != nil {
+		// 重试也不会出现录制文件
+		return nil, Permanent(err)
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {contentType}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
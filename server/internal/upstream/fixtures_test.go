@@ -0,0 +1,114 @@
+package upstream
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"top1000/internal/config"
+)
+
+func TestFixturesName(t *testing.T) {
+	tests := []struct {
+		name   string
+		rawURL string
+		want   string
+	}{
+		{name: "Top1000接口", rawURL: "https://api.iyuu.cn/top1000.php", want: "api.iyuu.cn_top1000.php"},
+		{
+			name:   "站点接口不包含签名",
+			rawURL: "https://api.iyuu.cn/index.php?version=2.0.0&sign=secret&service=App.Api.Sites",
+			want:   "api.iyuu.cn_index.php_service=App.Api.Sites_version=2.0.0",
+		},
+		{name: "端口和特殊字符", rawURL: "http://127.0.0.1:8080/a b/top1000.txt?Token=x", want: "127.0.0.1_8080_a_b_top1000.txt"},
+	}
+
+	fixtures := NewFixtures(t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.rawURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fixtures.Name(u); got != tt.want {
+				t.Errorf("Name() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	const body = "create time 2026-01-19 07:50:56 by xxx\n"
+	dir := t.TempDir()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(body))
+	}))
+	apiURL := server.URL + "/top1000.php?sign=secret"
+
+	get := func(t *testing.T, mode, rawURL string) (string, error) {
+		t.Helper()
+		rt, err := NewTransport(&config.Config{UpstreamMode: mode, FixturesDir: dir})
+		if err != nil {
+			t.Fatalf("NewTransport() error = %v", err)
+		}
+		resp, err := (&http.Client{Transport: rt}).Get(rawURL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		content, err := io.ReadAll(resp.Body)
+		return string(content), err
+	}
+
+	t.Run("录制", func(t *testing.T) {
+		if got, err := get(t, config.UpstreamModeRecord, apiURL); err != nil || got != body {
+			t.Fatalf("录制模式应返回原始响应，得到 %q, %v", got, err)
+		}
+		if _, err := get(t, config.UpstreamModeRecord, server.URL+"/missing"); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), ".txt") {
+			t.Fatalf("只应录制成功的响应，目录内容: %v", entries)
+		}
+		if strings.Contains(entries[0].Name(), "secret") {
+			t.Errorf("文件名不应包含签名: %s", entries[0].Name())
+		}
+		content, _ := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+		if string(content) != body {
+			t.Errorf("录制内容 = %q, want %q", content, body)
+		}
+	})
+
+	// 回放时上游已不可用
+	server.Close()
+
+	t.Run("回放", func(t *testing.T) {
+		if got, err := get(t, config.UpstreamModeReplay, apiURL); err != nil || got != body {
+			t.Errorf("回放结果 = %q, %v, want %q", got, err, body)
+		}
+	})
+
+	t.Run("没有录制时不访问网络", func(t *testing.T) {
+		_, err := get(t, config.UpstreamModeReplay, server.URL+"/sites.json")
+		if !errors.Is(err, ErrFixtureNotFound) {
+			t.Errorf("Get() error = %v, want ErrFixtureNotFound", err)
+		}
+		if Retryable(err) {
+			t.Error("缺少录制文件不应重试")
+		}
+	})
+}
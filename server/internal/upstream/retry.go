@@ -0,0 +1,190 @@
+// Package upstream 上游请求的重试与熔断策略（IYUU 接口和镜像共用）
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"top1000/internal/config"
+)
+
+const logPrefix = "上游"
+
+// Policy 重试策略（指数退避 + 随机浮动）
+type Policy struct {
+	Attempts  int           // 最多尝试次数（含首次）
+	BaseDelay time.Duration // 首次重试前的等待时间，之后每次翻倍
+	MaxDelay  time.Duration // 单次等待的上限
+	Jitter    float64       // 随机浮动比例（0.2 表示 ±20%）
+}
+
+// DefaultPolicy 根据配置创建重试策略
+func DefaultPolicy() Policy {
+	cfg := config.Get()
+	return Policy{
+		Attempts:  cfg.RetryAttempts,
+		BaseDelay: cfg.RetryBaseDelay,
+		MaxDelay:  cfg.RetryMaxDelay,
+		Jitter:    cfg.RetryJitter,
+	}
+}
+
+// Backoff 返回第 n 次重试（从 1 开始）前的等待时间
+func (p Policy) Backoff(n int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < n && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxDelay)
+
+	if p.Jitter > 0 && delay > 0 {
+		// 在 [1-jitter, 1+jitter] 范围内随机浮动，错开多个实例的重试
+		delay = time.Duration(float64(delay) * (1 + p.Jitter*(2*rand.Float64()-1)))
+	}
+	return delay
+}
+
+// StatusError 上游返回了非 200 状态码
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // Retry-After 响应头（未设置时为 0）
+}
+
+// Error 实现 error 接口
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API返回错误状态码: %d", e.StatusCode)
+}
+
+// Temporary 是否值得重试（限流和服务端错误）
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// CheckResponse 检查响应状态码，非 200 时返回 *StatusError
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter 解析 Retry-After（秒数或 HTTP 日期）
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+// permanentError 不可重试的错误（如签名错误、数据格式错误）
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 标记错误不可重试
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Retryable 判断错误是否值得重试
+// 多个错误合并时（如故障转移全部失败），只要有一个值得重试就重试
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			if Retryable(e) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Temporary()
+	}
+
+	// 网络错误等其他错误默认可以重试
+	return true
+}
+
+// retryAfter 返回错误中携带的 Retry-After（多个错误时取最大值）
+func retryAfter(err error) time.Duration {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var longest time.Duration
+		for _, e := range joined.Unwrap() {
+			longest = max(longest, retryAfter(e))
+		}
+		return longest
+	}
+
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.RetryAfter
+	}
+	return 0
+}
+
+// Retry 按策略执行 fn，失败且值得重试时等待后重试
+// 上游要求的 Retry-After 优先于退避时间，超过 MaxDelay 时只等待 MaxDelay 后继续重试
+func Retry(ctx context.Context, name string, policy Policy, fn func(ctx context.Context) error) error {
+	attempts := max(policy.Attempts, 1)
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if attempt >= attempts || !Retryable(err) {
+			return err
+		}
+
+		delay := policy.Backoff(attempt)
+		if wait := retryAfter(err); wait > 0 {
+			if wait > policy.MaxDelay {
+				log.Printf("[%s] %s 要求 %v 后重试，超过最大等待时间，只等待 %v", logPrefix, name, wait, policy.MaxDelay)
+				wait = policy.MaxDelay
+			}
+			delay = wait
+		}
+
+		log.Printf("[%s] %s 第 %d 次尝试失败: %v，%v 后重试", logPrefix, name, attempt, err, delay.Round(time.Millisecond))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w（最后一次错误: %v）", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
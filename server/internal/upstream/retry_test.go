@@ -0,0 +1,173 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPolicyBackoff(t *testing.T) {
+	policy := Policy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{retry: 1, want: time.Second},
+		{retry: 2, want: 2 * time.Second},
+		{retry: 3, want: 4 * time.Second},
+		{retry: 4, want: 5 * time.Second},
+		{retry: 100, want: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("第%d次重试", tt.retry), func(t *testing.T) {
+			if got := policy.Backoff(tt.retry); got != tt.want {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("随机浮动", func(t *testing.T) {
+		policy := Policy{BaseDelay: time.Second, MaxDelay: time.Second, Jitter: 0.2}
+		for range 100 {
+			if got := policy.Backoff(1); got < 800*time.Millisecond || got > 1200*time.Millisecond {
+				t.Fatalf("Backoff(1) = %v, 超出 [800ms, 1200ms]", got)
+			}
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 19, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "秒数", value: "30", want: 30 * time.Second},
+		{name: "HTTP日期", value: now.Add(time.Minute).Format(http.TimeFormat), want: time.Minute},
+		{name: "已过去的日期", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{name: "未设置", value: "", want: 0},
+		{name: "无效值", value: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "网络错误", err: errors.New("connection refused"), want: true},
+		{name: "限流", err: &StatusError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "服务不可用", err: &StatusError{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "资源不存在", err: &StatusError{StatusCode: http.StatusNotFound}, want: false},
+		{name: "不可重试", err: Permanent(errors.New("sign错误")), want: false},
+		{name: "已熔断", err: fmt.Errorf("primary: %w", ErrCircuitOpen), want: false},
+		{name: "已取消", err: context.Canceled, want: false},
+		{name: "合并错误中有可重试的", err: errors.Join(ErrCircuitOpen, &StatusError{StatusCode: http.StatusBadGateway}), want: true},
+		{name: "合并错误均不可重试", err: errors.Join(ErrCircuitOpen, Permanent(errors.New("格式错误"))), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Retryable(tt.err); got != tt.want {
+				t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	policy := Policy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 100 * time.Millisecond}
+
+	t.Run("失败后重试直到成功", func(t *testing.T) {
+		calls := 0
+		err := Retry(ctx, "test", policy, func(ctx context.Context) error {
+			if calls++; calls < 3 {
+				return &StatusError{StatusCode: http.StatusServiceUnavailable}
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Errorf("Retry() error = %v, calls = %d, want nil, 3", err, calls)
+		}
+	})
+
+	t.Run("达到最大次数后返回最后一次错误", func(t *testing.T) {
+		calls := 0
+		errLast := errors.New("第三次失败")
+		err := Retry(ctx, "test", policy, func(ctx context.Context) error {
+			if calls++; calls == 3 {
+				return errLast
+			}
+			return errors.New("失败")
+		})
+		if !errors.Is(err, errLast) || calls != 3 {
+			t.Errorf("Retry() error = %v, calls = %d", err, calls)
+		}
+	})
+
+	t.Run("不可重试的错误立即返回", func(t *testing.T) {
+		calls := 0
+		_ = Retry(ctx, "test", policy, func(ctx context.Context) error {
+			calls++
+			return Permanent(errors.New("sign错误"))
+		})
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+	})
+
+	t.Run("遵守Retry-After", func(t *testing.T) {
+		calls := 0
+		start := time.Now()
+		_ = Retry(ctx, "test", Policy{Attempts: 2, MaxDelay: time.Second}, func(ctx context.Context) error {
+			if calls++; calls == 1 {
+				return &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 50 * time.Millisecond}
+			}
+			return nil
+		})
+		if elapsed := time.Since(start); calls != 2 || elapsed < 50*time.Millisecond {
+			t.Errorf("calls = %d, 等待 %v，期望等待 Retry-After 后重试", calls, elapsed)
+		}
+	})
+
+	t.Run("Retry-After超过最大等待时间时按上限等待", func(t *testing.T) {
+		calls := 0
+		err := Retry(ctx, "test", policy, func(ctx context.Context) error {
+			calls++
+			return &StatusError{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Hour}
+		})
+		var status *StatusError
+		if !errors.As(err, &status) || calls != policy.Attempts {
+			t.Errorf("Retry() error = %v, calls = %d, want %d", err, calls, policy.Attempts)
+		}
+	})
+
+	t.Run("等待期间取消", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		err := Retry(ctx, "test", Policy{Attempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}, func(ctx context.Context) error {
+			return errors.New("失败")
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Retry() error = %v, want DeadlineExceeded", err)
+		}
+	})
+}
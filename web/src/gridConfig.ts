@@ -2,7 +2,7 @@ import type { GridOptions } from 'ag-grid-community'
 
 import type { DataType } from './types'
 
-import { convertSizeToKb } from './utils'
+import { duplicationOf, sizeInBytes } from './utils'
 import { operationRender } from './utils/operationRender'
 
 export const columnDefs: GridOptions<DataType>['columnDefs'] = [
@@ -32,11 +32,7 @@ export const columnDefs: GridOptions<DataType>['columnDefs'] = [
     headerName: '重复度',
     field: 'duplication',
     sortable: true,
-    comparator: (valueA, valueB) => {
-      const numA = Number.parseFloat(valueA) || 0
-      const numB = Number.parseFloat(valueB) || 0
-      return numA - numB
-    },
+    comparator: (_valueA, _valueB, nodeA, nodeB) => duplicationOf(nodeA.data!) - duplicationOf(nodeB.data!),
     minWidth: 100,
     width: 100,
   },
@@ -44,7 +40,7 @@ export const columnDefs: GridOptions<DataType>['columnDefs'] = [
     headerName: '文件大小',
     field: 'size',
     sortable: true,
-    comparator: (valueA, valueB) => convertSizeToKb(valueA) - convertSizeToKb(valueB),
+    comparator: (_valueA, _valueB, nodeA, nodeB) => sizeInBytes(nodeA.data!) - sizeInBytes(nodeB.data!),
     minWidth: 120,
     width: 120,
   },
@@ -16,7 +16,6 @@ import type { DataType } from './types'
 
 import { columnDefs, defaultColDef, interactionConfig, performanceConfig } from './gridConfig'
 import { fetchData } from './utils'
-import { loadSitesConfig } from './utils/config'
 
 const EXCLUDED_COLUMN = '操作'
 const ROOT_ID = '#root'
@@ -36,25 +35,6 @@ function shouldExportColumn(params: any): boolean {
   const colDef = params.column.getColDef()
   return colDef.headerName !== EXCLUDED_COLUMN
 }
-async function initApp() {
-  try {
-    await loadSitesConfig()
-    initGrid()
-  }
-  catch (error) {
-    console.error('应用初始化失败:', error)
-    const rootElement = document.querySelector<HTMLElement>(ROOT_ID)
-    if (rootElement) {
-      rootElement.innerHTML = `
-        <div style="padding: 20px; color: #fff;">
-          <h2>应用加载失败</h2>
-          <p>无法加载站点配置，请检查网络连接或联系管理员。</p>
-        </div>
-      `
-    }
-  }
-}
-
 function initGrid(): void {
   ModuleRegistry.registerModules([
     ClientSideRowModelModule,
@@ -101,4 +81,4 @@ function setupExportButtons(): void {
   })
 }
 
-initApp()
+initGrid()
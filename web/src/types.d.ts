@@ -14,15 +14,31 @@ export interface DataType {
   siteid: string
   /** 重复度 */
   duplication: string
+  /** 重复度（数值，旧数据可能没有） */
+  duplicationValue?: number
   /** 文件大小 */
   size: string
+  /** 文件大小（字节，KB/MB/GB/TB 按 1000 进位、KiB/MiB 等按 1024 进位，旧数据可能没有） */
+  sizeBytes?: number
   /** ID */
   id: number
+  /** 站点显示名称（?enrich=1，站点目录中没有时为空） */
+  nickname?: string
+  /** 种子详情链接（?enrich=1） */
+  detailsUrl?: string
+  /** 种子下载链接（?enrich=1，带用户令牌时包含该用户的 passkey 等凭据） */
+  downloadUrl?: string
 }
 /** 接口返回 */
 export interface ResDataType {
   /** 种子列表 */
   items: DataType[]
-  /** 更新时间 */
+  /** 更新时间（上游原始文本） */
   time: string
+  /** 更新时间（RFC 3339，旧数据可能没有） */
+  createdAt?: string
+  /** 更新时间（Unix 秒，旧数据可能没有） */
+  createdAtUnix?: number
+  /** 最近一次确认上游未变化的时间（RFC 3339） */
+  lastCheckedAt?: string
 }
@@ -2,8 +2,6 @@ import type { GridReadyEvent } from 'ag-grid-community'
 
 import type { DataType, ResDataType } from '@/types'
 
-export * from './config'
-
 const SIZE_UNITS = {
   KB: 1,
   MB: 1024,
@@ -13,6 +11,9 @@ const SIZE_UNITS = {
 
 const SIZE_PATTERN = /([\d.]+)\s*(KB|MB|GB|TB)/i
 
+/** 用户 API 令牌在 localStorage 中的键，设置后下载链接按该用户保存的站点凭据填充 */
+const API_TOKEN_KEY = 'top1000:apiToken'
+
 export function convertSizeToKb(sizeStr: string): number {
   const match = sizeStr.match(SIZE_PATTERN)
   if (!match) {
@@ -23,9 +24,37 @@ export function convertSizeToKb(sizeStr: string): number {
   return value * SIZE_UNITS[unit]
 }
 
+/** 文件大小（字节），旧数据没有 sizeBytes 时按文本解析 */
+export function sizeInBytes(item: DataType): number {
+  return item.sizeBytes ?? convertSizeToKb(item.size) * 1024
+}
+
+/** 重复度数值，旧数据没有 duplicationValue 时按文本解析 */
+export function duplicationOf(item: DataType): number {
+  return item.duplicationValue ?? (Number.parseFloat(item.duplication) || 0)
+}
+
+function authHeaders(): HeadersInit {
+  const token = localStorage.getItem(API_TOKEN_KEY)
+  return token ? { Authorization: `Bearer ${token}` } : {}
+}
+
+/** 请求补充了链接的数据；保存的令牌失效（401）时清除令牌，改为匿名请求 */
+async function fetchEnriched(): Promise<Response> {
+  // 详情和下载链接由服务端按站点目录补充（带令牌时包含用户的 passkey）
+  const response = await fetch('/top1000.json?enrich=1', { headers: authHeaders() })
+  if (response.status !== 401 || !localStorage.getItem(API_TOKEN_KEY)) {
+    return response
+  }
+
+  console.warn('API 令牌无效，已清除并改为匿名加载')
+  localStorage.removeItem(API_TOKEN_KEY)
+  return fetch('/top1000.json?enrich=1')
+}
+
 export async function fetchData(event: GridReadyEvent<DataType>): Promise<void> {
   try {
-    const response = await fetch('/top1000.json')
+    const response = await fetchEnriched()
     if (!response.ok) {
       throw new Error(`HTTP ${response.status}: ${response.statusText}`)
     }
@@ -2,20 +2,12 @@ import type { ICellRendererParams } from 'ag-grid-community'
 
 import type { DataType } from '../types'
 
-import { ptUrlConfig } from './config'
-
 export function operationRender(params: ICellRendererParams): string | null {
-  const data = params.data as DataType
-  const { siteName, siteid } = data
-
-  const urlConfig = ptUrlConfig[siteName]
-  if (!urlConfig) {
+  const { detailsUrl, downloadUrl } = params.data as DataType
+  if (!detailsUrl) {
     return null
   }
 
-  const detailsUrl = urlConfig.details(siteid)
-  const downloadUrl = urlConfig.download(siteid)
-
   return renderLinks(detailsUrl, downloadUrl)
 }
 